@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// ErrInvalidACL is returned when a canned ACL, grant header or access control
+// policy cannot be applied. It is the caller's fault, unlike load failures.
+var ErrInvalidACL = errors.New("invalid ACL")
+
+// grantHeaders lists the x-amz-grant-* headers in the order they are applied.
+var grantHeaders = []string{
+	"x-amz-grant-full-control",
+	"x-amz-grant-read",
+	"x-amz-grant-write",
+	"x-amz-grant-read-acp",
+	"x-amz-grant-write-acp",
+}
+
+// NewGroupGrant returns a grant for one of the predefined grantee groups.
+func NewGroupGrant(uri string, acl types.Permission) types.Grant {
+	return types.Grant{
+		Permission: acl,
+		Grantee: types.Grantee{
+			Type: types.GranteeGroup,
+			URI:  uri,
+		},
+		DateAdded: types.IsoTime(time.Now()),
+	}
+}
+
+// CannedObjectGrants expands a canned x-amz-acl value into the grants of an
+// object. The object owner always keeps FULL_CONTROL.
+func CannedObjectGrants(canned string, owner, bucketOwner types.UserObject) ([]types.Grant, error) {
+	grants := []types.Grant{NewGrant(owner.ID, owner.DisplayName, types.FULL_CONTROL)}
+
+	switch canned {
+	case "private":
+	case "public-read":
+		grants = append(grants, NewGroupGrant(types.AllUsersGroup, types.READ))
+	case "public-read-write":
+		grants = append(grants,
+			NewGroupGrant(types.AllUsersGroup, types.READ),
+			NewGroupGrant(types.AllUsersGroup, types.WRITE),
+		)
+	case "authenticated-read":
+		grants = append(grants, NewGroupGrant(types.AuthenticatedUsersGroup, types.READ))
+	case "bucket-owner-read":
+		if bucketOwner.ID != owner.ID {
+			grants = append(grants, NewGrant(bucketOwner.ID, bucketOwner.DisplayName, types.READ))
+		}
+	case "bucket-owner-full-control":
+		if bucketOwner.ID != owner.ID {
+			grants = append(grants, NewGrant(bucketOwner.ID, bucketOwner.DisplayName, types.FULL_CONTROL))
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported canned ACL %q", ErrInvalidACL, canned)
+	}
+	return grants, nil
+}
+
+// HasGrantHeaders reports whether any x-amz-grant-* header is set.
+func HasGrantHeaders(h http.Header) bool {
+	for _, name := range grantHeaders {
+		if h.Get(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// GrantsFromHeaders builds grants from every x-amz-grant-* header. Each header
+// holds a comma separated list of grantees, e.g.
+// id="KEY", uri="http://acs.amazonaws.com/groups/global/AllUsers" or
+// emailAddress="someone@example.com".
+func GrantsFromHeaders(h http.Header) ([]types.Grant, error) {
+	var grants []types.Grant
+	for _, name := range grantHeaders {
+		value := h.Get(name)
+		if value == "" {
+			continue
+		}
+		perm := types.AWSHeaderToACL(name)
+
+		for _, part := range strings.Split(value, ",") {
+			k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+			if !ok {
+				return nil, fmt.Errorf("%w: malformed grantee %q in %s", ErrInvalidACL, part, name)
+			}
+			v = strings.Trim(strings.TrimSpace(v), "\"")
+
+			grant := types.Grant{Permission: perm}
+			switch strings.ToLower(strings.TrimSpace(k)) {
+			case "id":
+				grant.Grantee.ID = v
+			case "uri":
+				grant.Grantee.URI = v
+			case "emailaddress":
+				grant.Grantee.EmailAddress = v
+			default:
+				return nil, fmt.Errorf("%w: unsupported grantee type %q in %s", ErrInvalidACL, k, name)
+			}
+			grants = append(grants, grant)
+		}
+	}
+	return ResolveGrants(grants)
+}
+
+// ResolveGrants validates grants and normalises their grantees: IDs must be
+// known access keys, URIs must be a predefined group, and email addresses are
+// resolved to every access key of the user with that address.
+func ResolveGrants(grants []types.Grant) ([]types.Grant, error) {
+	authorizations, err := LoadAuthorizations()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load authorizations: %v", err)
+	}
+	var identities *types.Identities
+
+	now := types.IsoTime(time.Now())
+	resolved := make([]types.Grant, 0, len(grants))
+	for _, g := range grants {
+		switch g.Permission {
+		case types.READ, types.WRITE, types.READ_ACP, types.WRITE_ACP, types.FULL_CONTROL:
+		default:
+			return nil, fmt.Errorf("%w: unknown permission %q", ErrInvalidACL, g.Permission)
+		}
+
+		switch g.Grantee.ResolvedType() {
+		case types.GranteeGroup:
+			if g.Grantee.URI != types.AllUsersGroup && g.Grantee.URI != types.AuthenticatedUsersGroup {
+				return nil, fmt.Errorf("%w: unknown grantee group %q", ErrInvalidACL, g.Grantee.URI)
+			}
+			resolved = append(resolved, NewGroupGrant(g.Grantee.URI, g.Permission))
+
+		case types.GranteeEmail:
+			if identities == nil {
+				if identities, err = LoadIdentities(); err != nil {
+					return nil, err
+				}
+			}
+			user := findUserByEmail(identities, g.Grantee.EmailAddress)
+			if user == nil {
+				return nil, fmt.Errorf("%w: no user with email address %s", ErrInvalidACL, g.Grantee.EmailAddress)
+			}
+			count := 0
+			for _, a := range authorizations.Authorizations {
+				if a.User == user.Name {
+					resolved = append(resolved, NewGrant(a.KeyID, a.Name, g.Permission))
+					count++
+				}
+			}
+			if count == 0 {
+				return nil, fmt.Errorf("%w: user %s has no access keys", ErrInvalidACL, user.Name)
+			}
+
+		default:
+			var found *types.Authorization
+			for i := range authorizations.Authorizations {
+				if authorizations.Authorizations[i].KeyID == g.Grantee.ID {
+					found = &authorizations.Authorizations[i]
+					break
+				}
+			}
+			if g.Grantee.ID == "" || found == nil {
+				return nil, fmt.Errorf("%w: unknown grantee ID %q", ErrInvalidACL, g.Grantee.ID)
+			}
+			resolved = append(resolved, NewGrant(found.KeyID, found.Name, g.Permission))
+		}
+	}
+
+	for i := range resolved {
+		resolved[i].DateAdded = now
+	}
+	return resolved, nil
+}
+
+// findUserByEmail returns the user with the email address, compared case-insensitively.
+func findUserByEmail(identities *types.Identities, email string) *types.User {
+	for i := range identities.Users {
+		if identities.Users[i].Email != "" && strings.EqualFold(identities.Users[i].Email, email) {
+			return &identities.Users[i]
+		}
+	}
+	return nil
+}
+
+// CannedBucketACL converts a canned x-amz-acl value to a bucket ACL.
+func CannedBucketACL(canned string) (types.Permission, error) {
+	acl := types.ConvertToBucketACL(canned)
+	if !types.IsBucketACL(acl) {
+		return "", fmt.Errorf("%w: unsupported canned ACL %q", ErrInvalidACL, canned)
+	}
+	return acl, nil
+}
+
+// BucketGrants returns the full ACL of a bucket: the owner's implicit
+// FULL_CONTROL, the group grants implied by the canned ACL, and the explicit
+// grants, without duplicates.
+func BucketGrants(bucket *types.Bucket) []types.Grant {
+	grants := []types.Grant{NewGrant(bucket.Owner.ID, bucket.Owner.DisplayName, types.FULL_CONTROL)}
+	if types.IsBucketACLRead(bucket.ACL) {
+		grants = append(grants, NewGroupGrant(types.AllUsersGroup, types.READ))
+	}
+	if types.IsBucketACLWrite(bucket.ACL) {
+		grants = append(grants, NewGroupGrant(types.AllUsersGroup, types.WRITE))
+	}
+
+	for _, g := range bucket.Grants {
+		duplicate := false
+		for _, existing := range grants {
+			if existing.Permission == g.Permission && existing.Grantee.ID == g.Grantee.ID && existing.Grantee.URI == g.Grantee.URI {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			grants = append(grants, g)
+		}
+	}
+	return grants
+}
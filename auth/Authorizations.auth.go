@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/aidenappl/openbucket-go/tools"
 	"github.com/aidenappl/openbucket-go/types"
 )
 
@@ -25,6 +26,18 @@ func LoadAuthorizations() (*types.Authorizations, error) {
 	return &authorizations, nil
 }
 
+// SaveAuthorizations replaces the authorizations file.
+func SaveAuthorizations(authorizations *types.Authorizations) error {
+	data, err := xml.MarshalIndent(authorizations, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal authorizations XML: %v", err)
+	}
+	if err := tools.WriteFileAtomic("authorizations.xml", data, 0644); err != nil {
+		return fmt.Errorf("failed to write authorizations file: %v", err)
+	}
+	return nil
+}
+
 func CheckUserExists(keyID string) (*types.Authorization, error) {
 	authorizations, err := LoadAuthorizations()
 	if err != nil {
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aidenappl/openbucket-go/tools"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// bucketPolicyPath returns the location of the policy file, which lives next
+// to the bucket's .obpermissions file.
+func bucketPolicyPath(bucketName string) string {
+	return fmt.Sprintf("buckets/%s.obpolicy", bucketName)
+}
+
+// LoadBucketPolicy loads the bucket policy. It returns nil without an error
+// when no policy is attached to the bucket.
+func LoadBucketPolicy(bucketName string) (*types.BucketPolicy, error) {
+	if bucketName == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(bucketPolicyPath(bucketName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read bucket policy: %v", err)
+	}
+
+	var policy types.BucketPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to decode bucket policy: %v", err)
+	}
+	return &policy, nil
+}
+
+// SaveBucketPolicy replaces the bucket policy.
+func SaveBucketPolicy(bucketName string, policy *types.BucketPolicy) error {
+	data, err := json.MarshalIndent(policy, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling bucket policy: %v", err)
+	}
+
+	if err := tools.WriteFileAtomic(bucketPolicyPath(bucketName), data, 0644); err != nil {
+		return fmt.Errorf("error writing bucket policy: %v", err)
+	}
+	return nil
+}
+
+// DeleteBucketPolicy removes the bucket policy. Deleting a missing policy is not an error.
+func DeleteBucketPolicy(bucketName string) error {
+	err := os.Remove(bucketPolicyPath(bucketName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error deleting bucket policy: %v", err)
+	}
+	return nil
+}
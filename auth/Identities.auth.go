@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aidenappl/openbucket-go/tools"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+const identitiesFile = "identities.xml"
+
+// LoadIdentities loads users, groups, roles and identity policies. A missing
+// identities file is treated as empty.
+func LoadIdentities() (*types.Identities, error) {
+	data, err := os.ReadFile(identitiesFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return &types.Identities{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read identities file: %v", err)
+	}
+
+	var identities types.Identities
+	if err := xml.Unmarshal(data, &identities); err != nil {
+		return nil, fmt.Errorf("failed to decode identities XML: %v", err)
+	}
+	return &identities, nil
+}
+
+// SaveIdentities replaces the identities file.
+func SaveIdentities(identities *types.Identities) error {
+	data, err := xml.MarshalIndent(identities, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal identities XML: %v", err)
+	}
+	if err := tools.WriteFileAtomic(identitiesFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write identities file: %v", err)
+	}
+	return nil
+}
+
+// FindUser returns the named user, or nil if there is none.
+func FindUser(identities *types.Identities, name string) *types.User {
+	for i := range identities.Users {
+		if identities.Users[i].Name == name {
+			return &identities.Users[i]
+		}
+	}
+	return nil
+}
+
+// FindGroup returns the named group, or nil if there is none.
+func FindGroup(identities *types.Identities, name string) *types.Group {
+	for i := range identities.Groups {
+		if identities.Groups[i].Name == name {
+			return &identities.Groups[i]
+		}
+	}
+	return nil
+}
+
+// FindRole returns the named role, or nil if there is none.
+func FindRole(identities *types.Identities, name string) *types.Role {
+	for i := range identities.Roles {
+		if identities.Roles[i].Name == name {
+			return &identities.Roles[i]
+		}
+	}
+	return nil
+}
+
+// FindPolicy returns the named identity policy, or nil if there is none.
+func FindPolicy(identities *types.Identities, name string) *types.ManagedPolicy {
+	for i := range identities.Policies {
+		if identities.Policies[i].Name == name {
+			return &identities.Policies[i]
+		}
+	}
+	return nil
+}
+
+// UserForKey returns the user owning the access key. Keys that are not
+// attached to a user return nil without an error.
+func UserForKey(keyID string) (*types.User, *types.Identities, error) {
+	authorization, err := CheckUserExists(keyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if authorization == nil || authorization.User == "" {
+		return nil, nil, nil
+	}
+
+	identities, err := LoadIdentities()
+	if err != nil {
+		return nil, nil, err
+	}
+	return FindUser(identities, authorization.User), identities, nil
+}
+
+// EffectivePolicies returns the identity policies attached to the user,
+// directly or through group membership, without duplicates.
+func EffectivePolicies(identities *types.Identities, user *types.User) []types.ManagedPolicy {
+	seen := make(map[string]bool)
+	var out []types.ManagedPolicy
+
+	add := func(names []string) {
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			if p := FindPolicy(identities, name); p != nil {
+				out = append(out, *p)
+			}
+		}
+	}
+
+	add(user.Policies)
+	for _, groupName := range user.Groups {
+		if group := FindGroup(identities, groupName); group != nil {
+			add(group.Policies)
+		}
+	}
+	return out
+}
+
+// RolePolicies returns the identity policies attached to the role.
+func RolePolicies(identities *types.Identities, role *types.Role) []types.ManagedPolicy {
+	var out []types.ManagedPolicy
+	for _, name := range role.Policies {
+		if p := FindPolicy(identities, name); p != nil {
+			out = append(out, *p)
+		}
+	}
+	return out
+}
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sync"
 	"time"
 
+	"github.com/aidenappl/openbucket-go/tools"
 	"github.com/aidenappl/openbucket-go/types"
 )
 
@@ -14,7 +16,7 @@ func LoadBucketPermissions(bucketName string) (*types.Bucket, error) {
 	permissionsFile := fmt.Sprintf("buckets/%s.obpermissions", bucketName)
 	file, err := os.Open(permissionsFile)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open permissions file: %v", err)
+		return nil, fmt.Errorf("failed to open permissions file: %w", err)
 	}
 	defer file.Close()
 
@@ -22,7 +24,7 @@ func LoadBucketPermissions(bucketName string) (*types.Bucket, error) {
 	decoder := xml.NewDecoder(file)
 	err = decoder.Decode(&permissions)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode permissions XML: %v", err)
+		return nil, fmt.Errorf("failed to decode permissions XML: %w", err)
 	}
 
 	return &permissions, nil
@@ -32,7 +34,7 @@ func NewGrant(keyID string, displayName string, acl types.Permission) types.Gran
 	return types.Grant{
 		Permission: acl,
 		Grantee: types.Grantee{
-			Type:        "CanonicalUser",
+			Type:        types.GranteeCanonicalUser,
 			ID:          keyID,
 			DisplayName: displayName,
 		},
@@ -41,41 +43,49 @@ func NewGrant(keyID string, displayName string, acl types.Permission) types.Gran
 }
 
 func SaveNewGrant(bucketName string, grant *types.Grant) error {
-	permissions, err := LoadBucketPermissions(bucketName)
-	if err != nil {
-		return fmt.Errorf("failed to load permissions: %v", err)
-	}
-
-	// Add the new grant to the permissions
-	permissions.Grants = append(permissions.Grants, *grant)
-
-	return UpdateBucketPermissions(bucketName, permissions)
+	return ModifyBucketPermissions(bucketName, func(permissions *types.Bucket) error {
+		// Add the new grant to the permissions
+		permissions.Grants = append(permissions.Grants, *grant)
+		return nil
+	})
 }
 
 func UpdateGrant(bucketName string, grant *types.Grant) error {
+	return ModifyBucketPermissions(bucketName, func(permissions *types.Bucket) error {
+		// Update the grant in the permissions
+		for i, existingGrant := range permissions.Grants {
+			if existingGrant.Grantee.ID == grant.Grantee.ID {
+				permissions.Grants[i] = *grant
+				break
+			}
+		}
+		return nil
+	})
+}
+
+// permissionsMu serialises read-modify-write cycles on .obpermissions files.
+var permissionsMu sync.Mutex
+
+// ModifyBucketPermissions loads the bucket permissions, applies modify and
+// saves the result. Concurrent modifications are serialised so none are lost.
+func ModifyBucketPermissions(bucketName string, modify func(*types.Bucket) error) error {
+	permissionsMu.Lock()
+	defer permissionsMu.Unlock()
+
 	permissions, err := LoadBucketPermissions(bucketName)
 	if err != nil {
 		return fmt.Errorf("failed to load permissions: %v", err)
 	}
-
-	// Update the grant in the permissions
-	for i, existingGrant := range permissions.Grants {
-		if existingGrant.Grantee.ID == grant.Grantee.ID {
-			permissions.Grants[i] = *grant
-			break
-		}
+	if err := modify(permissions); err != nil {
+		return err
 	}
-
 	return UpdateBucketPermissions(bucketName, permissions)
 }
 
+// UpdateBucketPermissions replaces the permissions file. The file is replaced
+// atomically, so readers never observe a partially written ACL.
 func UpdateBucketPermissions(bucketName string, permissions *types.Bucket) error {
 	permissionsFile := fmt.Sprintf("buckets/%s.obpermissions", bucketName)
-	file, err := os.Create(permissionsFile)
-	if err != nil {
-		return fmt.Errorf("failed to create permissions file: %v", err)
-	}
-	defer file.Close()
 
 	permissionsXML, err := xml.MarshalIndent(permissions, "", "  ")
 	if err != nil {
@@ -83,8 +93,7 @@ func UpdateBucketPermissions(bucketName string, permissions *types.Bucket) error
 		return fmt.Errorf("error marshalling permissions to XML: %v", err)
 	}
 
-	_, err = file.WriteString(string(permissionsXML))
-	if err != nil {
+	if err := tools.WriteFileAtomic(permissionsFile, permissionsXML, 0644); err != nil {
 		log.Println("Error writing to permissions file:", err)
 		return fmt.Errorf("error writing to permissions file: %v", err)
 	}
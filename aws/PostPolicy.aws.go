@@ -0,0 +1,238 @@
+package aws
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// Errors returned while checking a browser POST upload.
+var (
+	ErrInvalidPostPolicy    = errors.New("invalid POST policy document")
+	ErrPostPolicyCondition  = errors.New("invalid according to policy")
+	ErrPostSignatureInvalid = errors.New("POST policy signature does not match")
+	ErrEntityTooLarge       = errors.New("entity too large")
+	ErrEntityTooSmall       = errors.New("entity too small")
+)
+
+// postPolicyExemptFields are the form fields that need no policy condition.
+// Fields prefixed with x-ignore- are exempt as well. The bucket is taken from
+// the URL, so it is only checked when a condition names it.
+var postPolicyExemptFields = map[string]bool{
+	"awsaccesskeyid":  true,
+	"bucket":          true,
+	"file":            true,
+	"policy":          true,
+	"signature":       true,
+	"x-amz-signature": true,
+}
+
+// ValidatePostPolicySignature verifies the signature of a browser POST upload
+// and returns the access key that signed it. fields holds the form fields with
+// lowercased names. SigV4 forms sign the base64 policy with the SigV4 signing
+// key of x-amz-date; SigV2 forms sign it with HMAC-SHA1 of the secret key.
+func ValidatePostPolicySignature(fields map[string]string) (string, error) {
+	encodedPolicy := fields["policy"]
+
+	var accessKey string
+	var sign func(secretKey string) []byte
+	var provided []byte
+	if signature := fields["x-amz-signature"]; signature != "" {
+		if fields["x-amz-algorithm"] != "AWS4-HMAC-SHA256" {
+			return "", fmt.Errorf("%w: unsupported x-amz-algorithm %q", ErrPostSignatureInvalid, fields["x-amz-algorithm"])
+		}
+		scope := strings.Split(fields["x-amz-credential"], "/")
+		if len(scope) != 5 || scope[0] == "" {
+			return "", fmt.Errorf("%w: malformed x-amz-credential", ErrPostSignatureInvalid)
+		}
+		date, err := time.Parse("20060102T150405Z", fields["x-amz-date"])
+		if err != nil {
+			return "", fmt.Errorf("%w: malformed x-amz-date", ErrPostSignatureInvalid)
+		}
+		if provided, err = hex.DecodeString(signature); err != nil {
+			return "", fmt.Errorf("%w: malformed x-amz-signature", ErrPostSignatureInvalid)
+		}
+		accessKey = scope[0]
+		sign = func(secretKey string) []byte {
+			return hmacSHA256(getSigningKey(secretKey, date, Region, "s3"), encodedPolicy)
+		}
+	} else if signature := fields["signature"]; signature != "" {
+		var err error
+		if provided, err = base64.StdEncoding.DecodeString(signature); err != nil {
+			return "", fmt.Errorf("%w: malformed signature", ErrPostSignatureInvalid)
+		}
+		accessKey = fields["awsaccesskeyid"]
+		sign = func(secretKey string) []byte {
+			mac := hmac.New(sha1.New, []byte(secretKey))
+			mac.Write([]byte(encodedPolicy))
+			return mac.Sum(nil)
+		}
+	} else {
+		return "", fmt.Errorf("%w: no signature provided", ErrPostSignatureInvalid)
+	}
+
+	secret, err := secretKey(fields["x-amz-security-token"], accessKey)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrPostSignatureInvalid, err)
+	}
+
+	if !hmac.Equal(provided, sign(secret)) {
+		return "", ErrPostSignatureInvalid
+	}
+	return accessKey, nil
+}
+
+// ParsePostPolicy decodes a base64 POST policy document.
+func ParsePostPolicy(encoded string) (*types.PostPolicy, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: policy is not base64 encoded", ErrInvalidPostPolicy)
+	}
+
+	var doc struct {
+		Expiration string            `json:"expiration"`
+		Conditions []json.RawMessage `json:"conditions"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPostPolicy, err)
+	}
+
+	expiration, err := time.Parse(time.RFC3339, doc.Expiration)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid expiration %q", ErrInvalidPostPolicy, doc.Expiration)
+	}
+
+	policy := &types.PostPolicy{Expiration: expiration}
+	for _, rawCondition := range doc.Conditions {
+		condition, err := parsePostPolicyCondition(rawCondition)
+		if err != nil {
+			return nil, err
+		}
+		policy.Conditions = append(policy.Conditions, condition)
+	}
+	return policy, nil
+}
+
+// parsePostPolicyCondition decodes either an exact match object such as
+// {"acl": "public-read"} or an array such as ["starts-with", "$key", "user/"]
+// or ["content-length-range", 1, 1048576].
+func parsePostPolicyCondition(raw json.RawMessage) (types.PostPolicyCondition, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '{' {
+		var match map[string]string
+		if err := json.Unmarshal(raw, &match); err != nil || len(match) != 1 {
+			return types.PostPolicyCondition{}, fmt.Errorf("%w: invalid condition %s", ErrInvalidPostPolicy, raw)
+		}
+		for field, value := range match {
+			return types.PostPolicyCondition{Operator: "eq", Field: strings.ToLower(strings.TrimPrefix(field, "$")), Value: value}, nil
+		}
+	}
+
+	var parts []any
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&parts); err != nil || len(parts) != 3 {
+		return types.PostPolicyCondition{}, fmt.Errorf("%w: invalid condition %s", ErrInvalidPostPolicy, raw)
+	}
+	operator, _ := parts[0].(string)
+
+	switch operator = strings.ToLower(operator); operator {
+	case "eq", "starts-with":
+		field, ok := parts[1].(string)
+		value, valueOK := parts[2].(string)
+		if !ok || !valueOK || !strings.HasPrefix(field, "$") {
+			return types.PostPolicyCondition{}, fmt.Errorf("%w: invalid condition %s", ErrInvalidPostPolicy, raw)
+		}
+		return types.PostPolicyCondition{Operator: operator, Field: strings.ToLower(field[1:]), Value: value}, nil
+	case "content-length-range":
+		minSize, minErr := jsonInt(parts[1])
+		maxSize, maxErr := jsonInt(parts[2])
+		if minErr != nil || maxErr != nil || minSize < 0 || maxSize < minSize {
+			return types.PostPolicyCondition{}, fmt.Errorf("%w: invalid content-length-range %s", ErrInvalidPostPolicy, raw)
+		}
+		return types.PostPolicyCondition{Operator: operator, Min: minSize, Max: maxSize}, nil
+	}
+	return types.PostPolicyCondition{}, fmt.Errorf("%w: unknown condition operator %q", ErrInvalidPostPolicy, operator)
+}
+
+func jsonInt(v any) (int64, error) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("not a number")
+	}
+	return n.Int64()
+}
+
+// CheckPostPolicy checks the form fields, with lowercased names, against the
+// policy. Every field must satisfy the conditions that name it, and every
+// field that is not exempt must be named by at least one condition.
+func CheckPostPolicy(policy *types.PostPolicy, fields map[string]string) error {
+	if time.Now().After(policy.Expiration) {
+		return fmt.Errorf("%w: policy expired", ErrPostPolicyCondition)
+	}
+
+	covered := map[string]bool{}
+	for _, c := range policy.Conditions {
+		if c.Operator == "content-length-range" {
+			continue
+		}
+		covered[c.Field] = true
+
+		value, ok := fields[c.Field]
+		switch {
+		case !ok:
+			return fmt.Errorf("%w: missing field %s", ErrPostPolicyCondition, c.Field)
+		case c.Operator == "eq" && value != c.Value:
+			return fmt.Errorf("%w: [\"eq\", \"$%s\", %q]", ErrPostPolicyCondition, c.Field, c.Value)
+		case c.Operator == "starts-with" && !strings.HasPrefix(value, c.Value):
+			return fmt.Errorf("%w: [\"starts-with\", \"$%s\", %q]", ErrPostPolicyCondition, c.Field, c.Value)
+		}
+	}
+
+	for field := range fields {
+		if !covered[field] && !postPolicyExemptFields[field] && !strings.HasPrefix(field, "x-ignore-") {
+			return fmt.Errorf("%w: extra input fields: %s", ErrPostPolicyCondition, field)
+		}
+	}
+	return nil
+}
+
+// LimitPostBody enforces the policy's content-length-range while the file is
+// read: the reader fails with ErrEntityTooLarge or ErrEntityTooSmall instead
+// of returning more data or EOF.
+func LimitPostBody(policy *types.PostPolicy, r io.Reader) io.Reader {
+	for _, c := range policy.Conditions {
+		if c.Operator == "content-length-range" {
+			return &rangeReader{r: r, min: c.Min, max: c.Max}
+		}
+	}
+	return r
+}
+
+type rangeReader struct {
+	r        io.Reader
+	n        int64
+	min, max int64
+}
+
+func (l *rangeReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	if l.n > l.max {
+		return n, fmt.Errorf("%w: upload exceeds the maximum of %d bytes", ErrEntityTooLarge, l.max)
+	}
+	if err == io.EOF && l.n < l.min {
+		return n, fmt.Errorf("%w: upload is smaller than the minimum of %d bytes", ErrEntityTooSmall, l.min)
+	}
+	return n, err
+}
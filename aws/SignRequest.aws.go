@@ -0,0 +1,32 @@
+package aws
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// unsignedPayload is the payload hash of requests whose body is not signed.
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// SignRequest signs r for the S3 API of region with Signature Version 4, in
+// the form ValidateSignature checks, for clients such as the CLI and the
+// replication worker. The body is not signed. A session token is sent for
+// temporary credentials.
+func SignRequest(r *http.Request, region, accessKey, secretKey, sessionToken string, now time.Time) {
+	now = now.UTC()
+	r.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	r.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	if sessionToken != "" {
+		r.Header.Set("X-Amz-Security-Token", sessionToken)
+		signedHeaders += ";x-amz-security-token"
+	}
+
+	canonicalRequest := buildCanonicalRequest(r, signedHeaders, unsignedPayload)
+	stringToSign := buildStringToSign(now, region, "s3", canonicalRequest)
+	signature := computeSignature(getSigningKey(secretKey, now, region, "s3"), stringToSign)
+
+	r.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s/%s/s3/aws4_request, SignedHeaders=%s, Signature=%s",
+		accessKey, now.Format("20060102"), region, signedHeaders, signature))
+}
@@ -12,9 +12,17 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/aidenappl/openbucket-go/sts"
 )
 
-func ValidateSignature(r *http.Request, authorizationHeader, dateHeader, amzContentSHA256 string) bool {
+// Region is the region requests to this server are signed for.
+const Region = "garage"
+
+// ValidateSignature checks the Signature Version 4 Authorization header of a
+// request to the given service, "s3" or "sts". Signatures scoped to another
+// service are rejected.
+func ValidateSignature(r *http.Request, service, authorizationHeader, dateHeader, amzContentSHA256 string) bool {
 
 	parts := strings.Split(authorizationHeader, " ")
 	if parts[0] != "AWS4-HMAC-SHA256" {
@@ -28,12 +36,18 @@ func ValidateSignature(r *http.Request, authorizationHeader, dateHeader, amzCont
 		return false
 	}
 
-	accessKey := strings.Split(credentialParts[1], "/")[0]
+	scope := strings.Split(strings.TrimSuffix(credentialParts[1], ","), "/")
+	accessKey := scope[0]
 	if accessKey == "" {
 		log.Println("Access Key is missing in Authorization header")
 		return false
 	}
 
+	if len(scope) != 5 || scope[3] != service {
+		log.Println("Credential scope is not for the", service, "service:", strings.Join(scope[1:], "/"))
+		return false
+	}
+
 	signedHeadersParts := strings.Split(parts[2], "=")
 	if len(signedHeadersParts) != 2 || signedHeadersParts[0] != "SignedHeaders" {
 		log.Println("Invalid SignedHeaders format in Authorization header:", signedHeadersParts)
@@ -61,7 +75,7 @@ func ValidateSignature(r *http.Request, authorizationHeader, dateHeader, amzCont
 		return false
 	}
 
-	secretKey, err := loadSecretKeyByAccessKey(accessKey)
+	secretKey, err := secretKeyForRequest(r, accessKey)
 	if err != nil {
 		log.Println("Error loading secret key for Access Key:", accessKey, err)
 		return false
@@ -69,9 +83,9 @@ func ValidateSignature(r *http.Request, authorizationHeader, dateHeader, amzCont
 
 	canonicalRequest := buildCanonicalRequest(r, rawSH, amzContentSHA256)
 
-	stringToSign := buildStringToSign(date, "garage", "s3", canonicalRequest)
+	stringToSign := buildStringToSign(date, Region, service, canonicalRequest)
 
-	signingKey := getSigningKey(secretKey, date, "garage", "s3")
+	signingKey := getSigningKey(secretKey, date, Region, service)
 
 	computedSignature := computeSignature(signingKey, stringToSign)
 
@@ -82,6 +96,31 @@ func ValidateSignature(r *http.Request, authorizationHeader, dateHeader, amzCont
 
 	return true
 }
+
+// SecurityToken returns the session token of a request made with temporary
+// credentials, or an empty string.
+func SecurityToken(r *http.Request) string {
+	if token := r.Header.Get("X-Amz-Security-Token"); token != "" {
+		return token
+	}
+	return r.URL.Query().Get("X-Amz-Security-Token")
+}
+
+// secretKeyForRequest returns the secret key the request was signed with.
+func secretKeyForRequest(r *http.Request, accessKey string) (string, error) {
+	return secretKey(SecurityToken(r), accessKey)
+}
+
+// secretKey returns the secret of an access key. Temporary credentials carry
+// their session token; their secret is derived from it instead of being
+// stored in authorizations.xml.
+func secretKey(sessionToken, accessKey string) (string, error) {
+	if sessionToken != "" {
+		return sts.SessionSecret(sessionToken, accessKey)
+	}
+	return loadSecretKeyByAccessKey(accessKey)
+}
+
 func buildCanonicalRequest(r *http.Request,
 	signedHeadersCSV, payloadHash string) string {
 
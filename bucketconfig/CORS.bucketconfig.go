@@ -0,0 +1,113 @@
+package bucketconfig
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+const (
+	// MaxCORSConfigurationSize caps the size of a CORS configuration document.
+	MaxCORSConfigurationSize = 64 * 1024
+	// maxCORSRules is the number of rules S3 accepts in one configuration.
+	maxCORSRules = 100
+)
+
+// ErrInvalidCORS is returned for CORS configurations S3 would reject.
+var ErrInvalidCORS = errors.New("invalid CORS configuration")
+
+// corsMethods are the methods a CORS rule may allow.
+var corsMethods = []string{"GET", "PUT", "POST", "DELETE", "HEAD"}
+
+// LoadCORS returns the CORS configuration of a bucket, or nil when none is set.
+func LoadCORS(bucket string) (*types.CORSConfiguration, error) {
+	var cfg types.CORSConfiguration
+	found, err := load(bucket, "obcors", &cfg)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// SaveCORS replaces the CORS configuration of a bucket.
+func SaveCORS(bucket string, cfg *types.CORSConfiguration) error {
+	return save(bucket, "obcors", cfg)
+}
+
+// DeleteCORS removes the CORS configuration of a bucket.
+func DeleteCORS(bucket string) error {
+	return remove(bucket, "obcors")
+}
+
+// ParseCORS decodes and validates a CORSConfiguration document.
+func ParseCORS(data []byte) (*types.CORSConfiguration, error) {
+	var cfg types.CORSConfiguration
+	if err := xml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCORS, err)
+	}
+
+	if len(cfg.Rules) == 0 || len(cfg.Rules) > maxCORSRules {
+		return nil, fmt.Errorf("%w: between 1 and %d CORSRule elements are required", ErrInvalidCORS, maxCORSRules)
+	}
+	for i, rule := range cfg.Rules {
+		if len(rule.AllowedOrigins) == 0 || len(rule.AllowedMethods) == 0 {
+			return nil, fmt.Errorf("%w: rule %d needs at least one AllowedOrigin and AllowedMethod", ErrInvalidCORS, i+1)
+		}
+		for _, method := range rule.AllowedMethods {
+			if !slices.Contains(corsMethods, method) {
+				return nil, fmt.Errorf("%w: unsupported AllowedMethod %q", ErrInvalidCORS, method)
+			}
+		}
+		for _, value := range slices.Concat(rule.AllowedOrigins, rule.AllowedHeaders) {
+			if strings.Count(value, "*") > 1 {
+				return nil, fmt.Errorf("%w: %q may contain at most one wildcard", ErrInvalidCORS, value)
+			}
+		}
+		if rule.MaxAgeSeconds < 0 {
+			return nil, fmt.Errorf("%w: MaxAgeSeconds cannot be negative", ErrInvalidCORS)
+		}
+	}
+	return &cfg, nil
+}
+
+// MatchCORS returns the first rule that allows a request from origin using
+// method and sending the given headers, or nil if no rule does.
+func MatchCORS(cfg *types.CORSConfiguration, origin, method string, headers []string) *types.CORSRule {
+	if cfg == nil {
+		return nil
+	}
+	for i, rule := range cfg.Rules {
+		if !slices.Contains(rule.AllowedMethods, method) {
+			continue
+		}
+		if !slices.ContainsFunc(rule.AllowedOrigins, func(allowed string) bool { return corsMatch(allowed, origin) }) {
+			continue
+		}
+		allHeaders := true
+		for _, header := range headers {
+			if !slices.ContainsFunc(rule.AllowedHeaders, func(allowed string) bool {
+				return corsMatch(strings.ToLower(allowed), strings.ToLower(header))
+			}) {
+				allHeaders = false
+				break
+			}
+		}
+		if allHeaders {
+			return &cfg.Rules[i]
+		}
+	}
+	return nil
+}
+
+// corsMatch matches a value against a pattern with at most one "*".
+func corsMatch(pattern, value string) bool {
+	prefix, suffix, wildcard := strings.Cut(pattern, "*")
+	if !wildcard {
+		return pattern == value
+	}
+	return len(value) >= len(prefix)+len(suffix) && strings.HasPrefix(value, prefix) && strings.HasSuffix(value, suffix)
+}
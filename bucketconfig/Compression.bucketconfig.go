@@ -0,0 +1,98 @@
+package bucketconfig
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aidenappl/openbucket-go/tools"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// MaxCompressionConfigurationSize caps the size of a compression configuration document.
+const MaxCompressionConfigurationSize = 16 * 1024
+
+// ErrInvalidCompression is returned for compression configurations OpenBucket cannot apply.
+var ErrInvalidCompression = errors.New("invalid compression configuration")
+
+// defaultCompressibleTypes are compressed when a configuration names no content types.
+var defaultCompressibleTypes = []string{
+	"text/*",
+	"application/json",
+	"application/xml",
+	"application/javascript",
+	"image/svg+xml",
+}
+
+// LoadCompression returns the compression configuration of a bucket, or nil when none is set.
+func LoadCompression(bucket string) (*types.CompressionConfiguration, error) {
+	var cfg types.CompressionConfiguration
+	found, err := load(bucket, "obcompression", &cfg)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// SaveCompression replaces the compression configuration of a bucket.
+func SaveCompression(bucket string, cfg *types.CompressionConfiguration) error {
+	return save(bucket, "obcompression", cfg)
+}
+
+// DeleteCompression removes the compression configuration of a bucket.
+// Objects that are already compressed stay compressed.
+func DeleteCompression(bucket string) error {
+	return remove(bucket, "obcompression")
+}
+
+// ParseCompression decodes and validates a CompressionConfiguration document.
+func ParseCompression(data []byte) (*types.CompressionConfiguration, error) {
+	var cfg types.CompressionConfiguration
+	if err := xml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCompression, err)
+	}
+	if err := ValidateCompression(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// ValidateCompression checks the algorithm and content types of a configuration.
+func ValidateCompression(cfg *types.CompressionConfiguration) error {
+	if cfg.Algorithm != types.CompressionZstd && cfg.Algorithm != types.CompressionGzip {
+		return fmt.Errorf("%w: the algorithm must be %s or %s", ErrInvalidCompression, types.CompressionZstd, types.CompressionGzip)
+	}
+	for _, contentType := range cfg.ContentTypes {
+		kind, sub, ok := strings.Cut(contentType, "/")
+		if !ok || kind == "" || sub == "" || strings.Contains(sub, "/") {
+			return fmt.Errorf("%w: %q is not a content type", ErrInvalidCompression, contentType)
+		}
+	}
+	return nil
+}
+
+// CompressionFor returns the algorithm a new object with the given key is
+// compressed with, or "" when it is stored as is. The content type is
+// derived from the key, as it is when the object is served.
+func CompressionFor(cfg *types.CompressionConfiguration, key string) string {
+	if cfg == nil {
+		return ""
+	}
+	patterns := cfg.ContentTypes
+	if len(patterns) == 0 {
+		patterns = defaultCompressibleTypes
+	}
+
+	contentType := tools.ContentType(key)
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "/*"); ok {
+			if strings.HasPrefix(contentType, prefix+"/") {
+				return cfg.Algorithm
+			}
+		} else if strings.EqualFold(pattern, contentType) {
+			return cfg.Algorithm
+		}
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package bucketconfig
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// MaxEncryptionConfigurationSize caps the size of an encryption configuration document.
+const MaxEncryptionConfigurationSize = 16 * 1024
+
+// ErrInvalidEncryption is returned for encryption configurations S3 would reject.
+var ErrInvalidEncryption = errors.New("invalid encryption configuration")
+
+// LoadEncryption returns the default encryption of a bucket, or nil when none is set.
+func LoadEncryption(bucket string) (*types.ServerSideEncryptionConfiguration, error) {
+	var cfg types.ServerSideEncryptionConfiguration
+	found, err := load(bucket, "obencryption", &cfg)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// SaveEncryption replaces the default encryption of a bucket.
+func SaveEncryption(bucket string, cfg *types.ServerSideEncryptionConfiguration) error {
+	return save(bucket, "obencryption", cfg)
+}
+
+// DeleteEncryption removes the default encryption of a bucket. Objects that
+// are already encrypted stay encrypted.
+func DeleteEncryption(bucket string) error {
+	return remove(bucket, "obencryption")
+}
+
+// ParseEncryption decodes and validates a ServerSideEncryptionConfiguration
+// document. Only SSE-S3 (AES256) is supported.
+func ParseEncryption(data []byte) (*types.ServerSideEncryptionConfiguration, error) {
+	var cfg types.ServerSideEncryptionConfiguration
+	if err := xml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEncryption, err)
+	}
+
+	if len(cfg.Rules) != 1 {
+		return nil, fmt.Errorf("%w: exactly one rule is required", ErrInvalidEncryption)
+	}
+	def := cfg.Rules[0].ApplyServerSideEncryptionByDefault
+	if def == nil {
+		return nil, fmt.Errorf("%w: ApplyServerSideEncryptionByDefault is required", ErrInvalidEncryption)
+	}
+	if def.SSEAlgorithm != types.SSEAlgorithmAES256 || def.KMSMasterKeyID != "" {
+		return nil, fmt.Errorf("%w: only the AES256 algorithm is supported", ErrInvalidEncryption)
+	}
+	return &cfg, nil
+}
+
+// EncryptByDefault reports whether the configuration encrypts new objects.
+func EncryptByDefault(cfg *types.ServerSideEncryptionConfiguration) bool {
+	if cfg == nil {
+		return false
+	}
+	for _, rule := range cfg.Rules {
+		if def := rule.ApplyServerSideEncryptionByDefault; def != nil && def.SSEAlgorithm == types.SSEAlgorithmAES256 {
+			return true
+		}
+	}
+	return false
+}
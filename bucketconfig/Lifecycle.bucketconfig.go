@@ -0,0 +1,130 @@
+package bucketconfig
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+const (
+	// MaxLifecycleConfigurationSize caps the size of a lifecycle configuration document.
+	MaxLifecycleConfigurationSize = 256 * 1024
+	// maxLifecycleRules is the number of rules S3 accepts in one configuration.
+	maxLifecycleRules = 1000
+)
+
+// ErrInvalidLifecycle is returned for lifecycle configurations S3 would reject.
+var ErrInvalidLifecycle = errors.New("invalid lifecycle configuration")
+
+// LoadLifecycle returns the lifecycle configuration of a bucket, or nil when none is set.
+func LoadLifecycle(bucket string) (*types.LifecycleConfiguration, error) {
+	var cfg types.LifecycleConfiguration
+	found, err := load(bucket, "oblifecycle", &cfg)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// SaveLifecycle replaces the lifecycle configuration of a bucket.
+func SaveLifecycle(bucket string, cfg *types.LifecycleConfiguration) error {
+	return save(bucket, "oblifecycle", cfg)
+}
+
+// DeleteLifecycle removes the lifecycle configuration of a bucket.
+func DeleteLifecycle(bucket string) error {
+	return remove(bucket, "oblifecycle")
+}
+
+// ParseLifecycle decodes and validates a LifecycleConfiguration document.
+func ParseLifecycle(data []byte) (*types.LifecycleConfiguration, error) {
+	var cfg types.LifecycleConfiguration
+	if err := xml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLifecycle, err)
+	}
+
+	if len(cfg.Rules) == 0 || len(cfg.Rules) > maxLifecycleRules {
+		return nil, fmt.Errorf("%w: between 1 and %d rules are required", ErrInvalidLifecycle, maxLifecycleRules)
+	}
+	ids := map[string]bool{}
+	for i, rule := range cfg.Rules {
+		if rule.ID != "" {
+			if len(rule.ID) > 255 || ids[rule.ID] {
+				return nil, fmt.Errorf("%w: rule IDs must be unique and at most 255 characters", ErrInvalidLifecycle)
+			}
+			ids[rule.ID] = true
+		}
+		if err := validateLifecycleRule(rule); err != nil {
+			return nil, fmt.Errorf("%w: rule %d: %v", ErrInvalidLifecycle, i+1, err)
+		}
+	}
+	return &cfg, nil
+}
+
+func validateLifecycleRule(rule types.LifecycleRule) error {
+	if rule.Status != "Enabled" && rule.Status != "Disabled" {
+		return fmt.Errorf("status must be Enabled or Disabled")
+	}
+	if rule.Prefix != nil && rule.Filter != nil {
+		return fmt.Errorf("prefix and filter cannot both be set")
+	}
+	if rule.Expiration == nil && rule.NoncurrentVersionExpiration == nil && rule.AbortIncompleteMultipartUpload == nil {
+		return fmt.Errorf("at least one action is required")
+	}
+
+	hasTags := false
+	if f := rule.Filter; f != nil {
+		set := 0
+		for _, present := range []bool{f.Prefix != nil, f.Tag != nil, f.ObjectSizeGreaterThan != nil, f.ObjectSizeLessThan != nil, f.And != nil} {
+			if present {
+				set++
+			}
+		}
+		if set > 1 {
+			return fmt.Errorf("a filter holds one condition; combine several with And")
+		}
+		if f.And != nil && f.And.ObjectSizeLessThan > 0 && f.And.ObjectSizeLessThan <= f.And.ObjectSizeGreaterThan {
+			return fmt.Errorf("ObjectSizeLessThan must be greater than ObjectSizeGreaterThan")
+		}
+		hasTags = f.Tag != nil || (f.And != nil && len(f.And.Tags) > 0)
+	}
+
+	if e := rule.Expiration; e != nil {
+		set := 0
+		for _, present := range []bool{e.Days != 0, e.Date != "", e.ExpiredObjectDeleteMarker} {
+			if present {
+				set++
+			}
+		}
+		if set != 1 {
+			return fmt.Errorf("expiration needs exactly one of Days, Date or ExpiredObjectDeleteMarker")
+		}
+		if e.Days < 0 {
+			return fmt.Errorf("expiration days must be positive")
+		}
+		if e.Date != "" {
+			date, err := time.Parse(time.RFC3339, e.Date)
+			if err != nil || !date.UTC().Equal(date.UTC().Truncate(24*time.Hour)) {
+				return fmt.Errorf("expiration date must be midnight UTC in ISO 8601 format")
+			}
+		}
+		if e.ExpiredObjectDeleteMarker && hasTags {
+			return fmt.Errorf("ExpiredObjectDeleteMarker cannot be combined with a tag filter")
+		}
+	}
+	if n := rule.NoncurrentVersionExpiration; n != nil && (n.NoncurrentDays <= 0 || n.NewerNoncurrentVersions < 0) {
+		return fmt.Errorf("NoncurrentDays must be positive")
+	}
+	if a := rule.AbortIncompleteMultipartUpload; a != nil {
+		if a.DaysAfterInitiation <= 0 {
+			return fmt.Errorf("DaysAfterInitiation must be positive")
+		}
+		if hasTags {
+			return fmt.Errorf("AbortIncompleteMultipartUpload cannot be combined with a tag filter")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package bucketconfig
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aidenappl/openbucket-go/types"
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxNotificationConfigurationSize caps the size of a notification configuration document.
+	MaxNotificationConfigurationSize = 64 * 1024
+	// maxNotificationTargets is the number of configurations accepted in one document.
+	maxNotificationTargets = 100
+)
+
+// ErrInvalidNotification is returned for notification configurations S3 would reject.
+var ErrInvalidNotification = errors.New("invalid notification configuration")
+
+// NotificationEvents are the event types a configuration may subscribe to.
+var NotificationEvents = []string{
+	"s3:ObjectCreated:*",
+	"s3:ObjectCreated:Put",
+	"s3:ObjectCreated:Post",
+	"s3:ObjectCreated:Copy",
+	"s3:ObjectCreated:CompleteMultipartUpload",
+	"s3:ObjectRemoved:*",
+	"s3:ObjectRemoved:Delete",
+}
+
+// LoadNotification returns the notification configuration of a bucket, or nil when none is set.
+func LoadNotification(bucket string) (*types.NotificationConfiguration, error) {
+	var cfg types.NotificationConfiguration
+	found, err := load(bucket, "obnotification", &cfg)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// SaveNotification replaces the notification configuration of a bucket. An
+// empty configuration turns notifications off.
+func SaveNotification(bucket string, cfg *types.NotificationConfiguration) error {
+	if len(cfg.Targets()) == 0 {
+		return remove(bucket, "obnotification")
+	}
+	return save(bucket, "obnotification", cfg)
+}
+
+// ParseNotification decodes and validates a NotificationConfiguration
+// document. Configurations without an Id are given one. Whether the target
+// ARNs exist is up to the caller.
+func ParseNotification(data []byte) (*types.NotificationConfiguration, error) {
+	var cfg types.NotificationConfiguration
+	if err := xml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidNotification, err)
+	}
+
+	if len(cfg.Targets()) > maxNotificationTargets {
+		return nil, fmt.Errorf("%w: at most %d configurations are allowed", ErrInvalidNotification, maxNotificationTargets)
+	}
+	ids := map[string]bool{}
+	for _, list := range [][]types.NotificationTarget{cfg.TopicConfigurations, cfg.QueueConfigurations, cfg.FunctionConfigurations} {
+		for i := range list {
+			t := &list[i]
+			if t.Id == "" {
+				t.Id = uuid.New().String()
+			}
+			if ids[t.Id] {
+				return nil, fmt.Errorf("%w: configuration IDs must be unique", ErrInvalidNotification)
+			}
+			ids[t.Id] = true
+			if err := validateNotificationTarget(*t); err != nil {
+				return nil, fmt.Errorf("%w: configuration %s: %v", ErrInvalidNotification, t.Id, err)
+			}
+		}
+	}
+	return &cfg, nil
+}
+
+func validateNotificationTarget(t types.NotificationTarget) error {
+	set := 0
+	for _, arn := range []string{t.Topic, t.Queue, t.CloudFunction} {
+		if arn != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("exactly one destination ARN is required")
+	}
+
+	if len(t.Events) == 0 {
+		return fmt.Errorf("at least one event is required")
+	}
+	for _, event := range t.Events {
+		if !slices.Contains(NotificationEvents, event) {
+			return fmt.Errorf("the event %s is not supported", event)
+		}
+	}
+
+	if t.Filter != nil {
+		seen := map[string]bool{}
+		for _, rule := range t.Filter.Key.Rules {
+			name := strings.ToLower(rule.Name)
+			if name != "prefix" && name != "suffix" {
+				return fmt.Errorf("filter rule names must be prefix or suffix")
+			}
+			if seen[name] {
+				return fmt.Errorf("a filter may hold one %s rule", name)
+			}
+			seen[name] = true
+		}
+	}
+	return nil
+}
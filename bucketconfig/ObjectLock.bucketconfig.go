@@ -0,0 +1,54 @@
+package bucketconfig
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// MaxObjectLockConfigurationSize caps the size of an Object Lock configuration document.
+const MaxObjectLockConfigurationSize = 16 * 1024
+
+// ErrInvalidObjectLock is returned for Object Lock configurations S3 would reject.
+var ErrInvalidObjectLock = errors.New("invalid object lock configuration")
+
+// LoadObjectLock returns the Object Lock configuration of a bucket, or nil
+// when Object Lock is not enabled for it.
+func LoadObjectLock(bucket string) (*types.ObjectLockConfiguration, error) {
+	var cfg types.ObjectLockConfiguration
+	found, err := load(bucket, "oblock", &cfg)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// SaveObjectLock replaces the Object Lock configuration of a bucket. There is
+// no way to delete it: once enabled, Object Lock stays enabled.
+func SaveObjectLock(bucket string, cfg *types.ObjectLockConfiguration) error {
+	return save(bucket, "oblock", cfg)
+}
+
+// ParseObjectLock decodes and validates an ObjectLockConfiguration document.
+func ParseObjectLock(data []byte) (*types.ObjectLockConfiguration, error) {
+	var cfg types.ObjectLockConfiguration
+	if err := xml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidObjectLock, err)
+	}
+
+	if cfg.ObjectLockEnabled != "Enabled" {
+		return nil, fmt.Errorf("%w: ObjectLockEnabled must be Enabled", ErrInvalidObjectLock)
+	}
+	if cfg.Rule != nil {
+		d := cfg.Rule.DefaultRetention
+		if d.Mode != types.RetentionGovernance && d.Mode != types.RetentionCompliance {
+			return nil, fmt.Errorf("%w: mode must be GOVERNANCE or COMPLIANCE", ErrInvalidObjectLock)
+		}
+		if (d.Days > 0) == (d.Years > 0) || d.Days < 0 || d.Years < 0 {
+			return nil, fmt.Errorf("%w: default retention needs a positive number of either Days or Years", ErrInvalidObjectLock)
+		}
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,184 @@
+package bucketconfig
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aidenappl/openbucket-go/tools"
+	"github.com/aidenappl/openbucket-go/types"
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxReplicationConfigurationSize caps the size of a replication configuration document.
+	MaxReplicationConfigurationSize = 128 * 1024
+	// maxReplicationRules is the number of rules S3 accepts in one configuration.
+	maxReplicationRules = 1000
+)
+
+// ErrInvalidReplication is returned for replication configurations S3 would reject.
+var ErrInvalidReplication = errors.New("invalid replication configuration")
+
+// LoadReplication returns the replication configuration of a bucket, or nil when none is set.
+func LoadReplication(bucket string) (*types.ReplicationConfiguration, error) {
+	var cfg types.ReplicationConfiguration
+	found, err := load(bucket, "obreplication", &cfg)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// SaveReplication replaces the replication configuration of a bucket. It
+// holds the destination credentials, so it is saved readable by the server only.
+func SaveReplication(bucket string, cfg *types.ReplicationConfiguration) error {
+	return savePrivate(bucket, "obreplication", cfg)
+}
+
+// DeleteReplication removes the replication configuration of a bucket.
+func DeleteReplication(bucket string) error {
+	return remove(bucket, "obreplication")
+}
+
+// ParseReplication decodes and validates a ReplicationConfiguration
+// document. Rules without an ID are given one. Destination buckets may be
+// given as an ARN or a plain name; they are stored as plain names.
+func ParseReplication(data []byte) (*types.ReplicationConfiguration, error) {
+	var cfg types.ReplicationConfiguration
+	if err := xml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidReplication, err)
+	}
+
+	if len(cfg.Rules) == 0 || len(cfg.Rules) > maxReplicationRules {
+		return nil, fmt.Errorf("%w: between 1 and %d rules are required", ErrInvalidReplication, maxReplicationRules)
+	}
+	ids := map[string]bool{}
+	priorities := map[int]bool{}
+	for i := range cfg.Rules {
+		rule := &cfg.Rules[i]
+		if rule.ID == "" {
+			rule.ID = uuid.New().String()
+		}
+		if len(rule.ID) > 255 || ids[rule.ID] {
+			return nil, fmt.Errorf("%w: rule IDs must be unique and at most 255 characters", ErrInvalidReplication)
+		}
+		ids[rule.ID] = true
+		if rule.Filter != nil {
+			if priorities[rule.Priority] {
+				return nil, fmt.Errorf("%w: rule priorities must be unique", ErrInvalidReplication)
+			}
+			priorities[rule.Priority] = true
+		}
+		rule.Destination.Bucket = strings.TrimPrefix(rule.Destination.Bucket, "arn:aws:s3:::")
+		if err := validateReplicationRule(*rule); err != nil {
+			return nil, fmt.Errorf("%w: rule %s: %v", ErrInvalidReplication, rule.ID, err)
+		}
+	}
+	return &cfg, nil
+}
+
+func validateReplicationRule(rule types.ReplicationRule) error {
+	if rule.Status != "Enabled" && rule.Status != "Disabled" {
+		return fmt.Errorf("status must be Enabled or Disabled")
+	}
+	if rule.Prefix != nil && rule.Filter != nil {
+		return fmt.Errorf("prefix and filter cannot both be set")
+	}
+	if rule.Priority < 0 {
+		return fmt.Errorf("priority must not be negative")
+	}
+
+	if f := rule.Filter; f != nil {
+		set := 0
+		for _, present := range []bool{f.Prefix != nil, f.Tag != nil, f.And != nil} {
+			if present {
+				set++
+			}
+		}
+		if set > 1 {
+			return fmt.Errorf("a filter holds one condition; combine several with And")
+		}
+	}
+	if m := rule.DeleteMarkerReplication; m != nil && m.Status != "Enabled" && m.Status != "Disabled" {
+		return fmt.Errorf("delete marker replication status must be Enabled or Disabled")
+	}
+
+	d := rule.Destination
+	if !tools.ValidBucketName(d.Bucket) {
+		return fmt.Errorf("destination bucket %q is not a valid bucket name", d.Bucket)
+	}
+	endpoint, err := url.Parse(d.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return fmt.Errorf("destination endpoint must be an http or https URL")
+	}
+	if d.AccessKeyID == "" || d.SecretAccessKey == "" {
+		return fmt.Errorf("destination credentials are required")
+	}
+	return nil
+}
+
+// ReplicationRuleFor returns the enabled rule of cfg that replicates md, or
+// nil when there is none. When several rules match, the one with the
+// highest priority wins.
+func ReplicationRuleFor(cfg *types.ReplicationConfiguration, md *types.ObjectMetadata) *types.ReplicationRule {
+	var match *types.ReplicationRule
+	for i := range cfg.Rules {
+		rule := &cfg.Rules[i]
+		if rule.Status != "Enabled" || !replicationMatches(*rule, md) {
+			continue
+		}
+		if match == nil || rule.Priority > match.Priority {
+			match = rule
+		}
+	}
+	return match
+}
+
+// FindReplicationRule returns the rule of cfg with the given ID, or nil.
+func FindReplicationRule(cfg *types.ReplicationConfiguration, id string) *types.ReplicationRule {
+	for i := range cfg.Rules {
+		if cfg.Rules[i].ID == id {
+			return &cfg.Rules[i]
+		}
+	}
+	return nil
+}
+
+// replicationMatches reports whether the rule's filter, or legacy prefix,
+// selects the object.
+func replicationMatches(rule types.ReplicationRule, md *types.ObjectMetadata) bool {
+	if rule.Prefix != nil {
+		return strings.HasPrefix(md.Key, *rule.Prefix)
+	}
+	f := rule.Filter
+	switch {
+	case f == nil:
+		return true
+	case f.Prefix != nil:
+		return strings.HasPrefix(md.Key, *f.Prefix)
+	case f.Tag != nil:
+		return hasTag(md.Tags, *f.Tag)
+	case f.And != nil:
+		if !strings.HasPrefix(md.Key, f.And.Prefix) {
+			return false
+		}
+		for _, tag := range f.And.Tags {
+			if !hasTag(md.Tags, tag) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func hasTag(tags []types.Tag, want types.Tag) bool {
+	for _, tag := range tags {
+		if tag == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package bucketconfig
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+const (
+	// MaxWebsiteConfigurationSize caps the size of a website configuration document.
+	MaxWebsiteConfigurationSize = 64 * 1024
+	// maxRoutingRules is the number of routing rules S3 accepts in one configuration.
+	maxRoutingRules = 50
+)
+
+// ErrInvalidWebsite is returned for website configurations S3 would reject.
+var ErrInvalidWebsite = errors.New("invalid website configuration")
+
+// LoadWebsite returns the website configuration of a bucket, or nil when none is set.
+func LoadWebsite(bucket string) (*types.WebsiteConfiguration, error) {
+	var cfg types.WebsiteConfiguration
+	found, err := load(bucket, "obwebsite", &cfg)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// SaveWebsite replaces the website configuration of a bucket.
+func SaveWebsite(bucket string, cfg *types.WebsiteConfiguration) error {
+	return save(bucket, "obwebsite", cfg)
+}
+
+// DeleteWebsite removes the website configuration of a bucket.
+func DeleteWebsite(bucket string) error {
+	return remove(bucket, "obwebsite")
+}
+
+// ParseWebsite decodes and validates a WebsiteConfiguration document.
+func ParseWebsite(data []byte) (*types.WebsiteConfiguration, error) {
+	var cfg types.WebsiteConfiguration
+	if err := xml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWebsite, err)
+	}
+
+	if r := cfg.RedirectAllRequestsTo; r != nil {
+		if cfg.IndexDocument != nil || cfg.ErrorDocument != nil || len(cfg.RoutingRules) > 0 {
+			return nil, fmt.Errorf("%w: RedirectAllRequestsTo cannot be combined with other settings", ErrInvalidWebsite)
+		}
+		if r.HostName == "" {
+			return nil, fmt.Errorf("%w: RedirectAllRequestsTo needs a HostName", ErrInvalidWebsite)
+		}
+		if !validProtocol(r.Protocol) {
+			return nil, fmt.Errorf("%w: the protocol must be http or https", ErrInvalidWebsite)
+		}
+		return &cfg, nil
+	}
+
+	if cfg.IndexDocument == nil || cfg.IndexDocument.Suffix == "" || strings.Contains(cfg.IndexDocument.Suffix, "/") {
+		return nil, fmt.Errorf("%w: an IndexDocument suffix without slashes is required", ErrInvalidWebsite)
+	}
+	if cfg.ErrorDocument != nil && cfg.ErrorDocument.Key == "" {
+		return nil, fmt.Errorf("%w: the ErrorDocument needs a Key", ErrInvalidWebsite)
+	}
+	if len(cfg.RoutingRules) > maxRoutingRules {
+		return nil, fmt.Errorf("%w: at most %d routing rules are allowed", ErrInvalidWebsite, maxRoutingRules)
+	}
+	for i, rule := range cfg.RoutingRules {
+		if err := validateRoutingRule(rule); err != nil {
+			return nil, fmt.Errorf("%w: routing rule %d: %v", ErrInvalidWebsite, i+1, err)
+		}
+	}
+	return &cfg, nil
+}
+
+func validateRoutingRule(rule types.RoutingRule) error {
+	if c := rule.Condition; c != nil {
+		if c.KeyPrefixEquals == "" && c.HttpErrorCodeReturnedEquals == "" {
+			return fmt.Errorf("a condition needs KeyPrefixEquals or HttpErrorCodeReturnedEquals")
+		}
+		if c.HttpErrorCodeReturnedEquals != "" {
+			if code, err := strconv.Atoi(c.HttpErrorCodeReturnedEquals); err != nil || code < 400 || code > 599 {
+				return fmt.Errorf("HttpErrorCodeReturnedEquals must be a 4xx or 5xx status code")
+			}
+		}
+	}
+
+	r := rule.Redirect
+	if r.ReplaceKeyWith != "" && r.ReplaceKeyPrefixWith != "" {
+		return fmt.Errorf("ReplaceKeyWith and ReplaceKeyPrefixWith cannot both be set")
+	}
+	if r.HttpRedirectCode != "" {
+		if code, err := strconv.Atoi(r.HttpRedirectCode); err != nil || code < 300 || code > 399 {
+			return fmt.Errorf("HttpRedirectCode must be a 3xx status code")
+		}
+	}
+	if !validProtocol(r.Protocol) {
+		return fmt.Errorf("the protocol must be http or https")
+	}
+	return nil
+}
+
+func validProtocol(protocol string) bool {
+	return protocol == "" || protocol == "http" || protocol == "https"
+}
+
+// WebsiteRoutingRule returns the first routing rule of cfg that applies to a
+// request for key. status is the error the request would fail with, or 0
+// before the object has been looked up; rules with an error code condition
+// only apply once it is known.
+func WebsiteRoutingRule(cfg *types.WebsiteConfiguration, key string, status int) *types.RoutingRule {
+	for i := range cfg.RoutingRules {
+		rule := &cfg.RoutingRules[i]
+		c := rule.Condition
+		if c == nil {
+			return rule
+		}
+		if !strings.HasPrefix(key, c.KeyPrefixEquals) {
+			continue
+		}
+		if c.HttpErrorCodeReturnedEquals == "" || c.HttpErrorCodeReturnedEquals == strconv.Itoa(status) {
+			return rule
+		}
+	}
+	return nil
+}
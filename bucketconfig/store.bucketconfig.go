@@ -0,0 +1,69 @@
+package bucketconfig
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aidenappl/openbucket-go/tools"
+)
+
+// path returns the location of a bucket configuration file, which lives next
+// to the bucket's .obpermissions file with the given extension.
+func path(bucket, ext string) string {
+	return fmt.Sprintf("buckets/%s.%s", bucket, ext)
+}
+
+// load decodes the XML configuration file of a bucket into v. It returns
+// false without an error when the bucket has no such configuration.
+func load(bucket, ext string, v any) (bool, error) {
+	if bucket == "" {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(path(bucket, ext))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to read bucket configuration: %v", err)
+	}
+
+	if err := xml.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("failed to decode bucket configuration: %v", err)
+	}
+	return true, nil
+}
+
+// save replaces the XML configuration file of a bucket.
+func save(bucket, ext string, v any) error {
+	return write(bucket, ext, v, 0644)
+}
+
+// savePrivate replaces a configuration file holding credentials, which only
+// the user running the server may read.
+func savePrivate(bucket, ext string, v any) error {
+	return write(bucket, ext, v, 0600)
+}
+
+func write(bucket, ext string, v any, perm os.FileMode) error {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling bucket configuration: %v", err)
+	}
+
+	if err := tools.WriteFileAtomic(path(bucket, ext), data, perm); err != nil {
+		return fmt.Errorf("error writing bucket configuration: %v", err)
+	}
+	return nil
+}
+
+// remove deletes the configuration file of a bucket. Deleting a missing
+// configuration is not an error.
+func remove(bucket, ext string) error {
+	err := os.Remove(path(bucket, ext))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error deleting bucket configuration: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package chunkstore
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/aidenappl/openbucket-go/tools"
+	"github.com/aidenappl/openbucket-go/types"
+	"github.com/google/uuid"
+)
+
+// Dir is the directory holding the chunk store. Blobs live under the first
+// two hex digits of their hash, next to a .obchunk record with their
+// reference count.
+const Dir = "chunks"
+
+// ErrNotFound is returned when a chunk is not in the store.
+var ErrNotFound = errors.New("no such chunk")
+
+// mu serializes reference count updates, which read and rewrite the record.
+var mu sync.Mutex
+
+// Path returns the location of the blob with the given hash.
+func Path(hash string) string {
+	return filepath.Join(Dir, hash[:2], hash)
+}
+
+func recordPath(hash string) string {
+	return Path(hash) + ".obchunk"
+}
+
+// StagingPath returns a new location to write content to before it is added
+// to the store with Add. Staged content is on the same file system as the
+// store, so adding it is a rename.
+func StagingPath() string {
+	return filepath.Join(Dir, "staging", uuid.NewString())
+}
+
+func load(hash string) (*types.ContentChunk, error) {
+	data, err := os.ReadFile(recordPath(hash))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, hash)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read chunk record: %v", err)
+	}
+
+	var chunk types.ContentChunk
+	if err := xml.Unmarshal(data, &chunk); err != nil {
+		return nil, fmt.Errorf("failed to decode chunk record: %v", err)
+	}
+	return &chunk, nil
+}
+
+func save(chunk *types.ContentChunk) error {
+	data, err := xml.MarshalIndent(chunk, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling chunk record: %v", err)
+	}
+	if err := tools.WriteFileAtomic(recordPath(chunk.Hash), data, 0644); err != nil {
+		return fmt.Errorf("error writing chunk record: %v", err)
+	}
+	return nil
+}
+
+// Add takes one reference to the content staged at staged, described by
+// chunk. When the store already holds content with the same hash the staged
+// copy is discarded, otherwise it is moved into the store.
+func Add(staged string, chunk types.ContentChunk) (*types.ContentChunk, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	existing, err := load(chunk.Hash)
+	if err == nil {
+		os.Remove(staged)
+		existing.Refs++
+		if err := save(existing); err != nil {
+			return nil, err
+		}
+		return existing, nil
+	} else if !errors.Is(err, ErrNotFound) {
+		os.Remove(staged)
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(Path(chunk.Hash)), os.ModePerm); err != nil {
+		os.Remove(staged)
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.Rename(staged, Path(chunk.Hash)); err != nil {
+		os.Remove(staged)
+		return nil, fmt.Errorf("error saving chunk: %w", err)
+	}
+	chunk.Refs = 1
+	if err := save(&chunk); err != nil {
+		os.Remove(Path(chunk.Hash))
+		return nil, err
+	}
+	return &chunk, nil
+}
+
+// Acquire takes another reference to a chunk already in the store.
+func Acquire(hash string) (*types.ContentChunk, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	chunk, err := load(hash)
+	if err != nil {
+		return nil, err
+	}
+	chunk.Refs++
+	if err := save(chunk); err != nil {
+		return nil, err
+	}
+	return chunk, nil
+}
+
+// Release drops a reference to a chunk and removes the chunk once nothing
+// refers to it anymore.
+func Release(hash string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	chunk, err := load(hash)
+	if err != nil {
+		return err
+	}
+	chunk.Refs--
+	if chunk.Refs > 0 {
+		return save(chunk)
+	}
+
+	if err := os.Remove(Path(hash)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete chunk: %w", err)
+	}
+	if err := os.Remove(recordPath(hash)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete chunk record: %w", err)
+	}
+	return nil
+}
+
+// Usage summarizes the content of the chunk store.
+type Usage struct {
+	Chunks int
+	Refs   int
+	// LogicalSize counts the content of every reference, StoredSize the
+	// bytes the store takes on disk.
+	LogicalSize int64
+	StoredSize  int64
+}
+
+// Stats reads the records of every chunk in the store.
+func Stats() (*Usage, error) {
+	usage := &Usage{}
+	err := filepath.WalkDir(Dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, os.ErrNotExist) && path == Dir {
+			return filepath.SkipDir
+		} else if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".obchunk") {
+			return nil
+		}
+
+		chunk, err := load(strings.TrimSuffix(d.Name(), ".obchunk"))
+		if err != nil {
+			return err
+		}
+		usage.Chunks++
+		usage.Refs += chunk.Refs
+		usage.LogicalSize += chunk.Size * int64(chunk.Refs)
+		usage.StoredSize += chunk.StoredSize
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aidenappl/openbucket-go/handler"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+func createUser(cmd *cobra.Command, args []string) {
+	email, _ := cmd.Flags().GetString("email")
+	user, err := handler.CreateUser(args[0], email)
+	if err != nil {
+		fmt.Println("Error creating user:", err)
+		return
+	}
+	fmt.Printf("User %s created\n", user.Name)
+}
+
+func deleteUser(cmd *cobra.Command, args []string) {
+	if err := handler.DeleteUser(args[0]); err != nil {
+		fmt.Println("Error deleting user:", err)
+		return
+	}
+	fmt.Printf("User %s deleted\n", args[0])
+}
+
+func listUsers(cmd *cobra.Command, args []string) {
+	users, err := handler.ListUsers()
+	if err != nil {
+		fmt.Println("Error listing users:", err)
+		return
+	}
+	if len(users) == 0 {
+		fmt.Println("No users found")
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"Name", "Email", "Groups", "Policies", "Created At"})
+	for _, u := range users {
+		table.Append([]string{
+			u.Name,
+			u.Email,
+			strings.Join(u.Groups, ", "),
+			strings.Join(u.Policies, ", "),
+			u.DateCreated.Format("2006-01-02 15:04:05"),
+		})
+	}
+	table.Render()
+}
+
+func createUserKey(cmd *cobra.Command, args []string) {
+	creds, err := handler.CreateUserAccessKey(args[0])
+	if err != nil {
+		fmt.Println("Error creating access key:", err)
+		return
+	}
+	fmt.Printf("Generated Credentials for %s:\nAccess Key ID: %s\nSecret Access Key: %s\n", creds.User, creds.KeyID, creds.SecretKey)
+}
+
+func attachKey(cmd *cobra.Command, args []string) {
+	if err := handler.AttachUserKey(args[0], args[1]); err != nil {
+		fmt.Println("Error attaching access key:", err)
+		return
+	}
+	fmt.Printf("Access key %s now belongs to user %s\n", args[1], args[0])
+}
+
+func deleteKey(cmd *cobra.Command, args []string) {
+	if err := handler.DeleteAccessKey("", args[0]); err != nil {
+		fmt.Println("Error deleting access key:", err)
+		return
+	}
+	fmt.Printf("Access key %s deleted\n", args[0])
+}
+
+func createGroup(cmd *cobra.Command, args []string) {
+	group, err := handler.CreateGroup(args[0])
+	if err != nil {
+		fmt.Println("Error creating group:", err)
+		return
+	}
+	fmt.Printf("Group %s created\n", group.Name)
+}
+
+func deleteGroup(cmd *cobra.Command, args []string) {
+	if err := handler.DeleteGroup(args[0]); err != nil {
+		fmt.Println("Error deleting group:", err)
+		return
+	}
+	fmt.Printf("Group %s deleted\n", args[0])
+}
+
+func listGroups(cmd *cobra.Command, args []string) {
+	groups, err := handler.ListGroups()
+	if err != nil {
+		fmt.Println("Error listing groups:", err)
+		return
+	}
+	if len(groups) == 0 {
+		fmt.Println("No groups found")
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"Name", "Policies", "Created At"})
+	for _, g := range groups {
+		table.Append([]string{g.Name, strings.Join(g.Policies, ", "), g.DateCreated.Format("2006-01-02 15:04:05")})
+	}
+	table.Render()
+}
+
+func addToGroup(cmd *cobra.Command, args []string) {
+	if err := handler.AddUserToGroup(args[0], args[1]); err != nil {
+		fmt.Println("Error adding user to group:", err)
+		return
+	}
+	fmt.Printf("User %s added to group %s\n", args[0], args[1])
+}
+
+func removeFromGroup(cmd *cobra.Command, args []string) {
+	if err := handler.RemoveUserFromGroup(args[0], args[1]); err != nil {
+		fmt.Println("Error removing user from group:", err)
+		return
+	}
+	fmt.Printf("User %s removed from group %s\n", args[0], args[1])
+}
+
+func putPolicy(cmd *cobra.Command, args []string) {
+	document, err := os.ReadFile(args[1])
+	if err != nil {
+		fmt.Println("Error reading policy document:", err)
+		return
+	}
+	if _, err := handler.PutIdentityPolicy(args[0], document); err != nil {
+		fmt.Println("Error saving policy:", err)
+		return
+	}
+	fmt.Printf("Policy %s saved\n", args[0])
+}
+
+func deletePolicy(cmd *cobra.Command, args []string) {
+	if err := handler.DeleteIdentityPolicy(args[0]); err != nil {
+		fmt.Println("Error deleting policy:", err)
+		return
+	}
+	fmt.Printf("Policy %s deleted\n", args[0])
+}
+
+func listPolicies(cmd *cobra.Command, args []string) {
+	policies, err := handler.ListIdentityPolicies()
+	if err != nil {
+		fmt.Println("Error listing policies:", err)
+		return
+	}
+	if len(policies) == 0 {
+		fmt.Println("No policies found")
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"Name", "Created At"})
+	for _, p := range policies {
+		table.Append([]string{p.Name, p.DateCreated.Format("2006-01-02 15:04:05")})
+	}
+	table.Render()
+}
+
+func showPolicy(cmd *cobra.Command, args []string) {
+	p, err := handler.GetIdentityPolicy(args[0])
+	if err != nil {
+		fmt.Println("Error loading policy:", err)
+		return
+	}
+	fmt.Println(p.Document)
+}
+
+func attachPolicy(cmd *cobra.Command, args []string) {
+	targetType, targetName, ok := policyTarget(cmd)
+	if !ok {
+		return
+	}
+	if err := handler.AttachPolicy(targetType, targetName, args[0]); err != nil {
+		fmt.Println("Error attaching policy:", err)
+		return
+	}
+	fmt.Printf("Policy %s attached to %s %s\n", args[0], targetType, targetName)
+}
+
+func detachPolicy(cmd *cobra.Command, args []string) {
+	targetType, targetName, ok := policyTarget(cmd)
+	if !ok {
+		return
+	}
+	if err := handler.DetachPolicy(targetType, targetName, args[0]); err != nil {
+		fmt.Println("Error detaching policy:", err)
+		return
+	}
+	fmt.Printf("Policy %s detached from %s %s\n", args[0], targetType, targetName)
+}
+
+// policyTarget reads the --user / --group / --role flags; exactly one must be set.
+func policyTarget(cmd *cobra.Command) (string, string, bool) {
+	var targetType, targetName string
+	for _, t := range []string{handler.PolicyTargetUser, handler.PolicyTargetGroup, handler.PolicyTargetRole} {
+		name, _ := cmd.Flags().GetString(t)
+		if name == "" {
+			continue
+		}
+		if targetName != "" {
+			targetName = ""
+			break
+		}
+		targetType, targetName = t, name
+	}
+	if targetName == "" {
+		fmt.Println("Specify exactly one of --user, --group or --role")
+		return "", "", false
+	}
+	return targetType, targetName, true
+}
+
+func createRole(cmd *cobra.Command, args []string) {
+	trustPolicy, err := os.ReadFile(args[1])
+	if err != nil {
+		fmt.Println("Error reading trust policy:", err)
+		return
+	}
+	maxDuration, _ := cmd.Flags().GetInt("max-duration")
+	role, err := handler.CreateRole(args[0], trustPolicy, maxDuration)
+	if err != nil {
+		fmt.Println("Error creating role:", err)
+		return
+	}
+	fmt.Printf("Role %s created (arn:aws:iam:::role/%s)\n", role.Name, role.Name)
+}
+
+func deleteRole(cmd *cobra.Command, args []string) {
+	if err := handler.DeleteRole(args[0]); err != nil {
+		fmt.Println("Error deleting role:", err)
+		return
+	}
+	fmt.Printf("Role %s deleted\n", args[0])
+}
+
+func listRoles(cmd *cobra.Command, args []string) {
+	roles, err := handler.ListRoles()
+	if err != nil {
+		fmt.Println("Error listing roles:", err)
+		return
+	}
+	if len(roles) == 0 {
+		fmt.Println("No roles found")
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"Name", "Policies", "Max Session", "Created At"})
+	for _, r := range roles {
+		table.Append([]string{
+			r.Name,
+			strings.Join(r.Policies, ", "),
+			fmt.Sprintf("%ds", r.MaxSessionDuration),
+			r.DateCreated.Format("2006-01-02 15:04:05"),
+		})
+	}
+	table.Render()
+}
@@ -3,11 +3,19 @@ package cli
 import (
 	"fmt"
 	"log"
+	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/aidenappl/openbucket-go/auth"
+	"github.com/aidenappl/openbucket-go/bucketconfig"
 	"github.com/aidenappl/openbucket-go/handler"
+	"github.com/aidenappl/openbucket-go/lifecycle"
+	"github.com/aidenappl/openbucket-go/metadata"
+	"github.com/aidenappl/openbucket-go/middleware"
 	"github.com/aidenappl/openbucket-go/types"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
@@ -35,18 +43,33 @@ func createBucket(cmd *cobra.Command, args []string) {
 }
 
 func listBuckets(cmd *cobra.Command, args []string) {
-	buckets, err := handler.ListBuckets()
-	if err != nil {
-		fmt.Println("Error listing buckets:", err)
-		return
+	var buckets []types.Bucket
+	if keyID, _ := cmd.Flags().GetString("key-id"); keyID != "" {
+		visible, err := handler.ListBucketsFor(func(bucket string) bool {
+			return middleware.KeyAllowedOn(keyID, types.ActionListBucket, bucket, "")
+		})
+		if err != nil {
+			fmt.Println("Error listing buckets:", err)
+			return
+		}
+		buckets = visible
+	} else {
+		all, err := handler.ListBuckets()
+		if err != nil {
+			fmt.Println("Error listing buckets:", err)
+			return
+		}
+		buckets = *all
 	}
-	if len(*buckets) == 0 {
+	if len(buckets) == 0 {
 		fmt.Println("No buckets found")
 		return
 	}
 	table := tablewriter.NewWriter(os.Stdout)
-	table.Header([]string{"Bucket Name", "Creation Date"})
-	table.Bulk(*buckets)
+	table.Header([]string{"Bucket Name", "Owner", "Creation Date"})
+	for _, bucket := range buckets {
+		table.Append([]string{bucket.Name, bucket.Owner.DisplayName, time.Time(bucket.CreationDate).Format("2006-01-02 15:04:05")})
+	}
 	table.Render()
 }
 
@@ -134,7 +157,13 @@ func listObjects(cmd *cobra.Command, args []string) error {
 		delimiter = "/"
 	}
 
-	objs, err := handler.ListObjects(bucket)
+	var objs []types.ObjectMetadata
+	remote, err := serverHoldsIndex()
+	if remote {
+		err = callServer(cmd, http.MethodGet, "/_admin/buckets/"+url.PathEscape(bucket)+"/objects", nil, &objs)
+	} else if err == nil {
+		objs, err = handler.ListObjects(bucket)
+	}
 	if err != nil {
 		return fmt.Errorf("list objects: %w", err)
 	}
@@ -148,7 +177,7 @@ func listObjects(cmd *cobra.Command, args []string) error {
 
 	for _, o := range objs {
 		typ := "FILE"
-		size := fmt.Sprintf("%d", 0)
+		size := fmt.Sprintf("%d", o.Size)
 
 		if strings.HasSuffix(o.Key, "/") {
 			typ = "DIR"
@@ -182,3 +211,135 @@ func listCredentials(cmd *cobra.Command, args []string) {
 	}
 	table.Render()
 }
+
+func lifecycleDryRun(cmd *cobra.Command, args []string) {
+	now := time.Now()
+	if at, _ := cmd.Flags().GetString("at"); at != "" {
+		parsed, err := time.Parse("2006-01-02", at)
+		if err != nil {
+			fmt.Println("Invalid --at date, expected YYYY-MM-DD:", err)
+			return
+		}
+		now = parsed
+	}
+
+	var actions []lifecycle.Action
+	remote, err := serverHoldsIndex()
+	if remote {
+		query := url.Values{"bucket": args, "at": {now.Format(time.RFC3339)}}
+		err = callServer(cmd, http.MethodGet, "/_admin/lifecycle", query, &actions)
+	} else if err == nil {
+		actions, err = lifecycle.Run(now, args, true)
+	}
+	if err != nil {
+		fmt.Println("Error evaluating lifecycle rules:", err)
+		return
+	}
+	if len(actions) == 0 {
+		fmt.Println("No lifecycle actions due")
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"Bucket", "Key", "Rule", "Action", "Due"})
+	for _, a := range actions {
+		table.Append([]string{a.Bucket, a.Key, a.Rule, a.Kind, a.Due.Format("2006-01-02 15:04:05")})
+	}
+	table.Render()
+}
+
+func rotateSSEKey(cmd *cobra.Command, args []string) {
+	var rotation *handler.KeyRotation
+	remote, err := serverHoldsIndex()
+	if remote {
+		err = callServer(cmd, http.MethodPost, "/_admin/sse/rotate", nil, &rotation)
+	} else if err == nil {
+		rotation, err = handler.RotateEncryptionKey()
+	}
+	if rotation != nil {
+		fmt.Printf("New key-encryption key %s is active; %d data keys re-wrapped\n", rotation.KeyID, rotation.Rewrapped)
+	}
+	if err != nil {
+		fmt.Println("Error rotating key-encryption key, old keys were kept:", err)
+		return
+	}
+	for _, id := range rotation.Retired {
+		fmt.Println("Retired key-encryption key", id)
+	}
+}
+
+func setCompression(cmd *cobra.Command, args []string) {
+	bucket, algorithm := args[0], args[1]
+	if info, err := os.Stat(filepath.Join("buckets", bucket)); err != nil || !info.IsDir() {
+		fmt.Println("Bucket not found:", bucket)
+		return
+	}
+	if algorithm == "off" {
+		if err := bucketconfig.DeleteCompression(bucket); err != nil {
+			fmt.Println("Error removing compression configuration:", err)
+			return
+		}
+		fmt.Println("Compression turned off for new objects in", bucket)
+		return
+	}
+
+	contentTypes, _ := cmd.Flags().GetStringSlice("content-type")
+	cfg := &types.CompressionConfiguration{Algorithm: algorithm, ContentTypes: contentTypes}
+	if err := bucketconfig.ValidateCompression(cfg); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := bucketconfig.SaveCompression(bucket, cfg); err != nil {
+		fmt.Println("Error saving compression configuration:", err)
+		return
+	}
+	fmt.Printf("New objects in %s are compressed with %s\n", bucket, algorithm)
+}
+
+func stats(cmd *cobra.Command, args []string) {
+	var usage *handler.StorageUsage
+	remote, err := serverHoldsIndex()
+	if remote {
+		err = callServer(cmd, http.MethodGet, "/_admin/stats", nil, &usage)
+	} else if err == nil {
+		usage, err = handler.StorageStats()
+	}
+	if err != nil {
+		fmt.Println("Error measuring storage usage:", err)
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.Header([]string{"Bucket", "Objects", "Logical Size", "Stored Size", "Deduplicated"})
+	for _, b := range usage.Buckets {
+		table.Append([]string{b.Bucket, fmt.Sprint(b.Objects), fmt.Sprint(b.LogicalSize), fmt.Sprint(b.StoredSize), fmt.Sprint(b.ChunkedSize)})
+	}
+	chunks := usage.Chunks
+	table.Append([]string{"(chunk store)", fmt.Sprintf("%d chunks, %d refs", chunks.Chunks, chunks.Refs), "", fmt.Sprint(chunks.StoredSize), fmt.Sprint(chunks.LogicalSize)})
+	table.Render()
+
+	logical, physical := usage.LogicalSize(), usage.StoredSize()
+	fmt.Printf("Logical size: %d bytes\n", logical)
+	fmt.Printf("Physical size: %d bytes\n", physical)
+	if logical > 0 {
+		fmt.Printf("Saved: %d bytes (%.1f%%)\n", logical-physical, float64(logical-physical)*100/float64(logical))
+	}
+}
+
+func reindex(cmd *cobra.Command, args []string) {
+	var count int
+	remote, err := serverHoldsIndex()
+	if remote {
+		var result struct {
+			Indexed int `json:"indexed"`
+		}
+		err = callServer(cmd, http.MethodPost, "/_admin/reindex", nil, &result)
+		count = result.Indexed
+	} else if err == nil {
+		count, err = metadata.Reindex()
+	}
+	if err != nil {
+		fmt.Println("Error rebuilding metadata index:", err)
+		return
+	}
+	fmt.Printf("Indexed metadata of %d objects\n", count)
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/aws"
+	"github.com/aidenappl/openbucket-go/env"
+	"github.com/aidenappl/openbucket-go/metadata"
+	"github.com/spf13/cobra"
+)
+
+// addServerFlags adds the flags used to reach a running server to cmd.
+func addServerFlags(cmd *cobra.Command) {
+	cmd.Flags().String("endpoint", "http://localhost:"+env.Port, "address of the server")
+	cmd.Flags().String("access-key", "", "access key to sign with (default $AWS_ACCESS_KEY_ID)")
+	cmd.Flags().String("secret-key", "", "secret key to sign with (default $AWS_SECRET_ACCESS_KEY)")
+}
+
+// serverCredentials returns the credentials given to cmd, falling back to
+// the AWS environment variables.
+func serverCredentials(cmd *cobra.Command) (string, string, error) {
+	accessKey, _ := cmd.Flags().GetString("access-key")
+	secretKey, _ := cmd.Flags().GetString("secret-key")
+	if accessKey == "" {
+		accessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	if secretKey == "" {
+		secretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+	if accessKey == "" || secretKey == "" {
+		return "", "", errors.New("credentials are required: use --access-key and --secret-key or set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	return accessKey, secretKey, nil
+}
+
+// serverHoldsIndex reports whether the metadata index is held by another
+// process, in which case commands that need it are sent to the server. When
+// it is not, the index is opened for this process.
+func serverHoldsIndex() (bool, error) {
+	err := metadata.OpenIndex()
+	if errors.Is(err, metadata.ErrIndexInUse) {
+		return true, nil
+	}
+	return false, err
+}
+
+// callServer sends a signed request to the admin API of the server at the
+// --endpoint of cmd and decodes its JSON answer into out.
+func callServer(cmd *cobra.Command, method, path string, query url.Values, out any) error {
+	endpoint, _ := cmd.Flags().GetString("endpoint")
+	accessKey, secretKey, err := serverCredentials(cmd)
+	if err != nil {
+		return err
+	}
+
+	target := strings.TrimSuffix(endpoint, "/") + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, target, nil)
+	if err != nil {
+		return err
+	}
+	aws.SignRequest(req, aws.Region, accessKey, secretKey, os.Getenv("AWS_SESSION_TOKEN"), time.Now())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("the metadata index is held by another process and the server could not be reached: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("server answered %s\n%s", resp.Status, body)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
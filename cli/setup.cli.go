@@ -49,13 +49,14 @@ func SetupCLI() {
 	}
 	rootCmd.AddCommand(grantUpdateCmd)
 
-	// `openbucket list-buckets`
+	// `openbucket list-buckets [--key-id]`
 	// This command lists all buckets or the buckets accessible by a specific user.
 	var listBucketsCmd = &cobra.Command{
 		Use:   "list-buckets",
 		Short: "List all buckets",
 		Run:   listBuckets,
 	}
+	listBucketsCmd.Flags().String("key-id", "", "only list the buckets this access key may list the objects of")
 	rootCmd.AddCommand(listBucketsCmd)
 
 	// `openbucket permissions [bucket_name]`
@@ -80,6 +81,7 @@ func SetupCLI() {
 	}
 	listObjectsCmd.Flags().StringP("prefix", "p", "", "only keys that begin with this prefix")
 	listObjectsCmd.Flags().StringP("delimiter", "d", "/", "path delimiter (default '/')")
+	addServerFlags(listObjectsCmd)
 
 	rootCmd.AddCommand(listObjectsCmd)
 
@@ -92,6 +94,267 @@ func SetupCLI() {
 	}
 	rootCmd.AddCommand(listCredentialsCmd)
 
+	// `openbucket create-user [name] [--email]`
+	// This command creates a user that can own access keys, join groups and hold policies.
+	var createUserCmd = &cobra.Command{
+		Use:   "create-user [name]",
+		Short: "Create a new user",
+		Args:  cobra.ExactArgs(1),
+		Run:   createUser,
+	}
+	createUserCmd.Flags().String("email", "", "email address used for emailAddress= grants")
+	rootCmd.AddCommand(createUserCmd)
+
+	// `openbucket delete-user [name]`
+	// This command deletes a user that no longer owns any access keys.
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "delete-user [name]",
+		Short: "Delete a user",
+		Args:  cobra.ExactArgs(1),
+		Run:   deleteUser,
+	})
+
+	// `openbucket list-users`
+	// This command lists all users.
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "list-users",
+		Short: "List all users",
+		Run:   listUsers,
+	})
+
+	// `openbucket create-user-key [user]`
+	// This command generates a new access key owned by a user.
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "create-user-key [user]",
+		Short: "Generate a new access key for a user",
+		Args:  cobra.ExactArgs(1),
+		Run:   createUserKey,
+	})
+
+	// `openbucket attach-key [user] [key_id]`
+	// This command makes an existing access key belong to a user.
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "attach-key [user] [key_id]",
+		Short: "Attach an existing access key to a user",
+		Args:  cobra.ExactArgs(2),
+		Run:   attachKey,
+	})
+
+	// `openbucket delete-key [key_id]`
+	// This command deletes an access key.
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "delete-key [key_id]",
+		Short: "Delete an access key",
+		Args:  cobra.ExactArgs(1),
+		Run:   deleteKey,
+	})
+
+	// `openbucket create-group [name]`
+	// This command creates a new group.
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "create-group [name]",
+		Short: "Create a new group",
+		Args:  cobra.ExactArgs(1),
+		Run:   createGroup,
+	})
+
+	// `openbucket delete-group [name]`
+	// This command deletes a group and its memberships.
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "delete-group [name]",
+		Short: "Delete a group",
+		Args:  cobra.ExactArgs(1),
+		Run:   deleteGroup,
+	})
+
+	// `openbucket list-groups`
+	// This command lists all groups.
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "list-groups",
+		Short: "List all groups",
+		Run:   listGroups,
+	})
+
+	// `openbucket add-to-group [user] [group]`
+	// This command adds a user to a group.
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "add-to-group [user] [group]",
+		Short: "Add a user to a group",
+		Args:  cobra.ExactArgs(2),
+		Run:   addToGroup,
+	})
+
+	// `openbucket remove-from-group [user] [group]`
+	// This command removes a user from a group.
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "remove-from-group [user] [group]",
+		Short: "Remove a user from a group",
+		Args:  cobra.ExactArgs(2),
+		Run:   removeFromGroup,
+	})
+
+	// `openbucket put-policy [name] [policy.json]`
+	// This command creates or replaces an identity policy from a JSON document.
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "put-policy [name] [policy.json]",
+		Short: "Create or replace an identity policy",
+		Args:  cobra.ExactArgs(2),
+		Run:   putPolicy,
+	})
+
+	// `openbucket delete-policy [name]`
+	// This command deletes an identity policy and detaches it everywhere.
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "delete-policy [name]",
+		Short: "Delete an identity policy",
+		Args:  cobra.ExactArgs(1),
+		Run:   deletePolicy,
+	})
+
+	// `openbucket list-policies`
+	// This command lists all identity policies.
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "list-policies",
+		Short: "List all identity policies",
+		Run:   listPolicies,
+	})
+
+	// `openbucket show-policy [name]`
+	// This command prints the document of an identity policy.
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "show-policy [name]",
+		Short: "Show an identity policy document",
+		Args:  cobra.ExactArgs(1),
+		Run:   showPolicy,
+	})
+
+	// `openbucket attach-policy [policy] --user [user] | --group [group] | --role [role]`
+	// This command attaches an identity policy to a user, group or role.
+	var attachPolicyCmd = &cobra.Command{
+		Use:   "attach-policy [policy]",
+		Short: "Attach an identity policy to a user, group or role",
+		Args:  cobra.ExactArgs(1),
+		Run:   attachPolicy,
+	}
+	attachPolicyCmd.Flags().String("user", "", "user to attach the policy to")
+	attachPolicyCmd.Flags().String("group", "", "group to attach the policy to")
+	attachPolicyCmd.Flags().String("role", "", "role to attach the policy to")
+	rootCmd.AddCommand(attachPolicyCmd)
+
+	// `openbucket detach-policy [policy] --user [user] | --group [group] | --role [role]`
+	// This command detaches an identity policy from a user, group or role.
+	var detachPolicyCmd = &cobra.Command{
+		Use:   "detach-policy [policy]",
+		Short: "Detach an identity policy from a user, group or role",
+		Args:  cobra.ExactArgs(1),
+		Run:   detachPolicy,
+	}
+	detachPolicyCmd.Flags().String("user", "", "user to detach the policy from")
+	detachPolicyCmd.Flags().String("group", "", "group to detach the policy from")
+	detachPolicyCmd.Flags().String("role", "", "role to detach the policy from")
+	rootCmd.AddCommand(detachPolicyCmd)
+
+	// `openbucket create-role [name] [trust-policy.json] [--max-duration]`
+	// This command creates a role that the principals in its trust policy can assume through STS.
+	var createRoleCmd = &cobra.Command{
+		Use:   "create-role [name] [trust-policy.json]",
+		Short: "Create a role that can be assumed through STS",
+		Args:  cobra.ExactArgs(2),
+		Run:   createRole,
+	}
+	createRoleCmd.Flags().Int("max-duration", 0, "longest role session in seconds (default 3600)")
+	rootCmd.AddCommand(createRoleCmd)
+
+	// `openbucket delete-role [name]`
+	// This command deletes a role; its outstanding sessions stop working.
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "delete-role [name]",
+		Short: "Delete a role",
+		Args:  cobra.ExactArgs(1),
+		Run:   deleteRole,
+	})
+
+	// `openbucket list-roles`
+	// This command lists all roles.
+	rootCmd.AddCommand(&cobra.Command{
+		Use:   "list-roles",
+		Short: "List all roles",
+		Run:   listRoles,
+	})
+
+	// `openbucket lifecycle-dry-run [bucket...] [--at]`
+	// This command shows the objects the lifecycle rules would expire, without deleting them.
+	var lifecycleDryRunCmd = &cobra.Command{
+		Use:   "lifecycle-dry-run [bucket...]",
+		Short: "Show what the lifecycle rules would delete",
+		Run:   lifecycleDryRun,
+	}
+	lifecycleDryRunCmd.Flags().String("at", "", "evaluate the rules as of this date (YYYY-MM-DD)")
+	addServerFlags(lifecycleDryRunCmd)
+	rootCmd.AddCommand(lifecycleDryRunCmd)
+
+	// `openbucket rotate-sse-key`
+	// This command activates a new key-encryption key and re-wraps the data keys of encrypted objects with it.
+	var rotateSSEKeyCmd = &cobra.Command{
+		Use:   "rotate-sse-key",
+		Short: "Rotate the key that wraps the data keys of encrypted objects",
+		Args:  cobra.NoArgs,
+		Run:   rotateSSEKey,
+	}
+	addServerFlags(rotateSSEKeyCmd)
+	rootCmd.AddCommand(rotateSSEKeyCmd)
+
+	// `openbucket set-compression [bucket] [zstd|gzip|off] [--content-type]`
+	// This command sets how new objects in a bucket are compressed at rest.
+	var setCompressionCmd = &cobra.Command{
+		Use:   "set-compression [bucket] [zstd|gzip|off]",
+		Short: "Compress new objects in a bucket at rest",
+		Args:  cobra.ExactArgs(2),
+		Run:   setCompression,
+	}
+	setCompressionCmd.Flags().StringSlice("content-type", nil, "content types to compress, e.g. text/* (default common text formats)")
+	rootCmd.AddCommand(setCompressionCmd)
+
+	// `openbucket stats`
+	// This command shows the logical size of the stored objects against the space they take on disk.
+	var statsCmd = &cobra.Command{
+		Use:   "stats",
+		Short: "Show logical and physical storage usage",
+		Args:  cobra.NoArgs,
+		Run:   stats,
+	}
+	addServerFlags(statsCmd)
+	rootCmd.AddCommand(statsCmd)
+
+	// `openbucket reindex`
+	// This command rebuilds the metadata index from the .obmeta sidecar files.
+	var reindexCmd = &cobra.Command{
+		Use:   "reindex",
+		Short: "Rebuild the metadata index from the sidecar files",
+		Args:  cobra.NoArgs,
+		Run:   reindex,
+	}
+	addServerFlags(reindexCmd)
+	rootCmd.AddCommand(reindexCmd)
+
+	// The commands above that read or write the metadata index are sent to
+	// the admin API of the server, with --endpoint and the credentials, while
+	// a running server holds the index.
+
+	// `openbucket watch [bucket] [--prefix] [--suffix] [--events]`
+	// This command tails the object events of a bucket from a running server.
+	var watchCmd = &cobra.Command{
+		Use:   "watch [bucket]",
+		Short: "Stream the object events of a bucket as they happen",
+		Args:  cobra.ExactArgs(1),
+		Run:   watch,
+	}
+	addServerFlags(watchCmd)
+	watchCmd.Flags().String("prefix", "", "only show keys with this prefix")
+	watchCmd.Flags().String("suffix", "", "only show keys with this suffix")
+	watchCmd.Flags().StringSlice("events", nil, "event types to show, e.g. s3:ObjectCreated:* (default all)")
+	rootCmd.AddCommand(watchCmd)
+
 	if err := rootCmd.Execute(); err != nil {
 		log.Println("Error executing CLI command:", err)
 		os.Exit(1)
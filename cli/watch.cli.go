@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/aws"
+	"github.com/aidenappl/openbucket-go/types"
+	"github.com/spf13/cobra"
+)
+
+// watchRetryDelay is how long watch waits before reconnecting a lost stream.
+const watchRetryDelay = 2 * time.Second
+
+// errStreamRejected marks answers that reconnecting will not change.
+var errStreamRejected = errors.New("event stream rejected")
+
+func watch(cmd *cobra.Command, args []string) {
+	endpoint, _ := cmd.Flags().GetString("endpoint")
+	prefix, _ := cmd.Flags().GetString("prefix")
+	suffix, _ := cmd.Flags().GetString("suffix")
+	names, _ := cmd.Flags().GetStringSlice("events")
+	accessKey, secretKey, err := serverCredentials(cmd)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	query := url.Values{"events": names}
+	if len(names) == 0 {
+		query.Set("events", "")
+	}
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+	if suffix != "" {
+		query.Set("suffix", suffix)
+	}
+	streamURL := strings.TrimSuffix(endpoint, "/") + "/" + url.PathEscape(args[0]) + "?" + query.Encode()
+
+	// Tail the stream like tail -f, reconnecting when the connection drops
+	for {
+		err := tailEvents(streamURL, accessKey, secretKey, os.Getenv("AWS_SESSION_TOKEN"))
+		if errors.Is(err, errStreamRejected) {
+			fmt.Println(err)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Event stream lost (%v), reconnecting...\n", err)
+		time.Sleep(watchRetryDelay)
+	}
+}
+
+// tailEvents prints the events of one connection to the event stream until
+// it ends.
+func tailEvents(streamURL, accessKey, secretKey, sessionToken string) error {
+	req, err := http.NewRequest(http.MethodGet, streamURL, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errStreamRejected, err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	aws.SignRequest(req, aws.Region, accessKey, secretKey, sessionToken, time.Now())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%w: %s\n%s", errStreamRejected, resp.Status, body)
+	}
+
+	var event, data string
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			printEvent(event, data)
+			event, data = "", ""
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.EOF
+}
+
+// printEvent prints a Server-Sent Event from the stream as one line.
+func printEvent(event, data string) {
+	if event == "" || data == "" {
+		return
+	}
+	if event == "dropped" {
+		fmt.Fprintln(os.Stderr, "Missed events because the watcher fell behind:", data)
+		return
+	}
+
+	var msg types.EventMessage
+	if err := json.Unmarshal([]byte(data), &msg); err != nil {
+		fmt.Fprintln(os.Stderr, "Unreadable event:", err)
+		return
+	}
+	for _, record := range msg.Records {
+		key, err := url.QueryUnescape(record.S3.Object.Key)
+		if err != nil {
+			key = record.S3.Object.Key
+		}
+		line := fmt.Sprintf("%s  %-40s %s/%s", record.EventTime, record.EventName, record.S3.Bucket.Name, key)
+		if strings.HasPrefix(record.EventName, "ObjectCreated:") {
+			line += fmt.Sprintf(" (%d bytes)", record.S3.Object.Size)
+		}
+		fmt.Println(line)
+	}
+}
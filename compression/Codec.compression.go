@@ -0,0 +1,102 @@
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/aidenappl/openbucket-go/types"
+	"github.com/klauspost/compress/zstd"
+)
+
+// codec compresses and decompresses single frames.
+type codec interface {
+	encode(dst, src []byte) ([]byte, error)
+	// decode appends the content of src, which must be size bytes, to dst.
+	decode(dst, src []byte, size int) ([]byte, error)
+}
+
+func codecFor(algorithm string) (codec, error) {
+	switch algorithm {
+	case types.CompressionZstd:
+		return zstdCodec{}, nil
+	case types.CompressionGzip:
+		return gzipCodec{}, nil
+	}
+	return nil, fmt.Errorf("unsupported compression algorithm %q", algorithm)
+}
+
+// The zstd encoder and decoder are safe for concurrent EncodeAll and
+// DecodeAll calls, so one of each is shared.
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+	zstdErr     error
+)
+
+type zstdCodec struct{}
+
+func (zstdCodec) init() error {
+	zstdOnce.Do(func() {
+		if zstdEncoder, zstdErr = zstd.NewWriter(nil); zstdErr != nil {
+			return
+		}
+		// Frames never hold more than a chunk, so anything larger is corrupt
+		zstdDecoder, zstdErr = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0), zstd.WithDecoderMaxMemory(2*ChunkSize))
+	})
+	return zstdErr
+}
+
+func (z zstdCodec) encode(dst, src []byte) ([]byte, error) {
+	if err := z.init(); err != nil {
+		return nil, err
+	}
+	return zstdEncoder.EncodeAll(src, dst), nil
+}
+
+func (z zstdCodec) decode(dst, src []byte, size int) ([]byte, error) {
+	if err := z.init(); err != nil {
+		return nil, err
+	}
+	out, err := zstdDecoder.DecodeAll(src, dst)
+	if err != nil {
+		return nil, err
+	}
+	if len(out)-len(dst) != size {
+		return nil, fmt.Errorf("frame holds %d bytes, expected %d", len(out)-len(dst), size)
+	}
+	return out, nil
+}
+
+type gzipCodec struct{}
+
+func (gzipCodec) encode(dst, src []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	w := gzip.NewWriter(buf)
+	if _, err := w.Write(src); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gzipCodec) decode(dst, src []byte, size int) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	out := append(dst, make([]byte, size)...)
+	if _, err := io.ReadFull(r, out[len(dst):]); err != nil {
+		return nil, err
+	}
+	// The frame must end where its content does
+	if n, _ := r.Read(make([]byte, 1)); n != 0 {
+		return nil, fmt.Errorf("frame holds more than %d bytes", size)
+	}
+	return out, nil
+}
@@ -0,0 +1,279 @@
+package compression
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// ChunkSize is the amount of content compressed per frame. Each frame starts
+// with a 4-byte header holding its length, with the top bit set for frames
+// stored as is because they did not shrink. The frames are followed by a
+// table of their offsets, 8 bytes each, so any frame can be found directly.
+const ChunkSize = 256 * 1024
+
+const (
+	headerSize = 4
+	storedFlag = 1 << 31
+	entrySize  = 8
+)
+
+// ErrCorrupt is returned when compressed content cannot be decoded.
+var ErrCorrupt = errors.New("compressed object is corrupt")
+
+// Compress returns a reader of src compressed with algorithm, and the
+// compression metadata of the result, whose Size the caller records once the
+// reader is drained. Content that does not compress well is returned
+// unchanged with nil metadata; this is judged from its first chunk, so
+// already compressed formats are stored without the framing overhead.
+func Compress(algorithm string, src io.Reader) (io.Reader, *types.ObjectCompression, error) {
+	c, err := codecFor(algorithm)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	first := make([]byte, ChunkSize)
+	n, err := io.ReadFull(src, first)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, nil, err
+	}
+	first = first[:n]
+	rest := io.MultiReader(bytes.NewReader(first), src)
+
+	frame, err := encodeFrame(c, nil, first)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Keep the content as is unless it saves at least an eighth
+	if n == 0 || len(frame) > n-n/8 {
+		return rest, nil, nil
+	}
+
+	r := &frameReader{
+		codec:   c,
+		src:     src,
+		buf:     make([]byte, ChunkSize),
+		pending: frame,
+		written: int64(len(frame)),
+		table:   binary.BigEndian.AppendUint64(nil, 0),
+	}
+	if n < ChunkSize {
+		r.done = true
+	}
+	return r, &types.ObjectCompression{Algorithm: algorithm, ChunkSize: ChunkSize}, nil
+}
+
+// encodeFrame appends chunk to dst as a frame.
+func encodeFrame(c codec, dst, chunk []byte) ([]byte, error) {
+	start := len(dst)
+	dst = append(dst, make([]byte, headerSize)...)
+	out, err := c.encode(dst, chunk)
+	if err != nil {
+		return nil, err
+	}
+
+	length := len(out) - start - headerSize
+	if length >= len(chunk) {
+		out = append(out[:start+headerSize], chunk...)
+		length = len(chunk) | storedFlag
+	}
+	binary.BigEndian.PutUint32(out[start:], uint32(length))
+	return out, nil
+}
+
+// frameReader compresses its source chunk by chunk as it is read, and ends
+// with the offset table of the frames.
+type frameReader struct {
+	codec   codec
+	src     io.Reader
+	buf     []byte
+	pending []byte
+	done    bool
+	written int64  // length of the frames so far
+	table   []byte // offsets of the frames so far, nil once sent
+}
+
+func (r *frameReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.done {
+			if r.table == nil {
+				return 0, io.EOF
+			}
+			r.pending, r.table = r.table, nil
+			continue
+		}
+		n, err := io.ReadFull(r.src, r.buf)
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			r.done = true
+		} else if err != nil {
+			return 0, err
+		}
+		if n == 0 {
+			continue
+		}
+		if r.pending, err = encodeFrame(r.codec, r.pending[:0], r.buf[:n]); err != nil {
+			return 0, err
+		}
+		r.table = binary.BigEndian.AppendUint64(r.table, uint64(r.written))
+		r.written += int64(len(r.pending))
+	}
+
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// Reader decompresses an object stored by Compress. It supports seeking, so
+// ranged reads only decompress the frames they touch.
+type Reader struct {
+	codec     codec
+	src       io.ReadSeeker
+	size      int64
+	chunkSize int64
+	offset    int64
+	table     int64 // offset in src of the frame offset table
+
+	frame  int64 // index of the frame held in plain, or -1
+	next   int64 // offset in src of the frame after it
+	plain  []byte
+	packed []byte
+	err    error
+}
+
+// Decompress returns a Reader for an object of size bytes stored compressed in src.
+func Decompress(comp *types.ObjectCompression, src io.ReadSeeker, size int64) (*Reader, error) {
+	c, err := codecFor(comp.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if comp.ChunkSize <= 0 {
+		return nil, fmt.Errorf("invalid compression chunk size %d", comp.ChunkSize)
+	}
+	chunkSize := int64(comp.ChunkSize)
+	table := comp.Size - (size+chunkSize-1)/chunkSize*entrySize
+	if size < 0 || table < 0 {
+		return nil, fmt.Errorf("%w: %d bytes cannot hold %d bytes of content", ErrCorrupt, comp.Size, size)
+	}
+	return &Reader{
+		codec:     c,
+		src:       src,
+		size:      size,
+		chunkSize: chunkSize,
+		table:     table,
+		frame:     -1,
+	}, nil
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+
+	n := r.offset / r.chunkSize
+	if n != r.frame {
+		if err := r.load(n); err != nil {
+			r.err = err
+			return 0, err
+		}
+	}
+
+	copied := copy(p, r.plain[r.offset-n*r.chunkSize:])
+	r.offset += int64(copied)
+	return copied, nil
+}
+
+// Err returns the error that ended the last failed read, if any.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// load reads and decodes frame n. Frames vary in length, so unless it
+// follows the frame held already, its offset is read from the offset table.
+func (r *Reader) load(n int64) error {
+	start := r.next
+	if n != r.frame+1 {
+		var err error
+		if start, err = r.frameOffset(n); err != nil {
+			return err
+		}
+	}
+
+	length, stored, err := r.header(n, start)
+	if err != nil {
+		return err
+	}
+	if cap(r.packed) < int(length) {
+		r.packed = make([]byte, length)
+	}
+	packed := r.packed[:length]
+	if _, err := io.ReadFull(r.src, packed); err != nil {
+		return fmt.Errorf("%w: frame %d is truncated", ErrCorrupt, n)
+	}
+
+	size := int(min(r.chunkSize, r.size-n*r.chunkSize))
+	if stored {
+		if len(packed) != size {
+			return fmt.Errorf("%w: frame %d holds %d bytes, expected %d", ErrCorrupt, n, len(packed), size)
+		}
+		r.plain = append(r.plain[:0], packed...)
+	} else if r.plain, err = r.codec.decode(r.plain[:0], packed, size); err != nil {
+		return fmt.Errorf("%w: frame %d: %v", ErrCorrupt, n, err)
+	}
+	r.frame, r.next = n, start+headerSize+length
+	return nil
+}
+
+// frameOffset reads the offset of frame n from the offset table.
+func (r *Reader) frameOffset(n int64) (int64, error) {
+	if _, err := r.src.Seek(r.table+n*entrySize, io.SeekStart); err != nil {
+		return 0, err
+	}
+	var buf [entrySize]byte
+	if _, err := io.ReadFull(r.src, buf[:]); err != nil {
+		return 0, fmt.Errorf("%w: the offset of frame %d is missing", ErrCorrupt, n)
+	}
+	return int64(binary.BigEndian.Uint64(buf[:])), nil
+}
+
+// header seeks to frame n at start and reads its header, leaving src at its
+// content.
+func (r *Reader) header(n, start int64) (int64, bool, error) {
+	if start < 0 || start+headerSize > r.table {
+		return 0, false, fmt.Errorf("%w: frame %d is out of bounds", ErrCorrupt, n)
+	}
+	if _, err := r.src.Seek(start, io.SeekStart); err != nil {
+		return 0, false, err
+	}
+	var buf [headerSize]byte
+	if _, err := io.ReadFull(r.src, buf[:]); err != nil {
+		return 0, false, fmt.Errorf("%w: frame %d is missing", ErrCorrupt, n)
+	}
+	v := binary.BigEndian.Uint32(buf[:])
+	length := int64(v &^ storedFlag)
+	// Frames never grow past a stored chunk by more than the codec's overhead
+	if length > 2*r.chunkSize || start+headerSize+length > r.table {
+		return 0, false, fmt.Errorf("%w: frame %d has length %d", ErrCorrupt, n, length)
+	}
+	return length, v&storedFlag != 0, nil
+}
+
+func (r *Reader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, fmt.Errorf("invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative position %d", offset)
+	}
+	r.offset = offset
+	return offset, nil
+}
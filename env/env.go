@@ -6,8 +6,22 @@ import (
 )
 
 var (
-	Port              = getEnv("PORT", "8080")
-	BypassPermissions = getEnv("BYPASS_PERMISSIONS", "false") == "true"
+	Port                    = getEnv("PORT", "8080")
+	BypassPermissions       = getEnv("BYPASS_PERMISSIONS", "false") == "true"
+	TrustedProxies          = getEnv("TRUSTED_PROXIES", "")
+	STSKeyFile              = getEnv("STS_KEY_FILE", "sts.key")
+	OIDCConfigFile          = getEnv("OIDC_CONFIG_FILE", "oidc.xml")
+	SigV2Enabled            = getEnv("SIGV2_ENABLED", "false") == "true"
+	LifecycleInterval       = getEnv("LIFECYCLE_INTERVAL", "1h")
+	SSEKeyFile              = getEnv("SSE_KEY_FILE", "sse.keys")
+	StorageMode             = getEnv("STORAGE_MODE", "files")
+	MetadataIndexFile       = getEnv("METADATA_INDEX_FILE", "metadata.db")
+	MetadataSidecars        = getEnv("METADATA_SIDECARS", "true") == "true"
+	NotificationTargetsFile = getEnv("NOTIFICATION_TARGETS_FILE", "notifications.xml")
+	NotificationQueueDir    = getEnv("NOTIFICATION_QUEUE_DIR", "notifications")
+	ReplicationQueueDir     = getEnv("REPLICATION_QUEUE_DIR", "replication-queue")
+	WebsitePort             = getEnv("WEBSITE_PORT", "")
+	WebsiteDomain           = getEnv("WEBSITE_DOMAIN", "")
 )
 
 func getEnv(key string, fallback string) string {
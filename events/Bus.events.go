@@ -0,0 +1,90 @@
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// subscriberBuffer is how many events a subscriber may fall behind before
+// further events are dropped for it. Publishing never waits for a subscriber.
+const subscriberBuffer = 256
+
+// Subscription receives the events of one bucket published after it was
+// made, limited to the subscribed event names and the key prefix and suffix.
+type Subscription struct {
+	Events <-chan types.EventRecord
+
+	ch      chan types.EventRecord
+	bucket  string
+	names   []string
+	filter  *types.NotificationFilter
+	dropped atomic.Int64
+}
+
+var (
+	busMu       sync.RWMutex
+	subscribers = map[*Subscription]struct{}{}
+)
+
+// Subscribe starts receiving the events of bucket that match one of names,
+// e.g. "s3:ObjectCreated:*", and whose keys have the prefix and suffix. The
+// subscription must be closed when it is no longer read.
+func Subscribe(bucket, prefix, suffix string, names []string) *Subscription {
+	filter := &types.NotificationFilter{}
+	filter.Key.Rules = []types.FilterRule{{Name: "prefix", Value: prefix}, {Name: "suffix", Value: suffix}}
+
+	ch := make(chan types.EventRecord, subscriberBuffer)
+	s := &Subscription{Events: ch, ch: ch, bucket: bucket, names: names, filter: filter}
+
+	busMu.Lock()
+	subscribers[s] = struct{}{}
+	busMu.Unlock()
+	return s
+}
+
+// Close stops the subscription and closes its Events channel.
+func (s *Subscription) Close() {
+	busMu.Lock()
+	defer busMu.Unlock()
+	if _, ok := subscribers[s]; ok {
+		delete(subscribers, s)
+		close(s.ch)
+	}
+}
+
+// TakeDropped returns the number of events dropped because the subscriber
+// fell behind since the last call.
+func (s *Subscription) TakeDropped() int64 {
+	return s.dropped.Swap(0)
+}
+
+// listening reports whether anyone is subscribed to the events of bucket.
+func listening(bucket string) bool {
+	busMu.RLock()
+	defer busMu.RUnlock()
+	for s := range subscribers {
+		if s.bucket == bucket {
+			return true
+		}
+	}
+	return false
+}
+
+// publish hands an event about key to every matching subscriber. Subscribers
+// whose buffer is full miss the event and have it counted as dropped.
+func publish(bucket, key string, record types.EventRecord) {
+	busMu.RLock()
+	defer busMu.RUnlock()
+	for s := range subscribers {
+		if s.bucket != bucket || !subscribed(s.names, record.EventName) || !matchesFilter(s.filter, key) {
+			continue
+		}
+		select {
+		case s.ch <- record:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package events
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/auth"
+	"github.com/aidenappl/openbucket-go/bucketconfig"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// Event names, as they appear in event records. Configurations subscribe to
+// them with an "s3:" prefix, or to a whole family with "s3:ObjectCreated:*".
+const (
+	ObjectCreatedPut      = "ObjectCreated:Put"
+	ObjectCreatedPost     = "ObjectCreated:Post"
+	ObjectCreatedCopy     = "ObjectCreated:Copy"
+	ObjectCreatedComplete = "ObjectCreated:CompleteMultipartUpload"
+	ObjectRemovedDelete   = "ObjectRemoved:Delete"
+)
+
+// Source describes the request that caused an event.
+type Source struct {
+	RequestID string
+	HostID    string
+	Principal string // access key of the caller, empty for anonymous requests
+	SourceIP  string
+}
+
+// Emit publishes the named event about key to the bucket's live subscribers
+// and queues it for every notification configuration of the bucket that
+// subscribes to it and whose filter matches the key. md is the object that
+// was written or removed, if known. Failures are logged and never fail the
+// request that caused the event.
+func Emit(name, bucket, key string, md *types.ObjectMetadata, src Source) {
+	now := time.Now().UTC()
+	var record *types.EventRecord
+	build := func() types.EventRecord {
+		if record == nil {
+			record = newRecord(name, bucket, key, md, src, now)
+		}
+		return *record
+	}
+
+	if listening(bucket) {
+		publish(bucket, key, build())
+	}
+
+	cfg, err := bucketconfig.LoadNotification(bucket)
+	if err != nil {
+		log.Println("Error loading notification configuration for bucket", bucket+":", err)
+		return
+	}
+	if cfg == nil {
+		return
+	}
+
+	for _, t := range cfg.Targets() {
+		if !subscribed(t.Events, name) || !matchesFilter(t.Filter, key) {
+			continue
+		}
+
+		r := build()
+		r.S3.ConfigurationID = t.Id
+		if err := deliveries.Push(t.ARN(), types.EventMessage{Records: []types.EventRecord{r}}); err != nil {
+			log.Println("Error queueing notification for", bucket+"/"+key+":", err)
+		}
+	}
+}
+
+// subscribed reports whether any of the configured events covers name.
+func subscribed(events []string, name string) bool {
+	for _, event := range events {
+		event = strings.TrimPrefix(event, "s3:")
+		if event == name || (strings.HasSuffix(event, ":*") && strings.HasPrefix(name, strings.TrimSuffix(event, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesFilter reports whether key satisfies the prefix and suffix rules.
+func matchesFilter(filter *types.NotificationFilter, key string) bool {
+	if filter == nil {
+		return true
+	}
+	for _, rule := range filter.Key.Rules {
+		switch strings.ToLower(rule.Name) {
+		case "prefix":
+			if !strings.HasPrefix(key, rule.Value) {
+				return false
+			}
+		case "suffix":
+			if !strings.HasSuffix(key, rule.Value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// newRecord builds the S3 event record of an event.
+func newRecord(name, bucket, key string, md *types.ObjectMetadata, src Source, now time.Time) *types.EventRecord {
+	principal := src.Principal
+	if principal == "" {
+		principal = "Anonymous"
+	}
+	var owner string
+	if perms, err := auth.LoadBucketPermissions(bucket); err == nil {
+		owner = perms.Owner.ID
+	}
+
+	object := types.EventObject{
+		Key:       encodeKey(key),
+		Sequencer: fmt.Sprintf("%016X", now.UnixNano()),
+	}
+	if md != nil {
+		object.VersionID = md.VersionId
+		if strings.HasPrefix(name, "ObjectCreated:") {
+			object.Size = md.Size
+			object.ETag = strings.Trim(md.ETag, `"`)
+		}
+	}
+
+	return &types.EventRecord{
+		EventVersion:      "2.1",
+		EventSource:       "aws:s3",
+		AwsRegion:         "us-east-1",
+		EventTime:         now.Format("2006-01-02T15:04:05.000Z"),
+		EventName:         name,
+		UserIdentity:      types.EventIdentity{PrincipalID: principal},
+		RequestParameters: map[string]string{"sourceIPAddress": src.SourceIP},
+		ResponseElements: map[string]string{
+			"x-amz-request-id": src.RequestID,
+			"x-amz-id-2":       src.HostID,
+		},
+		S3: types.EventS3{
+			SchemaVersion: "1.0",
+			Bucket: types.EventBucket{
+				Name:          bucket,
+				OwnerIdentity: types.EventIdentity{PrincipalID: owner},
+				ARN:           "arn:aws:s3:::" + bucket,
+			},
+			Object: object,
+		},
+	}
+}
+
+// encodeKey URL-encodes a key the way event records carry it: spaces become
+// '+' and slashes are kept.
+func encodeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.QueryEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
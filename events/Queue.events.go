@@ -0,0 +1,82 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/env"
+	"github.com/aidenappl/openbucket-go/queue"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+const (
+	// deliveryTimeout bounds a single webhook request.
+	deliveryTimeout = 10 * time.Second
+	// maxEventAge is how long an event is retried before it is dropped.
+	maxEventAge = 24 * time.Hour
+)
+
+var (
+	// deliveries holds the event messages waiting for their webhook, grouped
+	// by target ARN.
+	deliveries = queue.New("notification", env.NotificationQueueDir, maxEventAge, deliverEntry)
+	httpClient = &http.Client{Timeout: deliveryTimeout}
+)
+
+// Start delivers queued events in the background, starting with those left
+// over from before a restart.
+func Start() {
+	deliveries.Start()
+}
+
+// deliverEntry sends a queued event message to the target it was queued for.
+func deliverEntry(e *queue.Entry) error {
+	var msg types.EventMessage
+	if err := json.Unmarshal(e.Payload, &msg); err != nil {
+		return queue.Permanent(err)
+	}
+
+	targets, err := LoadTargets()
+	if err != nil {
+		return err
+	}
+	return deliver(FindTarget(targets, e.Group), e.Group, msg)
+}
+
+// deliver POSTs an event message to the target. Any 2xx answer counts as
+// delivered.
+func deliver(target *types.WebhookTarget, arn string, msg types.EventMessage) error {
+	if target == nil {
+		return fmt.Errorf("no notification target %s", arn)
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("error encoding event: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, target.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if target.AuthToken != "" {
+		req.Header.Set("Authorization", "Bearer "+target.AuthToken)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s answered %s", target.Endpoint, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package events
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/aidenappl/openbucket-go/env"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// LoadTargets reads the webhook targets from env.NotificationTargetsFile. A
+// missing file means there are no targets.
+func LoadTargets() (*types.NotificationTargets, error) {
+	data, err := os.ReadFile(env.NotificationTargetsFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return &types.NotificationTargets{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read notification targets: %v", err)
+	}
+
+	var targets types.NotificationTargets
+	if err := xml.Unmarshal(data, &targets); err != nil {
+		return nil, fmt.Errorf("failed to decode notification targets: %v", err)
+	}
+	seen := map[string]bool{}
+	for _, t := range targets.Webhooks {
+		if t.ARN == "" || seen[t.ARN] {
+			return nil, fmt.Errorf("notification target %q needs a unique ARN", t.ARN)
+		}
+		seen[t.ARN] = true
+		u, err := url.Parse(t.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("notification target %q needs an http or https endpoint", t.ARN)
+		}
+	}
+	return &targets, nil
+}
+
+// FindTarget returns the webhook with the given ARN, or nil if there is none.
+func FindTarget(targets *types.NotificationTargets, arn string) *types.WebhookTarget {
+	for i := range targets.Webhooks {
+		if targets.Webhooks[i].ARN == arn {
+			return &targets.Webhooks[i]
+		}
+	}
+	return nil
+}
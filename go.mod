@@ -5,7 +5,9 @@ go 1.23.4
 require (
 	github.com/google/uuid v1.6.0
 	github.com/gorilla/mux v1.8.1
+	github.com/klauspost/compress v1.18.0
 	github.com/spf13/cobra v1.9.1
+	go.etcd.io/bbolt v1.3.11
 )
 
 require (
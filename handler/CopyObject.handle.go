@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aidenappl/openbucket-go/chunkstore"
+	"github.com/aidenappl/openbucket-go/metadata"
+	"github.com/aidenappl/openbucket-go/sse"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// CopyObject copies srcBucket/srcKey to bucket/key. The source is decrypted
+// with srcCustomerKey when it uses SSE-C, and the copy is written like
+// PutObject, so it is encrypted and locked according to opts and the
+// destination bucket. Tags are copied with the content unless
+// opts.ReplaceTags is set.
+//
+// In dedup storage mode a deduplicated source that is copied unencrypted only
+// gains another reference to its content, and the copy keeps its ETag.
+func CopyObject(srcBucket, srcKey string, srcCustomerKey []byte, bucket, key string, owner types.UserObject, grants []types.Grant, opts PutOptions) (*types.ObjectMetadata, error) {
+	// The source is locked as well, so it is not replaced or deleted while
+	// its content is read
+	defer lockObjects(srcBucket, srcKey, bucket, key)()
+
+	srcMD, err := metadata.Load(srcBucket, srcKey)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s/%s", ErrNoSuchKey, srcBucket, srcKey)
+	} else if err != nil {
+		return nil, err
+	}
+	if err := sse.CheckCustomerKey(srcMD.Encryption, srcCustomerKey); err != nil {
+		return nil, err
+	}
+
+	if !opts.ReplaceTags {
+		opts.Tags = srcMD.Tags
+	}
+
+	return putObject(bucket, key, owner, grants, opts, func(filePath, compress string, enc *types.ObjectEncryption, customerKey []byte) (*storedFile, error) {
+		if enc == nil && srcMD.ContentHash != "" && Deduplicate() {
+			chunk, err := chunkstore.Acquire(srcMD.ContentHash)
+			if err != nil {
+				return nil, err
+			}
+			stored, err := placeChunk(filePath, chunk)
+			if err != nil {
+				return nil, err
+			}
+			stored.etag = srcMD.ETag
+			return stored, nil
+		}
+
+		src, err := OpenContent(srcBucket, srcKey, srcMD, srcCustomerKey)
+		if err != nil {
+			return nil, err
+		}
+		defer src.Close()
+		return storeContent(filePath, src, compress, enc, customerKey)
+	})
+}
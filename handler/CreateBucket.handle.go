@@ -9,34 +9,45 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/aidenappl/openbucket-go/auth"
+	"github.com/aidenappl/openbucket-go/tools"
 	"github.com/aidenappl/openbucket-go/types"
 )
 
+// Errors returned by CreateBucket.
+var (
+	ErrInvalidBucketName       = errors.New("invalid bucket name")
+	ErrBucketAlreadyExists     = errors.New("bucket already exists")
+	ErrBucketAlreadyOwnedByYou = errors.New("bucket already owned by you")
+)
+
+// CreateBucket creates a bucket owned by owner. The bucket record, its
+// .obpermissions file, is created exclusively first, so concurrent requests
+// cannot both create the same bucket. Creating an existing bucket fails with
+// ErrBucketAlreadyOwnedByYou when owner already owns it, and with
+// ErrBucketAlreadyExists otherwise.
 func CreateBucket(bucket string, owner types.UserObject) error {
+	if !tools.ValidBucketName(bucket) {
+		return fmt.Errorf("%w: %q", ErrInvalidBucketName, bucket)
+	}
 
 	filePath := filepath.Join("buckets", bucket)
+	if err := os.MkdirAll("buckets", os.ModePerm); err != nil {
+		return fmt.Errorf("create buckets directory: %w", err)
+	}
 
-	// Does it already exist?
-	if fi, err := os.Stat(filePath); err == nil {
-		if !fi.IsDir() {
-			return fmt.Errorf("%s exists but is not a directory", filePath)
+	permissionsFile, err := os.OpenFile(filePath+".obpermissions", os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, os.ErrExist) {
+		existing, err := auth.LoadBucketPermissions(bucket)
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrBucketAlreadyExists, bucket)
 		}
 		log.Println("Bucket already exists:", bucket)
-		return fmt.Errorf("bucket already exists: %s", bucket)
-	} else if !errors.Is(err, os.ErrNotExist) {
-		// real error
-		return fmt.Errorf("stat %s: %w", filePath, err)
-	}
-
-	// Parent(s) + bucket
-	if err := os.MkdirAll(filePath, os.ModePerm); err != nil {
-		return fmt.Errorf("create bucket %s: %w", bucket, err)
-	}
-
-	log.Println("Created bucket:", bucket)
-
-	permissionsFile, err := os.Create(filePath + ".obpermissions")
-	if err != nil {
+		if existing.Owner.ID == owner.ID {
+			return fmt.Errorf("%w: %s", ErrBucketAlreadyOwnedByYou, bucket)
+		}
+		return fmt.Errorf("%w: %s", ErrBucketAlreadyExists, bucket)
+	} else if err != nil {
 		log.Println("Error creating permissions file:", err)
 		return fmt.Errorf("error creating permissions file: %v", err)
 	}
@@ -54,14 +65,23 @@ func CreateBucket(bucket string, owner types.UserObject) error {
 	permissionsXML, err := xml.MarshalIndent(permissions, "", "  ")
 	if err != nil {
 		log.Println("Error marshalling permissions to XML:", err)
+		os.Remove(filePath + ".obpermissions")
 		return fmt.Errorf("error marshalling permissions to XML: %v", err)
 	}
 
 	_, err = permissionsFile.WriteString(string(permissionsXML))
 	if err != nil {
 		log.Println("Error writing to permissions file:", err)
+		os.Remove(filePath + ".obpermissions")
 		return fmt.Errorf("error writing to permissions file: %v", err)
 	}
 
+	// A directory left behind by a bucket without a record is adopted
+	if err := os.MkdirAll(filePath, os.ModePerm); err != nil {
+		os.Remove(filePath + ".obpermissions")
+		return fmt.Errorf("create bucket %s: %w", bucket, err)
+	}
+
+	log.Println("Created bucket:", bucket)
 	return nil
 }
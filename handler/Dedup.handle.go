@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"strings"
+
+	"github.com/aidenappl/openbucket-go/chunkstore"
+	"github.com/aidenappl/openbucket-go/env"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// Storage modes selected with STORAGE_MODE. In dedup mode object content is
+// stored once per distinct content in the chunk store, and objects point at
+// it by hash.
+const (
+	StorageFiles = "files"
+	StorageDedup = "dedup"
+)
+
+// Deduplicate reports whether new unencrypted objects go to the chunk store.
+// Encrypted objects never do, as each is sealed with its own data key.
+func Deduplicate() bool {
+	return env.StorageMode == StorageDedup
+}
+
+// writeChunk adds body to the chunk store, compressed with the compress
+// algorithm unless the store already holds the same content, and references
+// it from filePath.
+func writeChunk(filePath string, body io.Reader, compress string) (*storedFile, error) {
+	hash := sha256.New()
+	staged := chunkstore.StagingPath()
+	stored, err := writeFile(staged, io.TeeReader(body, hash), compress, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	chunk, err := chunkstore.Add(staged, types.ContentChunk{
+		Hash:        hex.EncodeToString(hash.Sum(nil)),
+		Size:        stored.size,
+		StoredSize:  stored.storedSize,
+		Compression: stored.compression,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return placeChunk(filePath, chunk)
+}
+
+// placeChunk writes an empty placeholder at filePath for an object whose
+// content is chunk, so it is listed like any other object. The caller's
+// reference to chunk is dropped when that fails.
+func placeChunk(filePath string, chunk *types.ContentChunk) (*storedFile, error) {
+	if _, err := writeFile(filePath, strings.NewReader(""), "", nil, nil); err != nil {
+		chunkstore.Release(chunk.Hash)
+		return nil, err
+	}
+	return &storedFile{
+		size:        chunk.Size,
+		compression: chunk.Compression,
+		contentHash: chunk.Hash,
+	}, nil
+}
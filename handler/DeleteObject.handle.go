@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/chunkstore"
+	"github.com/aidenappl/openbucket-go/metadata"
+	"github.com/aidenappl/openbucket-go/tools"
+)
+
+// ErrNoSuchKey is returned when deleting an object that does not exist.
+var ErrNoSuchKey = errors.New("no such key")
+
+// DeleteObject removes an object together with its metadata and releases its
+// deduplicated content, if any. Objects under a legal hold or unexpired
+// retention are refused with ErrObjectLocked; bypassGovernance lifts
+// GOVERNANCE retention.
+func DeleteObject(bucket, key string, bypassGovernance bool) error {
+	defer LockObject(bucket, key)()
+
+	filePath := tools.ObjectPath(bucket, key)
+	if _, err := os.Stat(filePath); errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s/%s", ErrNoSuchKey, bucket, key)
+	}
+
+	md, err := metadata.Load(bucket, key)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to load object metadata: %w", err)
+	}
+	if err := CheckObjectLock(md, bypassGovernance, time.Now()); err != nil {
+		return err
+	}
+
+	if err := os.Remove(filePath); err != nil {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	if err := metadata.Delete(bucket, key); err != nil {
+		return fmt.Errorf("failed to delete object metadata: %w", err)
+	}
+	if md != nil && md.ContentHash != "" {
+		if err := chunkstore.Release(md.ContentHash); err != nil {
+			return fmt.Errorf("failed to release object content: %w", err)
+		}
+	}
+	return nil
+}
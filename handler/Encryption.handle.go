@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aidenappl/openbucket-go/metadata"
+	"github.com/aidenappl/openbucket-go/sse"
+)
+
+// KeyRotation summarises a key-encryption key rotation.
+type KeyRotation struct {
+	KeyID     string
+	Rewrapped int
+	Retired   []string
+}
+
+// RotateEncryptionKey makes a new key-encryption key active and re-wraps the
+// data key of every encrypted object and upload part with it. Object data is not rewritten.
+// Old keys are removed from the keyring once no object refers to them; if
+// any object fails to re-wrap, every old key is kept.
+func RotateEncryptionKey() (*KeyRotation, error) {
+	keyID, err := sse.Rotate()
+	if err != nil {
+		return nil, err
+	}
+	rotation := &KeyRotation{KeyID: keyID}
+
+	buckets, err := ListBuckets()
+	if err != nil {
+		return rotation, err
+	}
+	for _, bucket := range *buckets {
+		objects, err := ListObjects(bucket.Name)
+		if err != nil {
+			return rotation, fmt.Errorf("bucket %s: %w", bucket.Name, err)
+		}
+		for _, object := range objects {
+			if strings.HasSuffix(object.Key, "/") {
+				continue
+			}
+			changed, err := rewrapObject(bucket.Name, object.Key)
+			if err != nil {
+				return rotation, fmt.Errorf("%s/%s: %w", bucket.Name, object.Key, err)
+			}
+			if changed {
+				rotation.Rewrapped++
+			}
+		}
+
+		rewrapped, err := rewrapParts(bucket.Name)
+		rotation.Rewrapped += rewrapped
+		if err != nil {
+			return rotation, fmt.Errorf("bucket %s: %w", bucket.Name, err)
+		}
+	}
+
+	rotation.Retired, err = sse.Retire(map[string]bool{keyID: true})
+	return rotation, err
+}
+
+// rewrapObject re-wraps the data key of an encrypted object with the active
+// key-encryption key and reports whether it changed.
+func rewrapObject(bucket, key string) (bool, error) {
+	defer LockObject(bucket, key)()
+	md, err := metadata.Load(bucket, key)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	if md.Encryption == nil {
+		return false, nil
+	}
+
+	changed, err := sse.Rewrap(md.Encryption)
+	if err != nil || !changed {
+		return false, err
+	}
+	if err := metadata.Save(md); err != nil {
+		return false, err
+	}
+	return true, nil
+}
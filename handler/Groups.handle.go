@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/auth"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// ListGroups returns all groups.
+func ListGroups() ([]types.Group, error) {
+	identities, err := auth.LoadIdentities()
+	if err != nil {
+		return nil, err
+	}
+	return identities.Groups, nil
+}
+
+// CreateGroup adds a new, empty group.
+func CreateGroup(name string) (*types.Group, error) {
+	if err := validateIdentityName("group", name); err != nil {
+		return nil, err
+	}
+
+	identitiesMu.Lock()
+	defer identitiesMu.Unlock()
+
+	identities, err := auth.LoadIdentities()
+	if err != nil {
+		return nil, err
+	}
+	if auth.FindGroup(identities, name) != nil {
+		return nil, fmt.Errorf("%w: group %s", ErrEntityAlreadyExists, name)
+	}
+
+	group := types.Group{Name: name, DateCreated: time.Now()}
+	identities.Groups = append(identities.Groups, group)
+	if err := auth.SaveIdentities(identities); err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// DeleteGroup removes a group and its memberships.
+func DeleteGroup(name string) error {
+	identitiesMu.Lock()
+	defer identitiesMu.Unlock()
+
+	identities, err := auth.LoadIdentities()
+	if err != nil {
+		return err
+	}
+	if auth.FindGroup(identities, name) == nil {
+		return fmt.Errorf("%w: group %s", ErrNoSuchEntity, name)
+	}
+
+	identities.Groups = slices.DeleteFunc(identities.Groups, func(g types.Group) bool { return g.Name == name })
+	for i := range identities.Users {
+		identities.Users[i].Groups = slices.DeleteFunc(identities.Users[i].Groups, func(g string) bool { return g == name })
+	}
+	return auth.SaveIdentities(identities)
+}
+
+// AddUserToGroup makes the user a member of the group.
+func AddUserToGroup(userName, groupName string) error {
+	identitiesMu.Lock()
+	defer identitiesMu.Unlock()
+
+	identities, err := auth.LoadIdentities()
+	if err != nil {
+		return err
+	}
+	user := auth.FindUser(identities, userName)
+	if user == nil {
+		return fmt.Errorf("%w: user %s", ErrNoSuchEntity, userName)
+	}
+	if auth.FindGroup(identities, groupName) == nil {
+		return fmt.Errorf("%w: group %s", ErrNoSuchEntity, groupName)
+	}
+	if slices.Contains(user.Groups, groupName) {
+		return nil
+	}
+
+	user.Groups = append(user.Groups, groupName)
+	return auth.SaveIdentities(identities)
+}
+
+// RemoveUserFromGroup removes the user from the group.
+func RemoveUserFromGroup(userName, groupName string) error {
+	identitiesMu.Lock()
+	defer identitiesMu.Unlock()
+
+	identities, err := auth.LoadIdentities()
+	if err != nil {
+		return err
+	}
+	user := auth.FindUser(identities, userName)
+	if user == nil {
+		return fmt.Errorf("%w: user %s", ErrNoSuchEntity, userName)
+	}
+	if !slices.Contains(user.Groups, groupName) {
+		return fmt.Errorf("%w: user %s is not a member of group %s", ErrNoSuchEntity, userName, groupName)
+	}
+
+	user.Groups = slices.DeleteFunc(user.Groups, func(g string) bool { return g == groupName })
+	return auth.SaveIdentities(identities)
+}
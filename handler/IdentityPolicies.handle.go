@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/auth"
+	"github.com/aidenappl/openbucket-go/policy"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// Identity policies can be attached to users, groups or roles.
+const (
+	PolicyTargetUser  = "user"
+	PolicyTargetGroup = "group"
+	PolicyTargetRole  = "role"
+)
+
+// ListIdentityPolicies returns all identity policies.
+func ListIdentityPolicies() ([]types.ManagedPolicy, error) {
+	identities, err := auth.LoadIdentities()
+	if err != nil {
+		return nil, err
+	}
+	return identities.Policies, nil
+}
+
+// GetIdentityPolicy returns the named identity policy.
+func GetIdentityPolicy(name string) (*types.ManagedPolicy, error) {
+	identities, err := auth.LoadIdentities()
+	if err != nil {
+		return nil, err
+	}
+	p := auth.FindPolicy(identities, name)
+	if p == nil {
+		return nil, fmt.Errorf("%w: policy %s", ErrNoSuchEntity, name)
+	}
+	return p, nil
+}
+
+// PutIdentityPolicy creates the named policy, or replaces its document if it exists.
+func PutIdentityPolicy(name string, document []byte) (*types.ManagedPolicy, error) {
+	if err := validateIdentityName("policy", name); err != nil {
+		return nil, err
+	}
+	if _, err := policy.ParseIdentityPolicy(document); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+
+	identitiesMu.Lock()
+	defer identitiesMu.Unlock()
+
+	identities, err := auth.LoadIdentities()
+	if err != nil {
+		return nil, err
+	}
+
+	p := auth.FindPolicy(identities, name)
+	if p == nil {
+		identities.Policies = append(identities.Policies, types.ManagedPolicy{Name: name, DateCreated: time.Now()})
+		p = &identities.Policies[len(identities.Policies)-1]
+	}
+	p.Document = string(document)
+
+	if err := auth.SaveIdentities(identities); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// DeleteIdentityPolicy removes the policy and detaches it everywhere.
+func DeleteIdentityPolicy(name string) error {
+	identitiesMu.Lock()
+	defer identitiesMu.Unlock()
+
+	identities, err := auth.LoadIdentities()
+	if err != nil {
+		return err
+	}
+	if auth.FindPolicy(identities, name) == nil {
+		return fmt.Errorf("%w: policy %s", ErrNoSuchEntity, name)
+	}
+
+	isPolicy := func(p string) bool { return p == name }
+	identities.Policies = slices.DeleteFunc(identities.Policies, func(p types.ManagedPolicy) bool { return p.Name == name })
+	for i := range identities.Users {
+		identities.Users[i].Policies = slices.DeleteFunc(identities.Users[i].Policies, isPolicy)
+	}
+	for i := range identities.Groups {
+		identities.Groups[i].Policies = slices.DeleteFunc(identities.Groups[i].Policies, isPolicy)
+	}
+	for i := range identities.Roles {
+		identities.Roles[i].Policies = slices.DeleteFunc(identities.Roles[i].Policies, isPolicy)
+	}
+	return auth.SaveIdentities(identities)
+}
+
+// AttachPolicy attaches a policy to a user, group or role.
+func AttachPolicy(targetType, targetName, policyName string) error {
+	return updateAttachedPolicies(targetType, targetName, policyName, func(policies []string) ([]string, error) {
+		if slices.Contains(policies, policyName) {
+			return policies, nil
+		}
+		return append(policies, policyName), nil
+	})
+}
+
+// DetachPolicy detaches a policy from a user, group or role.
+func DetachPolicy(targetType, targetName, policyName string) error {
+	return updateAttachedPolicies(targetType, targetName, policyName, func(policies []string) ([]string, error) {
+		if !slices.Contains(policies, policyName) {
+			return nil, fmt.Errorf("%w: policy %s is not attached to %s %s", ErrNoSuchEntity, policyName, targetType, targetName)
+		}
+		return slices.DeleteFunc(policies, func(p string) bool { return p == policyName }), nil
+	})
+}
+
+func updateAttachedPolicies(targetType, targetName, policyName string, update func([]string) ([]string, error)) error {
+	identitiesMu.Lock()
+	defer identitiesMu.Unlock()
+
+	identities, err := auth.LoadIdentities()
+	if err != nil {
+		return err
+	}
+	if auth.FindPolicy(identities, policyName) == nil {
+		return fmt.Errorf("%w: policy %s", ErrNoSuchEntity, policyName)
+	}
+
+	var policies *[]string
+	switch targetType {
+	case PolicyTargetUser:
+		user := auth.FindUser(identities, targetName)
+		if user == nil {
+			return fmt.Errorf("%w: user %s", ErrNoSuchEntity, targetName)
+		}
+		policies = &user.Policies
+	case PolicyTargetGroup:
+		group := auth.FindGroup(identities, targetName)
+		if group == nil {
+			return fmt.Errorf("%w: group %s", ErrNoSuchEntity, targetName)
+		}
+		policies = &group.Policies
+	case PolicyTargetRole:
+		role := auth.FindRole(identities, targetName)
+		if role == nil {
+			return fmt.Errorf("%w: role %s", ErrNoSuchEntity, targetName)
+		}
+		policies = &role.Policies
+	default:
+		return fmt.Errorf("%w: invalid policy target type %q", ErrInvalidInput, targetType)
+	}
+
+	updated, err := update(*policies)
+	if err != nil {
+		return err
+	}
+	*policies = updated
+	return auth.SaveIdentities(identities)
+}
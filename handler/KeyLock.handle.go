@@ -0,0 +1,62 @@
+package handler
+
+import "sync"
+
+// Changes to an object take a lock on its key, so that replacing or deleting
+// it and releasing the deduplicated content it referred to are never
+// interleaved with another change to the same object. Code that loads,
+// changes and saves the metadata of an object holds the lock as well, so it
+// never saves metadata that was replaced in the meantime.
+var (
+	keyLocksMu sync.Mutex
+	keyLocks   = map[string]*keyLock{}
+)
+
+type keyLock struct {
+	sync.Mutex
+	waiters int
+}
+
+// LockObject locks bucket/key and returns the function that unlocks it. Locks
+// are dropped once nobody holds or waits for them.
+func LockObject(bucket, key string) func() {
+	// Bucket names cannot contain "/", so the name is unambiguous
+	name := bucket + "/" + key
+
+	keyLocksMu.Lock()
+	l := keyLocks[name]
+	if l == nil {
+		l = &keyLock{}
+		keyLocks[name] = l
+	}
+	l.waiters++
+	keyLocksMu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		keyLocksMu.Lock()
+		if l.waiters--; l.waiters == 0 {
+			delete(keyLocks, name)
+		}
+		keyLocksMu.Unlock()
+	}
+}
+
+// lockObjects locks two objects, which may be the same, and returns the
+// function that unlocks them. The keys are always locked in the same order,
+// so two calls locking the same pair never wait for each other.
+func lockObjects(bucketA, keyA, bucketB, keyB string) func() {
+	if bucketA == bucketB && keyA == keyB {
+		return LockObject(bucketA, keyA)
+	}
+	if bucketB+"/"+keyB < bucketA+"/"+keyA {
+		bucketA, keyA, bucketB, keyB = bucketB, keyB, bucketA, keyA
+	}
+	unlockA := LockObject(bucketA, keyA)
+	unlockB := LockObject(bucketB, keyB)
+	return func() {
+		unlockB()
+		unlockA()
+	}
+}
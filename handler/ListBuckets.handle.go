@@ -1,32 +1,89 @@
 package handler
 
 import (
+	"encoding/base64"
+	"errors"
 	"log"
 	"os"
+	"strings"
 
+	"github.com/aidenappl/openbucket-go/auth"
+	"github.com/aidenappl/openbucket-go/tools"
 	"github.com/aidenappl/openbucket-go/types"
 )
 
-func ListBucketsXML() (*types.BucketList, error) {
-	buckets, err := ListBuckets()
+// ErrInvalidContinuationToken is returned for a ListBuckets continuation
+// token that was not issued by ListBucketsXML.
+var ErrInvalidContinuationToken = errors.New("invalid continuation token")
+
+// BucketQuery holds the parameters of a ListBuckets request. MaxBuckets
+// limits the page size when set; ContinuationToken resumes a previous page.
+type BucketQuery struct {
+	Prefix            string
+	MaxBuckets        int
+	ContinuationToken string
+}
+
+// ListBucketsXML returns a page of the buckets visible reports true for, see
+// ListBucketsFor, with owner as the S3 Owner of the listing.
+func ListBucketsXML(owner types.UserObject, q BucketQuery, visible func(bucket string) bool) (*types.BucketList, error) {
+	var after string
+	if q.ContinuationToken != "" {
+		name, err := base64.RawURLEncoding.DecodeString(q.ContinuationToken)
+		if err != nil || len(name) == 0 {
+			return nil, ErrInvalidContinuationToken
+		}
+		after = string(name)
+	}
+
+	buckets, err := ListBucketsFor(visible)
 	if err != nil {
 		log.Println("Error listing buckets:", err)
 		return nil, err
 	}
 
 	bucketList := &types.BucketList{
-		Buckets: struct {
-			Bucket []types.Bucket `xml:"Bucket"`
-		}{
-			Bucket: make([]types.Bucket, len(*buckets)),
-		},
+		Xmlns:  "http://s3.amazonaws.com/doc/2006-03-01/",
+		Owner:  &owner,
+		Prefix: q.Prefix,
 	}
-
-	copy(bucketList.Buckets.Bucket, *buckets)
+	entries := []types.BucketListEntry{}
+	for _, bucket := range buckets {
+		if bucket.Name <= after || !strings.HasPrefix(bucket.Name, q.Prefix) {
+			continue
+		}
+		if q.MaxBuckets > 0 && len(entries) == q.MaxBuckets {
+			bucketList.ContinuationToken = base64.RawURLEncoding.EncodeToString([]byte(entries[len(entries)-1].Name))
+			break
+		}
+		entries = append(entries, types.BucketListEntry{Name: bucket.Name, CreationDate: bucket.CreationDate})
+	}
+	bucketList.Buckets.Bucket = entries
 
 	return bucketList, nil
 }
 
+// ListBucketsFor returns the buckets visible reports true for, in name
+// order. Callers pass the decision of whether the caller may list the
+// bucket, so that ACLs, bucket and identity policies and roles all count.
+func ListBucketsFor(visible func(bucket string) bool) ([]types.Bucket, error) {
+	buckets, err := ListBuckets()
+	if err != nil {
+		return nil, err
+	}
+
+	var listed []types.Bucket
+	for _, bucket := range *buckets {
+		if visible(bucket.Name) {
+			listed = append(listed, bucket)
+		}
+	}
+	return listed, nil
+}
+
+// ListBuckets returns the records of the buckets that have a valid name and
+// a directory, in name order, with the name of their directory. Other
+// directories under buckets/ are not buckets.
 func ListBuckets() (*[]types.Bucket, error) {
 	bucketsDir := "buckets"
 	files, err := os.ReadDir(bucketsDir)
@@ -38,18 +95,17 @@ func ListBuckets() (*[]types.Bucket, error) {
 	var bucketList []types.Bucket
 
 	for _, file := range files {
-		if file.IsDir() {
-
-			info, err := file.Info()
-			if err != nil {
-				log.Println("Error getting file info:", err)
-				continue
-			}
-			bucketList = append(bucketList, types.Bucket{
-				Name:         file.Name(),
-				CreationDate: types.IsoTime(info.ModTime()),
-			})
+		if !file.IsDir() || !tools.ValidBucketName(file.Name()) {
+			continue
+		}
+
+		record, err := auth.LoadBucketPermissions(file.Name())
+		if err != nil {
+			log.Println("Skipping directory without a valid bucket record:", file.Name())
+			continue
 		}
+		record.Name = file.Name()
+		bucketList = append(bucketList, *record)
 	}
 	return &bucketList, nil
 }
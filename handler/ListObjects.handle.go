@@ -1,79 +1,70 @@
 package handler
 
 import (
-	"encoding/xml"
 	"fmt"
-	"io/fs"
 	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/aidenappl/openbucket-go/metadata"
 	"github.com/aidenappl/openbucket-go/types"
 )
 
+// ListObjects returns every object in a bucket in key order, read from the
+// metadata index. The parent directories of keys are listed as well, with
+// keys ending in "/", unless an object with that key exists.
 func ListObjects(bucket string) ([]types.ObjectMetadata, error) {
+	return listObjects(bucket, "")
+}
+
+// listObjects lists the objects whose key starts with prefix, along with
+// their parent directories.
+func listObjects(bucket, prefix string) ([]types.ObjectMetadata, error) {
 	root := filepath.Join("buckets", bucket)
 	if st, err := os.Stat(root); err != nil || !st.IsDir() {
-		return nil, fmt.Errorf("bucket %q not found", bucket)
+		return nil, fmt.Errorf("%w: %s", ErrNoSuchBucket, bucket)
 	}
 
-	var out []types.ObjectMetadata
-
-	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
-		if walkErr != nil {
-			return walkErr
-		}
-
-		if isIgnored(d.Name()) {
-			return nil
-		}
-		if path == root {
-			return nil
-		}
-
-		rel, _ := filepath.Rel(root, path)
-		key := filepath.ToSlash(rel)
-
-		// Handle directory keys
-		if d.IsDir() {
-
-			st, _ := os.Stat(path)
-			out = append(out, types.ObjectMetadata{
-				Key:          key + "/",
-				LastModified: types.IsoTime(st.ModTime()),
-			})
-			return nil
-		}
-
-		// Skip metadata files
-		if strings.HasSuffix(d.Name(), ".obmeta") {
-			return nil
-		}
+	indexed, err := metadata.List(bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
 
-		st, _ := os.Stat(path)
-		oc := types.ObjectMetadata{
-			Key:          key,
-			Size:         st.Size(),
-			LastModified: types.IsoTime(st.ModTime()),
-		}
+	out := make([]types.ObjectMetadata, 0, len(indexed))
+	objects := make(map[string]bool, len(indexed))
+	for _, md := range indexed {
+		objects[md.Key] = true
+		out = append(out, types.ObjectMetadata{
+			Key:          md.Key,
+			ETag:         md.ETag,
+			Owner:        md.Owner,
+			Size:         md.Size,
+			LastModified: md.LastModified,
+		})
+	}
 
-		metaPath := path + ".obmeta"
-		if f, err := os.Open(metaPath); err == nil {
-			defer f.Close()
-			var m types.ObjectMetadata
-			if err := xml.NewDecoder(f).Decode(&m); err == nil {
-				oc.ETag = m.ETag
-				oc.Owner = m.Owner
+	// Directories were last modified with their newest object
+	dirs := make(map[string]int)
+	for _, md := range indexed {
+		parts := strings.Split(strings.TrimSuffix(md.Key, "/"), "/")
+		for n := 1; n < len(parts); n++ {
+			dir := strings.Join(parts[:n], "/") + "/"
+			if objects[dir] {
+				continue
+			}
+			if i, ok := dirs[dir]; !ok {
+				dirs[dir] = len(out)
+				out = append(out, types.ObjectMetadata{Key: dir, LastModified: md.LastModified})
+			} else if time.Time(md.LastModified).After(time.Time(out[i].LastModified)) {
+				out[i].LastModified = md.LastModified
 			}
 		}
-		out = append(out, oc)
-		return nil
-	})
-	if err != nil {
-		return nil, err
 	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Key < out[j].Key })
 	return out, nil
 }
 
@@ -82,7 +73,7 @@ func ListObjectsXML(bucket string, q url.Values) (*types.ObjectList, error) {
 	prefix := q.Get("prefix")
 	delimiter := q.Get("delimiter")
 
-	all, err := ListObjects(bucket)
+	all, err := listObjects(bucket, prefix)
 	if err != nil {
 		return nil, err
 	}
@@ -129,10 +120,3 @@ func ListObjectsXML(bucket string, q url.Values) (*types.ObjectList, error) {
 		CommonPrefixes: cps,
 	}, nil
 }
-
-func isIgnored(name string) bool {
-	if name == ".DS_Store" {
-		return true
-	}
-	return false
-}
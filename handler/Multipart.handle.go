@@ -0,0 +1,406 @@
+package handler
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/bucketconfig"
+	"github.com/aidenappl/openbucket-go/metadata"
+	"github.com/aidenappl/openbucket-go/sse"
+	"github.com/aidenappl/openbucket-go/tools"
+	"github.com/aidenappl/openbucket-go/types"
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxPartNumber is the highest part number of a multipart upload.
+	MaxPartNumber = 10000
+	// MinPartSize is the smallest size of every part but the last.
+	MinPartSize = 5 * 1024 * 1024
+)
+
+var (
+	// ErrNoSuchUpload is returned for unknown or finished multipart uploads.
+	ErrNoSuchUpload = errors.New("no such upload")
+	// ErrInvalidPart is returned when a part is out of range, missing or
+	// does not match the ETag given for it.
+	ErrInvalidPart = errors.New("invalid part")
+	// ErrInvalidPartOrder is returned when parts are not listed in ascending order.
+	ErrInvalidPartOrder = errors.New("parts must be listed in ascending order")
+	// ErrPartTooSmall is returned when a part other than the last is below MinPartSize.
+	ErrPartTooSmall = errors.New("part is smaller than the minimum allowed size")
+)
+
+// uploadDir is where the parts of a multipart upload are kept until it is
+// completed or aborted. Uploads live outside buckets/ so they never show up
+// as objects or buckets.
+func uploadDir(bucket, uploadID string) string {
+	return filepath.Join("uploads", bucket, uploadID)
+}
+
+func partPath(bucket, uploadID string, partNumber int) string {
+	return filepath.Join(uploadDir(bucket, uploadID), fmt.Sprintf("%05d", partNumber))
+}
+
+// CreateMultipartUpload starts a multipart upload to bucket/key. The owner,
+// grants and options are applied to the object when the upload completes.
+// The encryption is fixed now: parts are encrypted at rest as they arrive.
+func CreateMultipartUpload(bucket, key string, owner types.UserObject, grants []types.Grant, opts PutOptions) (*types.MultipartUpload, error) {
+	if _, err := os.Stat(filepath.Join("buckets", bucket)); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNoSuchBucket, bucket)
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to access bucket: %w", err)
+	}
+
+	// Fail early for objects the upload could never replace
+	now := time.Now()
+	if existing, err := metadata.Load(bucket, key); err == nil {
+		if err := CheckObjectLock(existing, opts.BypassGovernance, now); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error loading metadata: %w", err)
+	}
+	if err := validateLock(bucket, opts.Retention, opts.LegalHold, now); err != nil {
+		return nil, err
+	}
+
+	upload := &types.MultipartUpload{
+		UploadID:  uuid.New().String(),
+		Bucket:    bucket,
+		Key:       key,
+		Owner:     owner,
+		Initiated: types.IsoTime(now),
+		Grants:    grants,
+		Retention: opts.Retention,
+		LegalHold: opts.LegalHold,
+		Encrypt:   opts.Encrypt,
+
+		WebsiteRedirectLocation: opts.WebsiteRedirectLocation,
+	}
+	if opts.CustomerKey != nil {
+		var err error
+		if upload.CustomerKey, err = sse.NewCustomerKey(opts.CustomerKey); err != nil {
+			return nil, err
+		}
+	} else if !opts.Encrypt {
+		cfg, err := bucketconfig.LoadEncryption(bucket)
+		if err != nil {
+			return nil, fmt.Errorf("error loading encryption configuration: %w", err)
+		}
+		upload.Encrypt = bucketconfig.EncryptByDefault(cfg)
+	}
+
+	if err := os.MkdirAll(uploadDir(bucket, upload.UploadID), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create upload directory: %w", err)
+	}
+	if err := saveXML(filepath.Join(uploadDir(bucket, upload.UploadID), "upload.xml"), upload); err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// LoadMultipartUpload returns the multipart upload uploadID to bucket/key.
+func LoadMultipartUpload(bucket, key, uploadID string) (*types.MultipartUpload, error) {
+	// Upload IDs come from the query string; only accept ones we could have issued
+	if _, err := uuid.Parse(uploadID); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoSuchUpload, uploadID)
+	}
+
+	data, err := os.ReadFile(filepath.Join(uploadDir(bucket, uploadID), "upload.xml"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNoSuchUpload, uploadID)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	var upload types.MultipartUpload
+	if err := xml.Unmarshal(data, &upload); err != nil {
+		return nil, fmt.Errorf("failed to decode upload: %w", err)
+	}
+	if upload.Key != key {
+		return nil, fmt.Errorf("%w: %s is not an upload to %s", ErrNoSuchUpload, uploadID, key)
+	}
+	return &upload, nil
+}
+
+// UploadPart stores a part of a multipart upload, replacing any earlier part
+// with the same number. SSE-C uploads need the key they were created with.
+func UploadPart(bucket, key, uploadID string, partNumber int, body io.Reader, customerKey []byte) (*types.UploadPart, error) {
+	upload, err := LoadMultipartUpload(bucket, key, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if partNumber < 1 || partNumber > MaxPartNumber {
+		return nil, fmt.Errorf("%w: part number must be an integer between 1 and %d", ErrInvalidPart, MaxPartNumber)
+	}
+	if err := sse.CheckCustomerKey(upload.CustomerKey, customerKey); err != nil {
+		return nil, err
+	}
+
+	var enc *types.ObjectEncryption
+	switch {
+	case upload.CustomerKey != nil:
+		enc, err = sse.NewCustomerKey(customerKey)
+	case upload.Encrypt:
+		enc, err = sse.NewObjectKey()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hash := md5.New()
+	path := partPath(bucket, uploadID, partNumber)
+	// Parts are compressed once they are assembled into the object
+	stored, err := writeFile(path, io.TeeReader(body, hash), "", enc, customerKey)
+	if err != nil {
+		return nil, err
+	}
+
+	part := &types.UploadPart{
+		PartNumber:   partNumber,
+		ETag:         hex.EncodeToString(hash.Sum(nil)),
+		Size:         stored.size,
+		LastModified: types.IsoTime(time.Now()),
+		Encryption:   enc,
+	}
+	if err := saveXML(path+".xml", part); err != nil {
+		return nil, err
+	}
+	return part, nil
+}
+
+// ListParts returns a multipart upload and its parts in part number order.
+func ListParts(bucket, key, uploadID string) (*types.MultipartUpload, []types.UploadPart, error) {
+	upload, err := LoadMultipartUpload(bucket, key, uploadID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries, err := os.ReadDir(uploadDir(bucket, uploadID))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	var parts []types.UploadPart
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".xml")
+		if !ok || name == "upload" {
+			continue
+		}
+		if _, err := strconv.Atoi(name); err != nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(uploadDir(bucket, uploadID), entry.Name()))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read part: %w", err)
+		}
+		var part types.UploadPart
+		if err := xml.Unmarshal(data, &part); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode part: %w", err)
+		}
+		parts = append(parts, part)
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return upload, parts, nil
+}
+
+// CompleteMultipartUpload assembles the listed parts into the object and
+// removes the upload. The object is written by PutObject with the settings
+// recorded when the upload was created.
+func CompleteMultipartUpload(bucket, key, uploadID string, completed []types.CompletedPart, customerKey []byte, bypassGovernance bool) (*types.ObjectMetadata, error) {
+	upload, stored, err := ListParts(bucket, key, uploadID)
+	if err != nil {
+		return nil, err
+	}
+	if err := sse.CheckCustomerKey(upload.CustomerKey, customerKey); err != nil {
+		return nil, err
+	}
+	if len(completed) == 0 {
+		return nil, fmt.Errorf("%w: at least one part must be specified", ErrInvalidPart)
+	}
+
+	byNumber := map[int]types.UploadPart{}
+	for _, part := range stored {
+		byNumber[part.PartNumber] = part
+	}
+	for i := 1; i < len(completed); i++ {
+		if completed[i].PartNumber <= completed[i-1].PartNumber {
+			return nil, ErrInvalidPartOrder
+		}
+	}
+	parts := make([]types.UploadPart, 0, len(completed))
+	for i, c := range completed {
+		part, ok := byNumber[c.PartNumber]
+		if !ok || strings.Trim(c.ETag, `"`) != part.ETag {
+			return nil, fmt.Errorf("%w: part %d was not found or its ETag does not match", ErrInvalidPart, c.PartNumber)
+		}
+		if i < len(completed)-1 && part.Size < MinPartSize {
+			return nil, fmt.Errorf("%w: part %d is %d bytes", ErrPartTooSmall, c.PartNumber, part.Size)
+		}
+		parts = append(parts, part)
+	}
+
+	body := &partsReader{bucket: bucket, uploadID: uploadID, parts: parts, customerKey: customerKey}
+	md, err := PutObject(bucket, key, body, upload.Owner, upload.Grants, PutOptions{
+		Retention:        upload.Retention,
+		LegalHold:        upload.LegalHold,
+		BypassGovernance: bypassGovernance,
+		Encrypt:          upload.Encrypt,
+		CustomerKey:      customerKey,
+
+		WebsiteRedirectLocation: upload.WebsiteRedirectLocation,
+	})
+	body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.RemoveAll(uploadDir(bucket, uploadID)); err != nil {
+		return md, fmt.Errorf("failed to remove completed upload: %w", err)
+	}
+	return md, nil
+}
+
+// AbortMultipartUpload discards a multipart upload and its parts.
+func AbortMultipartUpload(bucket, key, uploadID string) error {
+	if _, err := LoadMultipartUpload(bucket, key, uploadID); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(uploadDir(bucket, uploadID)); err != nil {
+		return fmt.Errorf("failed to remove upload: %w", err)
+	}
+	return nil
+}
+
+// ListMultipartUploads returns the uploads in progress to a bucket, ordered
+// by key and then by initiation time.
+func ListMultipartUploads(bucket string) ([]types.MultipartUpload, error) {
+	entries, err := os.ReadDir(filepath.Join("uploads", bucket))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read uploads: %w", err)
+	}
+
+	var uploads []types.MultipartUpload
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join("uploads", bucket, entry.Name(), "upload.xml"))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read upload: %w", err)
+		}
+		var upload types.MultipartUpload
+		if err := xml.Unmarshal(data, &upload); err != nil {
+			return nil, fmt.Errorf("failed to decode upload %s: %w", entry.Name(), err)
+		}
+		uploads = append(uploads, upload)
+	}
+	sort.Slice(uploads, func(i, j int) bool {
+		if uploads[i].Key != uploads[j].Key {
+			return uploads[i].Key < uploads[j].Key
+		}
+		return time.Time(uploads[i].Initiated).Before(time.Time(uploads[j].Initiated))
+	})
+	return uploads, nil
+}
+
+// rewrapParts re-wraps the data keys of the parts of every upload in progress
+// to a bucket with the active key-encryption key.
+func rewrapParts(bucket string) (int, error) {
+	uploads, err := ListMultipartUploads(bucket)
+	if err != nil {
+		return 0, err
+	}
+
+	rewrapped := 0
+	for _, upload := range uploads {
+		_, parts, err := ListParts(bucket, upload.Key, upload.UploadID)
+		if err != nil {
+			return rewrapped, err
+		}
+		for _, part := range parts {
+			if part.Encryption == nil {
+				continue
+			}
+			changed, err := sse.Rewrap(part.Encryption)
+			if err != nil {
+				return rewrapped, fmt.Errorf("upload %s part %d: %w", upload.UploadID, part.PartNumber, err)
+			}
+			if !changed {
+				continue
+			}
+			if err := saveXML(partPath(bucket, upload.UploadID, part.PartNumber)+".xml", &part); err != nil {
+				return rewrapped, err
+			}
+			rewrapped++
+		}
+	}
+	return rewrapped, nil
+}
+
+// partsReader reads the parts of an upload one after another, decrypting
+// them as needed.
+type partsReader struct {
+	bucket      string
+	uploadID    string
+	parts       []types.UploadPart
+	customerKey []byte
+	current     *ObjectReader
+}
+
+func (p *partsReader) Read(b []byte) (int, error) {
+	for {
+		if p.current == nil {
+			if len(p.parts) == 0 {
+				return 0, io.EOF
+			}
+			part := p.parts[0]
+			p.parts = p.parts[1:]
+			file, err := openStored(partPath(p.bucket, p.uploadID, part.PartNumber), part.Encryption, p.customerKey, nil, part.Size)
+			if err != nil {
+				return 0, err
+			}
+			p.current = file
+		}
+
+		n, err := p.current.Read(b)
+		if errors.Is(err, io.EOF) {
+			p.current.Close()
+			p.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (p *partsReader) Close() {
+	if p.current != nil {
+		p.current.Close()
+		p.current = nil
+	}
+}
+
+// saveXML writes v as XML to path.
+func saveXML(path string, v any) error {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling %s: %w", filepath.Base(path), err)
+	}
+	if err := tools.WriteFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
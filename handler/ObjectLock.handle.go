@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/bucketconfig"
+	"github.com/aidenappl/openbucket-go/metadata"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+var (
+	// ErrObjectLocked is returned when retention or a legal hold protects an object.
+	ErrObjectLocked = errors.New("object is protected by object lock")
+	// ErrObjectLockNotEnabled is returned when locking objects in a bucket without Object Lock.
+	ErrObjectLockNotEnabled = errors.New("bucket is missing object lock configuration")
+	// ErrInvalidRetention is returned for retention settings S3 would reject.
+	ErrInvalidRetention = errors.New("invalid object lock settings")
+)
+
+// EnableObjectLock turns on Object Lock for a bucket. S3 only allows this
+// when the bucket is created.
+func EnableObjectLock(bucket string) error {
+	return bucketconfig.SaveObjectLock(bucket, &types.ObjectLockConfiguration{ObjectLockEnabled: "Enabled"})
+}
+
+// CheckObjectLock returns ErrObjectLocked when a legal hold or unexpired
+// retention keeps the object from being deleted or overwritten at now.
+// GOVERNANCE retention is ignored when bypassGovernance is set.
+func CheckObjectLock(md *types.ObjectMetadata, bypassGovernance bool, now time.Time) error {
+	if md == nil {
+		return nil
+	}
+	if md.LegalHold == types.LegalHoldOn {
+		return fmt.Errorf("%w: %s/%s is under legal hold", ErrObjectLocked, md.Bucket, md.Key)
+	}
+	if r := md.Retention; r != nil && now.Before(time.Time(r.RetainUntilDate)) {
+		if r.Mode == types.RetentionCompliance || !bypassGovernance {
+			return fmt.Errorf("%w: %s/%s is retained in %s mode until %s", ErrObjectLocked, md.Bucket, md.Key, r.Mode, time.Time(r.RetainUntilDate).UTC().Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// DefaultRetention returns the retention the bucket's Object Lock
+// configuration gives new objects written at now, or nil when it has none.
+func DefaultRetention(bucket string, now time.Time) (*types.ObjectRetention, error) {
+	cfg, err := bucketconfig.LoadObjectLock(bucket)
+	if err != nil || cfg == nil || cfg.Rule == nil {
+		return nil, err
+	}
+	d := cfg.Rule.DefaultRetention
+	return &types.ObjectRetention{
+		Mode:            d.Mode,
+		RetainUntilDate: types.IsoTime(now.AddDate(d.Years, 0, d.Days)),
+	}, nil
+}
+
+// PutObjectRetention replaces the retention of an object; a retention without
+// a mode removes it. Unexpired COMPLIANCE retention can only be extended, and
+// shortening, removing or changing the mode of unexpired GOVERNANCE retention
+// requires bypassGovernance.
+func PutObjectRetention(bucket, key string, retention *types.ObjectRetention, bypassGovernance bool) error {
+	now := time.Now()
+	if retention != nil && retention.Mode == "" {
+		retention = nil
+	}
+	if err := validateLock(bucket, retention, "", now); err != nil {
+		return err
+	}
+
+	defer LockObject(bucket, key)()
+	md, err := loadLockable(bucket, key)
+	if err != nil {
+		return err
+	}
+
+	if current := md.Retention; current != nil && now.Before(time.Time(current.RetainUntilDate)) {
+		weakened := retention == nil || retention.Mode != current.Mode ||
+			time.Time(retention.RetainUntilDate).Before(time.Time(current.RetainUntilDate))
+		if weakened && (current.Mode == types.RetentionCompliance || !bypassGovernance) {
+			return fmt.Errorf("%w: %s/%s is retained in %s mode until %s", ErrObjectLocked, bucket, key, current.Mode, time.Time(current.RetainUntilDate).UTC().Format(time.RFC3339))
+		}
+	}
+
+	if retention != nil {
+		retention = &types.ObjectRetention{Mode: retention.Mode, RetainUntilDate: retention.RetainUntilDate}
+	}
+	md.Retention = retention
+	return metadata.Save(md)
+}
+
+// PutObjectLegalHold turns the legal hold of an object ON or OFF.
+func PutObjectLegalHold(bucket, key, status string) error {
+	if err := validateLock(bucket, nil, status, time.Now()); err != nil {
+		return err
+	}
+
+	defer LockObject(bucket, key)()
+	md, err := loadLockable(bucket, key)
+	if err != nil {
+		return err
+	}
+	md.LegalHold = status
+	return metadata.Save(md)
+}
+
+// loadLockable loads the metadata of an object whose lock is being changed.
+func loadLockable(bucket, key string) (*types.ObjectMetadata, error) {
+	md, err := metadata.Load(bucket, key)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s/%s", ErrNoSuchKey, bucket, key)
+	}
+	return md, err
+}
+
+// validateLock checks a retention and legal hold requested for an object in
+// bucket. Either may be left empty; locking requires Object Lock on the bucket.
+func validateLock(bucket string, retention *types.ObjectRetention, legalHold string, now time.Time) error {
+	if retention == nil && legalHold == "" {
+		return nil
+	}
+
+	cfg, err := bucketconfig.LoadObjectLock(bucket)
+	if err != nil {
+		return err
+	}
+	if cfg == nil {
+		return fmt.Errorf("%w: %s", ErrObjectLockNotEnabled, bucket)
+	}
+
+	if legalHold != "" && legalHold != types.LegalHoldOn && legalHold != types.LegalHoldOff {
+		return fmt.Errorf("%w: legal hold status must be ON or OFF", ErrInvalidRetention)
+	}
+	if retention != nil {
+		if retention.Mode != types.RetentionGovernance && retention.Mode != types.RetentionCompliance {
+			return fmt.Errorf("%w: mode must be GOVERNANCE or COMPLIANCE", ErrInvalidRetention)
+		}
+		if !now.Before(time.Time(retention.RetainUntilDate)) {
+			return fmt.Errorf("%w: the retain until date must be in the future", ErrInvalidRetention)
+		}
+	}
+	return nil
+}
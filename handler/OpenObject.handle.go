@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aidenappl/openbucket-go/chunkstore"
+	"github.com/aidenappl/openbucket-go/compression"
+	"github.com/aidenappl/openbucket-go/metadata"
+	"github.com/aidenappl/openbucket-go/sse"
+	"github.com/aidenappl/openbucket-go/tools"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// ObjectReader reads the content of a stored object, decrypting and
+// decompressing it as needed.
+type ObjectReader struct {
+	io.ReadSeeker
+	file *os.File
+	err  error
+}
+
+func (o *ObjectReader) Read(p []byte) (int, error) {
+	n, err := o.ReadSeeker.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) && o.err == nil {
+		o.err = err
+	}
+	return n, err
+}
+
+// Err returns the first error a read failed with, if any. Readers such as
+// http.ServeContent stop at a failed read without reporting it.
+func (o *ObjectReader) Err() error {
+	return o.err
+}
+
+// Close closes the object's file.
+func (o *ObjectReader) Close() error {
+	return o.file.Close()
+}
+
+// OpenObject opens an object for reading. customerKey must be the
+// customer-provided key of objects encrypted with SSE-C, and nil otherwise.
+func OpenObject(bucket, key string, customerKey []byte) (*ObjectReader, *types.ObjectMetadata, error) {
+	md, err := metadata.Load(bucket, key)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, fmt.Errorf("%w: %s/%s", ErrNoSuchKey, bucket, key)
+	} else if err != nil {
+		return nil, nil, err
+	}
+	if err := sse.CheckCustomerKey(md.Encryption, customerKey); err != nil {
+		return nil, nil, err
+	}
+
+	file, err := OpenContent(bucket, key, md, customerKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return file, md, nil
+}
+
+// OpenContent opens the content of bucket/key as described by its metadata,
+// reading deduplicated content from the chunk store. The customer-provided
+// key is not checked; see sse.CheckCustomerKey.
+func OpenContent(bucket, key string, md *types.ObjectMetadata, customerKey []byte) (*ObjectReader, error) {
+	filePath := tools.ObjectPath(bucket, key)
+	if md.ContentHash != "" {
+		filePath = chunkstore.Path(md.ContentHash)
+	}
+	return openStored(filePath, md.Encryption, customerKey, md.Compression, md.Size)
+}
+
+// openStored opens a file written by writeFile with size bytes of content.
+func openStored(filePath string, enc *types.ObjectEncryption, customerKey []byte, comp *types.ObjectCompression, size int64) (*ObjectReader, error) {
+	file, err := os.Open(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNoSuchKey, filePath)
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Compressed content is encrypted after compression
+	var content io.ReadSeeker = file
+	if enc != nil {
+		sealed := size
+		if comp != nil {
+			sealed = comp.Size
+		}
+		if content, err = sse.Decrypt(enc, customerKey, file, sealed); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	if comp != nil {
+		if content, err = compression.Decompress(comp, content, size); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return &ObjectReader{ReadSeeker: content, file: file}, nil
+}
@@ -0,0 +1,276 @@
+package handler
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/bucketconfig"
+	"github.com/aidenappl/openbucket-go/chunkstore"
+	"github.com/aidenappl/openbucket-go/compression"
+	"github.com/aidenappl/openbucket-go/metadata"
+	"github.com/aidenappl/openbucket-go/sse"
+	"github.com/aidenappl/openbucket-go/tools"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// ErrNoSuchBucket is returned when writing to a bucket that does not exist.
+var ErrNoSuchBucket = errors.New("no such bucket")
+
+// MaxObjectSize is the largest object a single PUT may upload; larger
+// objects need a multipart upload.
+const MaxObjectSize = 5 * 1024 * 1024 * 1024
+
+// PutOptions holds the optional settings of an upload.
+type PutOptions struct {
+	// Retention and LegalHold lock the new object. Without a retention the
+	// bucket's default retention, if any, applies.
+	Retention *types.ObjectRetention
+	LegalHold string
+	// BypassGovernance allows replacing an object under GOVERNANCE retention.
+	BypassGovernance bool
+	// Encrypt stores the object encrypted at rest. Buckets with a default
+	// encryption configuration encrypt every object.
+	Encrypt bool
+	// CustomerKey encrypts the object with a customer-provided key (SSE-C)
+	// instead. The key is not stored.
+	CustomerKey []byte
+	// ReplicationStatus is REPLICA for copies written by the replication of
+	// another bucket, which are not replicated further.
+	ReplicationStatus string
+	// WebsiteRedirectLocation redirects website requests for the object.
+	WebsiteRedirectLocation string
+	// Tags are the tags of the new object. CopyObject keeps the tags of the
+	// source instead unless ReplaceTags is set.
+	Tags        []types.Tag
+	ReplaceTags bool
+}
+
+// PutObject writes the body to bucket/key and saves its metadata with the
+// given owner and grants. Keys ending in "/" are ordinary objects, usually
+// empty, that are listed as directories. The body is written to a temporary file first, so a failed or
+// rejected upload (for example a body reader returning an error) leaves any
+// existing object untouched. Locked objects cannot be replaced.
+func PutObject(bucket, key string, body io.Reader, owner types.UserObject, grants []types.Grant, opts PutOptions) (*types.ObjectMetadata, error) {
+	defer LockObject(bucket, key)()
+	return putObject(bucket, key, owner, grants, opts, func(filePath, compress string, enc *types.ObjectEncryption, customerKey []byte) (*storedFile, error) {
+		return storeContent(filePath, body, compress, enc, customerKey)
+	})
+}
+
+// contentWriter stores the content of a new object for filePath, compressed
+// with the compress algorithm and encrypted with enc when set.
+type contentWriter func(filePath, compress string, enc *types.ObjectEncryption, customerKey []byte) (*storedFile, error)
+
+// putObject creates or replaces bucket/key with the content stored by write.
+// The caller holds the lock of bucket/key.
+func putObject(bucket, key string, owner types.UserObject, grants []types.Grant, opts PutOptions, write contentWriter) (*types.ObjectMetadata, error) {
+	bucketDir := filepath.Join("buckets", bucket)
+	if _, err := os.Stat(bucketDir); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrNoSuchBucket, bucket)
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to access bucket: %w", err)
+	}
+
+	filePath := tools.ObjectPath(bucket, key)
+	now := time.Now()
+	existing, err := metadata.Load(bucket, key)
+	if err == nil {
+		if err := CheckObjectLock(existing, opts.BypassGovernance, now); err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("error loading metadata: %w", err)
+	}
+
+	retention := opts.Retention
+	if err := validateLock(bucket, retention, opts.LegalHold, now); err != nil {
+		return nil, err
+	}
+	if retention == nil {
+		if retention, err = DefaultRetention(bucket, now); err != nil {
+			return nil, fmt.Errorf("error loading object lock configuration: %w", err)
+		}
+	}
+
+	enc, err := newEncryption(bucket, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	compressCfg, err := bucketconfig.LoadCompression(bucket)
+	if err != nil {
+		return nil, fmt.Errorf("error loading compression configuration: %w", err)
+	}
+
+	stored, err := write(filePath, bucketconfig.CompressionFor(compressCfg, key), enc, opts.CustomerKey)
+	if err != nil {
+		return nil, err
+	}
+
+	md := &types.ObjectMetadata{
+		ETag:         stored.etag,
+		Key:          key,
+		Bucket:       bucket,
+		Tags:         opts.Tags,
+		Owner:        owner,
+		LastModified: types.IsoTime(time.Now()),
+		UploadedAt:   types.IsoTime(time.Now()),
+		VersionId:    "1",
+		Size:         stored.size,
+		StoredSize:   stored.storedSize,
+		Retention:    retention,
+		LegalHold:    opts.LegalHold,
+		Encryption:   enc,
+		Compression:  stored.compression,
+		ContentHash:  stored.contentHash,
+
+		ReplicationStatus:       opts.ReplicationStatus,
+		WebsiteRedirectLocation: opts.WebsiteRedirectLocation,
+	}
+	metadata.SetGrants(md, grants)
+
+	if err := metadata.Save(md); err != nil {
+		if stored.contentHash != "" {
+			chunkstore.Release(stored.contentHash)
+		}
+		return nil, fmt.Errorf("error saving metadata: %w", err)
+	}
+
+	// The replaced content is released after the new metadata is saved, so a
+	// crash leaks a chunk rather than leaving a dangling reference
+	if existing != nil && existing.ContentHash != "" {
+		if err := chunkstore.Release(existing.ContentHash); err != nil {
+			log.Println("Error releasing replaced content:", err)
+		}
+	}
+	return md, nil
+}
+
+// storeContent writes body for filePath and returns how it was stored. The
+// ETag covers the path and the original content, however it is stored.
+// Unencrypted content goes to the chunk store in dedup storage mode.
+func storeContent(filePath string, body io.Reader, compress string, enc *types.ObjectEncryption, customerKey []byte) (*storedFile, error) {
+	hash := md5.New()
+	hash.Write([]byte(filePath))
+	body = io.TeeReader(body, hash)
+
+	var stored *storedFile
+	var err error
+	if enc == nil && Deduplicate() {
+		stored, err = writeChunk(filePath, body, compress)
+	} else {
+		stored, err = writeFile(filePath, body, compress, enc, customerKey)
+	}
+	if err != nil {
+		return nil, err
+	}
+	stored.etag = hex.EncodeToString(hash.Sum(nil))
+	return stored, nil
+}
+
+// newEncryption returns the encryption of a new object in bucket, or nil when
+// it is stored in plain: a customer-provided key wins over SSE-S3, which is
+// used when asked for or when the bucket encrypts by default.
+func newEncryption(bucket string, opts PutOptions) (*types.ObjectEncryption, error) {
+	if opts.CustomerKey != nil {
+		return sse.NewCustomerKey(opts.CustomerKey)
+	}
+	if !opts.Encrypt {
+		cfg, err := bucketconfig.LoadEncryption(bucket)
+		if err != nil {
+			return nil, fmt.Errorf("error loading encryption configuration: %w", err)
+		}
+		if !bucketconfig.EncryptByDefault(cfg) {
+			return nil, nil
+		}
+	}
+	enc, err := sse.NewObjectKey()
+	if err != nil {
+		return nil, fmt.Errorf("error creating data key: %w", err)
+	}
+	return enc, nil
+}
+
+// storedFile describes how the content of an object was stored.
+type storedFile struct {
+	size        int64 // bytes read from the body
+	storedSize  int64 // bytes written to disk
+	compression *types.ObjectCompression
+	etag        string
+	contentHash string // set when the content is in the chunk store
+}
+
+// writeFile writes body to filePath through a temporary file. The content is
+// compressed with the compress algorithm unless it is empty or the content
+// does not shrink, and then encrypted when enc is set.
+func writeFile(filePath string, body io.Reader, compress string, enc *types.ObjectEncryption, customerKey []byte) (*storedFile, error) {
+	if err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".tmp-*")
+	if err != nil {
+		return nil, fmt.Errorf("unable to create file: %w", err)
+	}
+	tmpName := tmp.Name()
+
+	stored := &storedFile{}
+	counted := &countingReader{r: body}
+	var src io.Reader = counted
+	if compress != "" {
+		src, stored.compression, err = compression.Compress(compress, src)
+	}
+
+	var written int64
+	if err == nil && enc != nil {
+		written, err = sse.Encrypt(enc, customerKey, tmp, src)
+	} else if err == nil {
+		written, err = io.Copy(tmp, src)
+	}
+	if err == nil {
+		var info os.FileInfo
+		if info, err = tmp.Stat(); err == nil {
+			stored.storedSize = info.Size()
+		}
+	}
+	if err == nil {
+		// CreateTemp files are private; objects get the usual file mode
+		err = tmp.Chmod(0o644)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpName)
+		return nil, fmt.Errorf("error saving file: %w", err)
+	}
+	if err := os.Rename(tmpName, filePath); err != nil {
+		os.Remove(tmpName)
+		return nil, fmt.Errorf("error saving file: %w", err)
+	}
+
+	stored.size = counted.n
+	if stored.compression != nil {
+		stored.compression.Size = written
+	}
+	return stored, nil
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/auth"
+	"github.com/aidenappl/openbucket-go/policy"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// Session duration limits for roles, in seconds, as in IAM.
+const (
+	DefaultRoleSessionDuration = 3600
+	MaxRoleSessionDuration     = 43200
+)
+
+// ListRoles returns all roles.
+func ListRoles() ([]types.Role, error) {
+	identities, err := auth.LoadIdentities()
+	if err != nil {
+		return nil, err
+	}
+	return identities.Roles, nil
+}
+
+// CreateRole adds a role that the principals in the trust policy can assume.
+// maxDuration is the longest session in seconds; zero selects the default.
+func CreateRole(name string, trustPolicy []byte, maxDuration int) (*types.Role, error) {
+	if err := validateIdentityName("role", name); err != nil {
+		return nil, err
+	}
+	if _, err := policy.ParseTrustPolicy(trustPolicy); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	if maxDuration == 0 {
+		maxDuration = DefaultRoleSessionDuration
+	}
+	if maxDuration < MinSessionDuration || maxDuration > MaxRoleSessionDuration {
+		return nil, fmt.Errorf("%w: max session duration must be between %d and %d seconds", ErrInvalidInput, MinSessionDuration, MaxRoleSessionDuration)
+	}
+
+	identitiesMu.Lock()
+	defer identitiesMu.Unlock()
+
+	identities, err := auth.LoadIdentities()
+	if err != nil {
+		return nil, err
+	}
+	if auth.FindRole(identities, name) != nil {
+		return nil, fmt.Errorf("%w: role %s", ErrEntityAlreadyExists, name)
+	}
+
+	role := types.Role{
+		Name:               name,
+		TrustPolicy:        string(trustPolicy),
+		MaxSessionDuration: maxDuration,
+		DateCreated:        time.Now(),
+	}
+	identities.Roles = append(identities.Roles, role)
+	if err := auth.SaveIdentities(identities); err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// DeleteRole removes a role. Sessions issued for it stop working immediately.
+func DeleteRole(name string) error {
+	identitiesMu.Lock()
+	defer identitiesMu.Unlock()
+
+	identities, err := auth.LoadIdentities()
+	if err != nil {
+		return err
+	}
+	if auth.FindRole(identities, name) == nil {
+		return fmt.Errorf("%w: role %s", ErrNoSuchEntity, name)
+	}
+
+	identities.Roles = slices.DeleteFunc(identities.Roles, func(r types.Role) bool { return r.Name == name })
+	return auth.SaveIdentities(identities)
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/auth"
+	"github.com/aidenappl/openbucket-go/oidc"
+	"github.com/aidenappl/openbucket-go/policy"
+	"github.com/aidenappl/openbucket-go/sts"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// Session limits, in seconds for durations, matching AWS STS.
+const (
+	MinSessionDuration          = 900
+	DefaultSessionTokenDuration = 43200
+	MaxSessionTokenDuration     = 129600
+	MaxSessionPolicySize        = 2048
+)
+
+// ErrAccessDenied is returned when the caller may not assume a role.
+var ErrAccessDenied = errors.New("access denied")
+
+// sessionNamePattern matches the STS rules for RoleSessionName.
+var sessionNamePattern = regexp.MustCompile(`^[\w+=,.@-]{2,64}$`)
+
+// GetSessionToken issues temporary credentials that act as the caller's
+// long-term access key, optionally limited by a session policy.
+func GetSessionToken(caller *types.Authorization, durationSeconds int, sessionPolicy string) (*types.Credentials, error) {
+	if durationSeconds == 0 {
+		durationSeconds = DefaultSessionTokenDuration
+	}
+	if durationSeconds < MinSessionDuration || durationSeconds > MaxSessionTokenDuration {
+		return nil, fmt.Errorf("%w: DurationSeconds must be between %d and %d", ErrInvalidInput, MinSessionDuration, MaxSessionTokenDuration)
+	}
+	if err := validateSessionPolicy(sessionPolicy); err != nil {
+		return nil, err
+	}
+
+	return sts.Issue(sts.Claims{
+		Parent: caller.KeyID,
+		Policy: sessionPolicy,
+	}, time.Duration(durationSeconds)*time.Second)
+}
+
+// AssumeRole issues temporary credentials for a role. The role's trust
+// policy must allow the caller, described by trustReq, to assume it.
+func AssumeRole(trustReq *policy.Request, caller *types.Authorization, roleARN, sessionName string, durationSeconds int, sessionPolicy string) (*types.Credentials, *types.AssumedRoleUser, error) {
+	role, duration, err := prepareRoleSession(roleARN, sessionName, durationSeconds, sessionPolicy)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The trust policy may name the caller by access key or by user
+	trust, err := policy.ParseTrustPolicy([]byte(role.TrustPolicy))
+	if err != nil {
+		return nil, nil, fmt.Errorf("role %s has an invalid trust policy: %v", role.Name, err)
+	}
+	trustReq.Resource = policy.RoleARN(role.Name)
+	if caller.User != "" {
+		trustReq.Principals = append(trustReq.Principals, policy.UserARN(caller.User))
+		trustReq.Conditions["aws:username"] = []string{caller.User}
+	}
+	if policy.EvaluateTrustPolicy(trust, trustReq) != policy.Allow {
+		return nil, nil, fmt.Errorf("%w: %s is not authorized to assume role %s", ErrAccessDenied, caller.KeyID, role.Name)
+	}
+
+	return issueRoleSession(sts.Claims{
+		Parent:      caller.KeyID,
+		Role:        role.Name,
+		SessionName: sessionName,
+		Policy:      sessionPolicy,
+	}, duration)
+}
+
+// AssumeRoleWithWebIdentity exchanges an OIDC token from a configured
+// provider for temporary credentials. The provider's role mappings, not the
+// role's trust policy, decide which roles the token may assume.
+func AssumeRoleWithWebIdentity(webIdentityToken, roleARN, sessionName string, durationSeconds int, sessionPolicy string) (*types.Credentials, *types.AssumedRoleUser, *oidc.Identity, error) {
+	providers, err := oidc.LoadProviders()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	identity, err := oidc.Verify(providers, webIdentityToken)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	role, duration, err := prepareRoleSession(roleARN, sessionName, durationSeconds, sessionPolicy)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if !slices.Contains(mappedRoles(identity), role.Name) {
+		return nil, nil, nil, fmt.Errorf("%w: subject %s of %s is not mapped to role %s", ErrAccessDenied, identity.Subject, identity.Issuer, role.Name)
+	}
+
+	creds, user, err := issueRoleSession(sts.Claims{
+		Role:        role.Name,
+		SessionName: sessionName,
+		Issuer:      identity.Issuer,
+		Subject:     identity.Subject,
+		Policy:      sessionPolicy,
+	}, duration)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return creds, user, identity, nil
+}
+
+// mappedRoles returns the roles the identity's claims are mapped to by its provider.
+func mappedRoles(identity *oidc.Identity) []string {
+	var roles []string
+	for _, m := range identity.Provider.RoleMappings {
+		if slices.Contains(roles, m.Role) {
+			continue
+		}
+		for _, v := range identity.ClaimValues(m.Claim) {
+			if policy.WildcardMatch(m.Value, v) {
+				roles = append(roles, m.Role)
+				break
+			}
+		}
+	}
+	return roles
+}
+
+// prepareRoleSession validates the parameters shared by the AssumeRole
+// actions and returns the role with the session duration to use.
+func prepareRoleSession(roleARN, sessionName string, durationSeconds int, sessionPolicy string) (*types.Role, time.Duration, error) {
+	_, roleName, ok := strings.Cut(roleARN, ":role/")
+	if !ok || !strings.HasPrefix(roleARN, "arn:aws:iam:") || roleName == "" {
+		return nil, 0, fmt.Errorf("%w: invalid RoleArn %q", ErrInvalidInput, roleARN)
+	}
+	if !sessionNamePattern.MatchString(sessionName) {
+		return nil, 0, fmt.Errorf("%w: invalid RoleSessionName %q", ErrInvalidInput, sessionName)
+	}
+	if err := validateSessionPolicy(sessionPolicy); err != nil {
+		return nil, 0, err
+	}
+
+	identities, err := auth.LoadIdentities()
+	if err != nil {
+		return nil, 0, err
+	}
+	role := auth.FindRole(identities, roleName)
+	if role == nil {
+		return nil, 0, fmt.Errorf("%w: role %s", ErrNoSuchEntity, roleName)
+	}
+
+	maxDuration := role.MaxSessionDuration
+	if maxDuration == 0 {
+		maxDuration = DefaultRoleSessionDuration
+	}
+	if durationSeconds == 0 {
+		durationSeconds = DefaultRoleSessionDuration
+	}
+	if durationSeconds < MinSessionDuration || durationSeconds > maxDuration {
+		return nil, 0, fmt.Errorf("%w: DurationSeconds must be between %d and %d", ErrInvalidInput, MinSessionDuration, maxDuration)
+	}
+	return role, time.Duration(durationSeconds) * time.Second, nil
+}
+
+// issueRoleSession issues credentials for a role session.
+func issueRoleSession(claims sts.Claims, duration time.Duration) (*types.Credentials, *types.AssumedRoleUser, error) {
+	creds, err := sts.Issue(claims, duration)
+	if err != nil {
+		return nil, nil, err
+	}
+	return creds, &types.AssumedRoleUser{
+		AssumedRoleID: creds.AccessKeyID + ":" + claims.SessionName,
+		Arn:           policy.AssumedRoleARN(claims.Role, claims.SessionName),
+	}, nil
+}
+
+// validateSessionPolicy checks an optional session policy document.
+func validateSessionPolicy(document string) error {
+	if document == "" {
+		return nil
+	}
+	if len(document) > MaxSessionPolicySize {
+		return fmt.Errorf("%w: session policy exceeds %d characters", ErrInvalidInput, MaxSessionPolicySize)
+	}
+	if _, err := policy.ParseIdentityPolicy([]byte(document)); err != nil {
+		return fmt.Errorf("%w: session policy: %v", ErrInvalidInput, err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/aidenappl/openbucket-go/chunkstore"
+	"github.com/aidenappl/openbucket-go/metadata"
+	"github.com/aidenappl/openbucket-go/tools"
+)
+
+// BucketUsage is the storage taken by the objects of a bucket. LogicalSize
+// counts their content and StoredSize the files under the bucket; content
+// in the chunk store is counted in ChunkedSize and shared between buckets.
+type BucketUsage struct {
+	Bucket      string
+	Objects     int
+	LogicalSize int64
+	StoredSize  int64
+	ChunkedSize int64
+}
+
+// StorageUsage is the storage taken by all buckets and the chunk store.
+type StorageUsage struct {
+	Buckets []BucketUsage
+	Chunks  *chunkstore.Usage
+}
+
+// LogicalSize returns the size of the content of every object.
+func (u *StorageUsage) LogicalSize() int64 {
+	var size int64
+	for _, b := range u.Buckets {
+		size += b.LogicalSize
+	}
+	return size
+}
+
+// StoredSize returns the bytes stored on disk for every object.
+func (u *StorageUsage) StoredSize() int64 {
+	size := u.Chunks.StoredSize
+	for _, b := range u.Buckets {
+		size += b.StoredSize
+	}
+	return size
+}
+
+// StorageStats measures the logical and physical size of every bucket and of
+// the chunk store.
+func StorageStats() (*StorageUsage, error) {
+	buckets, err := ListBuckets()
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &StorageUsage{}
+	for _, bucket := range *buckets {
+		objects, err := ListObjects(bucket.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		bu := BucketUsage{Bucket: bucket.Name}
+		for _, o := range objects {
+			if strings.HasSuffix(o.Key, "/") {
+				continue
+			}
+			info, err := os.Stat(tools.ObjectPath(bucket.Name, o.Key))
+			if err != nil {
+				return nil, err
+			}
+			md, err := metadata.Load(bucket.Name, o.Key)
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+
+			bu.Objects++
+			bu.LogicalSize += o.Size
+			bu.StoredSize += info.Size()
+			if md != nil && md.ContentHash != "" {
+				bu.ChunkedSize += md.Size
+			}
+		}
+		usage.Buckets = append(usage.Buckets, bu)
+	}
+
+	if usage.Chunks, err = chunkstore.Stats(); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aidenappl/openbucket-go/metadata"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+const (
+	// maxObjectTags is the number of tags S3 allows on an object.
+	maxObjectTags = 10
+	maxTagKey     = 128
+	maxTagValue   = 256
+)
+
+// ErrInvalidTag is returned for tag sets S3 would reject.
+var ErrInvalidTag = errors.New("invalid tag")
+
+// ValidateTags checks a tag set given to an object: at most 10 tags with
+// unique, non-empty keys of up to 128 characters and values of up to 256.
+// Keys starting with aws: are reserved.
+func ValidateTags(tags []types.Tag) error {
+	if len(tags) > maxObjectTags {
+		return fmt.Errorf("%w: objects can have at most %d tags", ErrInvalidTag, maxObjectTags)
+	}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		switch {
+		case tag.Key == "" || utf8.RuneCountInString(tag.Key) > maxTagKey:
+			return fmt.Errorf("%w: tag keys must be between 1 and %d characters", ErrInvalidTag, maxTagKey)
+		case utf8.RuneCountInString(tag.Value) > maxTagValue:
+			return fmt.Errorf("%w: tag values must be at most %d characters", ErrInvalidTag, maxTagValue)
+		case strings.HasPrefix(strings.ToLower(tag.Key), "aws:"):
+			return fmt.Errorf("%w: tag keys starting with aws: are reserved", ErrInvalidTag)
+		case seen[tag.Key]:
+			return fmt.Errorf("%w: tag key %q is given more than once", ErrInvalidTag, tag.Key)
+		}
+		seen[tag.Key] = true
+	}
+	return nil
+}
+
+// PutObjectTagging replaces the tags of an object. An empty tag set removes
+// them.
+func PutObjectTagging(bucket, key string, tags []types.Tag) (*types.ObjectMetadata, error) {
+	if err := ValidateTags(tags); err != nil {
+		return nil, err
+	}
+
+	defer LockObject(bucket, key)()
+	md, err := metadata.Load(bucket, key)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s/%s", ErrNoSuchKey, bucket, key)
+	} else if err != nil {
+		return nil, err
+	}
+	md.Tags = tags
+	if err := metadata.Save(md); err != nil {
+		return nil, err
+	}
+	return md, nil
+}
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/auth"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// Errors returned by the identity management functions.
+var (
+	ErrNoSuchEntity        = errors.New("no such entity")
+	ErrEntityAlreadyExists = errors.New("entity already exists")
+	ErrDeleteConflict      = errors.New("delete conflict")
+	ErrInvalidInput        = errors.New("invalid input")
+)
+
+// identitiesMu serialises read-modify-write cycles on identities.xml and
+// authorizations.xml between concurrent admin requests.
+var identitiesMu sync.Mutex
+
+// identityNamePattern matches the IAM rules for user, group and policy names.
+var identityNamePattern = regexp.MustCompile(`^[A-Za-z0-9+=,.@_-]{1,64}$`)
+
+func validateIdentityName(kind, name string) error {
+	if !identityNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: invalid %s name %q", ErrInvalidInput, kind, name)
+	}
+	return nil
+}
+
+// ListUsers returns all users.
+func ListUsers() ([]types.User, error) {
+	identities, err := auth.LoadIdentities()
+	if err != nil {
+		return nil, err
+	}
+	return identities.Users, nil
+}
+
+// CreateUser adds a new user with no keys, groups or policies.
+func CreateUser(name, email string) (*types.User, error) {
+	if err := validateIdentityName("user", name); err != nil {
+		return nil, err
+	}
+
+	identitiesMu.Lock()
+	defer identitiesMu.Unlock()
+
+	identities, err := auth.LoadIdentities()
+	if err != nil {
+		return nil, err
+	}
+	if auth.FindUser(identities, name) != nil {
+		return nil, fmt.Errorf("%w: user %s", ErrEntityAlreadyExists, name)
+	}
+
+	user := types.User{Name: name, Email: email, DateCreated: time.Now()}
+	identities.Users = append(identities.Users, user)
+	if err := auth.SaveIdentities(identities); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DeleteUser removes a user. Users that still own access keys cannot be deleted.
+func DeleteUser(name string) error {
+	identitiesMu.Lock()
+	defer identitiesMu.Unlock()
+
+	identities, err := auth.LoadIdentities()
+	if err != nil {
+		return err
+	}
+	if auth.FindUser(identities, name) == nil {
+		return fmt.Errorf("%w: user %s", ErrNoSuchEntity, name)
+	}
+
+	keys, err := userKeys(name)
+	if err != nil {
+		return err
+	}
+	if len(keys) > 0 {
+		return fmt.Errorf("%w: user %s still owns %d access key(s)", ErrDeleteConflict, name, len(keys))
+	}
+
+	identities.Users = slices.DeleteFunc(identities.Users, func(u types.User) bool { return u.Name == name })
+	return auth.SaveIdentities(identities)
+}
+
+// CreateUserAccessKey generates a new access key owned by the user.
+func CreateUserAccessKey(name string) (*types.Authorization, error) {
+	identitiesMu.Lock()
+	defer identitiesMu.Unlock()
+
+	identities, err := auth.LoadIdentities()
+	if err != nil {
+		return nil, err
+	}
+	if auth.FindUser(identities, name) == nil {
+		return nil, fmt.Errorf("%w: user %s", ErrNoSuchEntity, name)
+	}
+
+	creds := GenerateCredentials()
+	creds.Name = name
+	creds.User = name
+	if err := SaveCredentials(creds); err != nil {
+		return nil, err
+	}
+	return creds, nil
+}
+
+// AttachUserKey transfers ownership of an existing access key to the user.
+func AttachUserKey(name, keyID string) error {
+	identitiesMu.Lock()
+	defer identitiesMu.Unlock()
+
+	identities, err := auth.LoadIdentities()
+	if err != nil {
+		return err
+	}
+	if auth.FindUser(identities, name) == nil {
+		return fmt.Errorf("%w: user %s", ErrNoSuchEntity, name)
+	}
+
+	authorizations, err := auth.LoadAuthorizations()
+	if err != nil {
+		return err
+	}
+	for i := range authorizations.Authorizations {
+		if authorizations.Authorizations[i].KeyID == keyID {
+			authorizations.Authorizations[i].User = name
+			return auth.SaveAuthorizations(authorizations)
+		}
+	}
+	return fmt.Errorf("%w: keyID %s", ErrNoSuchEntity, keyID)
+}
+
+// DeleteAccessKey removes an access key. When userName is set the key must
+// be owned by that user.
+func DeleteAccessKey(userName, keyID string) error {
+	identitiesMu.Lock()
+	defer identitiesMu.Unlock()
+
+	authorizations, err := auth.LoadAuthorizations()
+	if err != nil {
+		return err
+	}
+	before := len(authorizations.Authorizations)
+	authorizations.Authorizations = slices.DeleteFunc(authorizations.Authorizations, func(a types.Authorization) bool {
+		return a.KeyID == keyID && (userName == "" || a.User == userName)
+	})
+	if len(authorizations.Authorizations) == before {
+		return fmt.Errorf("%w: keyID %s", ErrNoSuchEntity, keyID)
+	}
+	return auth.SaveAuthorizations(authorizations)
+}
+
+// userKeys returns the IDs of the access keys owned by the user.
+func userKeys(name string) ([]string, error) {
+	authorizations, err := auth.LoadAuthorizations()
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, a := range authorizations.Authorizations {
+		if a.User == name {
+			keys = append(keys, a.KeyID)
+		}
+	}
+	return keys, nil
+}
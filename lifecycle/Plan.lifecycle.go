@@ -0,0 +1,169 @@
+package lifecycle
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/handler"
+	"github.com/aidenappl/openbucket-go/metadata"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// Kinds of lifecycle actions.
+const (
+	KindExpiration           = "Expiration"
+	KindAbortMultipartUpload = "AbortIncompleteMultipartUpload"
+)
+
+// Action is a change the lifecycle rules of a bucket call for.
+type Action struct {
+	Bucket   string
+	Key      string
+	UploadID string // for KindAbortMultipartUpload
+	Rule     string
+	Kind     string
+	Due      time.Time
+}
+
+// Plan returns the actions that are due at now for the objects of a bucket.
+// Each object is expired by the first enabled rule that matches it. Objects
+// under Object Lock are left alone until their lock expires. Multipart
+// uploads are aborted by the first rule whose prefix matches their key.
+//
+// Objects are stored with a single version and without delete markers, so
+// NoncurrentVersionExpiration and ExpiredObjectDeleteMarker have nothing to
+// act on; they are accepted so configurations written for S3 apply unchanged.
+func Plan(bucket string, cfg *types.LifecycleConfiguration, now time.Time) ([]Action, error) {
+	objects, err := handler.ListObjects(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []Action
+	for _, object := range objects {
+		if strings.HasSuffix(object.Key, "/") {
+			continue
+		}
+
+		// Objects written before metadata existed are judged by their file
+		md, err := metadata.Load(bucket, object.Key)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if md == nil {
+			md = &object
+		}
+		if handler.CheckObjectLock(md, false, now) != nil {
+			continue
+		}
+
+		for _, rule := range cfg.Rules {
+			if rule.Status != "Enabled" || rule.Expiration == nil || !matches(rule, md) {
+				continue
+			}
+			if due, ok := expirationDue(rule.Expiration, time.Time(md.LastModified)); ok && !now.Before(due) {
+				actions = append(actions, Action{Bucket: bucket, Key: object.Key, Rule: rule.ID, Kind: KindExpiration, Due: due})
+				break
+			}
+		}
+	}
+
+	uploads, err := handler.ListMultipartUploads(bucket)
+	if err != nil {
+		return nil, err
+	}
+	for _, upload := range uploads {
+		for _, rule := range cfg.Rules {
+			abort := rule.AbortIncompleteMultipartUpload
+			if rule.Status != "Enabled" || abort == nil || !strings.HasPrefix(upload.Key, rulePrefix(rule)) {
+				continue
+			}
+			due, _ := expirationDue(&types.LifecycleExpiration{Days: abort.DaysAfterInitiation}, time.Time(upload.Initiated))
+			if !now.Before(due) {
+				actions = append(actions, Action{Bucket: bucket, Key: upload.Key, UploadID: upload.UploadID, Rule: rule.ID, Kind: KindAbortMultipartUpload, Due: due})
+				break
+			}
+		}
+	}
+	return actions, nil
+}
+
+// rulePrefix returns the key prefix a rule is limited to. Tags cannot be
+// combined with AbortIncompleteMultipartUpload, and uploads have no size
+// yet, so the prefix is all that selects uploads.
+func rulePrefix(rule types.LifecycleRule) string {
+	switch f := rule.Filter; {
+	case rule.Prefix != nil:
+		return *rule.Prefix
+	case f != nil && f.Prefix != nil:
+		return *f.Prefix
+	case f != nil && f.And != nil:
+		return f.And.Prefix
+	}
+	return ""
+}
+
+// matches reports whether the rule's filter, or legacy prefix, selects the object.
+func matches(rule types.LifecycleRule, md *types.ObjectMetadata) bool {
+	if rule.Prefix != nil {
+		return strings.HasPrefix(md.Key, *rule.Prefix)
+	}
+	f := rule.Filter
+	switch {
+	case f == nil:
+		return true
+	case f.Prefix != nil:
+		return strings.HasPrefix(md.Key, *f.Prefix)
+	case f.Tag != nil:
+		return hasTag(md.Tags, *f.Tag)
+	case f.ObjectSizeGreaterThan != nil:
+		return md.Size > *f.ObjectSizeGreaterThan
+	case f.ObjectSizeLessThan != nil:
+		return md.Size < *f.ObjectSizeLessThan
+	case f.And != nil:
+		if !strings.HasPrefix(md.Key, f.And.Prefix) {
+			return false
+		}
+		for _, tag := range f.And.Tags {
+			if !hasTag(md.Tags, tag) {
+				return false
+			}
+		}
+		if f.And.ObjectSizeGreaterThan > 0 && md.Size <= f.And.ObjectSizeGreaterThan {
+			return false
+		}
+		if f.And.ObjectSizeLessThan > 0 && md.Size >= f.And.ObjectSizeLessThan {
+			return false
+		}
+	}
+	return true
+}
+
+func hasTag(tags []types.Tag, want types.Tag) bool {
+	for _, tag := range tags {
+		if tag == want {
+			return true
+		}
+	}
+	return false
+}
+
+// expirationDue returns when an object last modified at modified expires.
+// Like S3, expiry after a number of days is rounded up to the next midnight UTC.
+func expirationDue(e *types.LifecycleExpiration, modified time.Time) (time.Time, bool) {
+	switch {
+	case e.Date != "":
+		date, err := time.Parse(time.RFC3339, e.Date)
+		return date, err == nil
+	case e.Days > 0:
+		expires := modified.UTC().AddDate(0, 0, e.Days)
+		due := expires.Truncate(24 * time.Hour)
+		if due.Before(expires) {
+			due = due.Add(24 * time.Hour)
+		}
+		return due, true
+	}
+	return time.Time{}, false
+}
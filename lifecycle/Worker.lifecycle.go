@@ -0,0 +1,91 @@
+package lifecycle
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/bucketconfig"
+	"github.com/aidenappl/openbucket-go/handler"
+)
+
+// Start applies the lifecycle rules of every bucket now and then once per
+// interval, in the background. A zero interval disables the worker.
+func Start(interval time.Duration) {
+	if interval <= 0 {
+		log.Println("Lifecycle worker disabled")
+		return
+	}
+
+	go func() {
+		for {
+			if _, err := Run(time.Now(), nil, false); err != nil {
+				log.Println("Lifecycle run failed:", err)
+			}
+			time.Sleep(interval)
+		}
+	}()
+}
+
+// Run plans the lifecycle actions due at now for the given buckets, or every
+// bucket when buckets is empty, and applies them unless dryRun is set. Every
+// action is logged. Failures to apply an action are logged and skipped.
+func Run(now time.Time, buckets []string, dryRun bool) ([]Action, error) {
+	if len(buckets) == 0 {
+		all, err := handler.ListBuckets()
+		if err != nil {
+			return nil, err
+		}
+		for _, bucket := range *all {
+			buckets = append(buckets, bucket.Name)
+		}
+	}
+
+	var actions []Action
+	for _, bucket := range buckets {
+		cfg, err := bucketconfig.LoadLifecycle(bucket)
+		if err != nil {
+			return actions, fmt.Errorf("bucket %s: %w", bucket, err)
+		}
+		if cfg == nil {
+			continue
+		}
+
+		planned, err := Plan(bucket, cfg, now)
+		if err != nil {
+			return actions, fmt.Errorf("bucket %s: %w", bucket, err)
+		}
+		for _, action := range planned {
+			if err := apply(action, dryRun); err != nil {
+				log.Printf("Lifecycle: failed to apply %s to %s/%s: %v", action.Kind, action.Bucket, action.Key, err)
+			}
+		}
+		actions = append(actions, planned...)
+	}
+	return actions, nil
+}
+
+// apply carries out a planned action and logs it.
+func apply(action Action, dryRun bool) error {
+	switch action.Kind {
+	case KindAbortMultipartUpload:
+		if dryRun {
+			log.Printf("Lifecycle (dry run): would abort upload %s to %s/%s by rule %q", action.UploadID, action.Bucket, action.Key, action.Rule)
+			return nil
+		}
+		if err := handler.AbortMultipartUpload(action.Bucket, action.Key, action.UploadID); err != nil {
+			return err
+		}
+		log.Printf("Lifecycle: aborted upload %s to %s/%s by rule %q", action.UploadID, action.Bucket, action.Key, action.Rule)
+	default:
+		if dryRun {
+			log.Printf("Lifecycle (dry run): would expire %s/%s by rule %q", action.Bucket, action.Key, action.Rule)
+			return nil
+		}
+		if err := handler.DeleteObject(action.Bucket, action.Key, false); err != nil {
+			return err
+		}
+		log.Printf("Lifecycle: expired %s/%s by rule %q", action.Bucket, action.Key, action.Rule)
+	}
+	return nil
+}
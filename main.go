@@ -4,10 +4,16 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/aidenappl/openbucket-go/cli"
 	"github.com/aidenappl/openbucket-go/env"
+	"github.com/aidenappl/openbucket-go/events"
+	"github.com/aidenappl/openbucket-go/handler"
+	"github.com/aidenappl/openbucket-go/lifecycle"
+	"github.com/aidenappl/openbucket-go/metadata"
 	"github.com/aidenappl/openbucket-go/middleware"
+	"github.com/aidenappl/openbucket-go/replication"
 	"github.com/aidenappl/openbucket-go/routers"
 	"github.com/gorilla/mux"
 )
@@ -17,26 +23,102 @@ func startServer() {
 	// Create a new router
 	r := mux.NewRouter()
 
+	// Keys such as "a/../b" or "a//b" are valid and must not be cleaned
+	r.SkipClean(true)
+
 	// Middleware for handling request state (Host & Request ID)
 	r.Use(middleware.RequestState)
 
 	// Logging middleware for console output
 	r.Use(middleware.LoggingMiddleware)
 
-	r.HandleFunc("/", middleware.Authorized(routers.HandleListBuckets)).Methods(http.MethodGet)
+	// Access-Control-* headers from the bucket's CORS configuration
+	r.Use(middleware.CORS)
+
+	// Admin API for users, groups and identity policies
+	admin := r.PathPrefix("/_admin").Subrouter()
+	admin.HandleFunc("/users", middleware.AdminAuthorized("iam:ListUsers", "user", routers.HandleAdminListUsers)).Methods(http.MethodGet)
+	admin.HandleFunc("/users/{name}", middleware.AdminAuthorized("iam:CreateUser", "user", routers.HandleAdminCreateUser)).Methods(http.MethodPut)
+	admin.HandleFunc("/users/{name}", middleware.AdminAuthorized("iam:DeleteUser", "user", routers.HandleAdminDeleteUser)).Methods(http.MethodDelete)
+	admin.HandleFunc("/users/{name}/keys", middleware.AdminAuthorized("iam:CreateAccessKey", "user", routers.HandleAdminCreateAccessKey)).Methods(http.MethodPost)
+	admin.HandleFunc("/users/{name}/keys/{key_id}", middleware.AdminAuthorized("iam:DeleteAccessKey", "user", routers.HandleAdminDeleteAccessKey)).Methods(http.MethodDelete)
+	admin.HandleFunc("/users/{name}/groups/{group}", middleware.AdminAuthorized("iam:AddUserToGroup", "user", routers.HandleAdminAddUserToGroup)).Methods(http.MethodPut)
+	admin.HandleFunc("/users/{name}/groups/{group}", middleware.AdminAuthorized("iam:RemoveUserFromGroup", "user", routers.HandleAdminRemoveUserFromGroup)).Methods(http.MethodDelete)
+	admin.HandleFunc("/users/{name}/policies/{policy}", middleware.AdminAuthorized("iam:AttachUserPolicy", "user", routers.HandleAdminAttachUserPolicy)).Methods(http.MethodPut)
+	admin.HandleFunc("/users/{name}/policies/{policy}", middleware.AdminAuthorized("iam:DetachUserPolicy", "user", routers.HandleAdminDetachUserPolicy)).Methods(http.MethodDelete)
+	admin.HandleFunc("/groups", middleware.AdminAuthorized("iam:ListGroups", "group", routers.HandleAdminListGroups)).Methods(http.MethodGet)
+	admin.HandleFunc("/groups/{name}", middleware.AdminAuthorized("iam:CreateGroup", "group", routers.HandleAdminCreateGroup)).Methods(http.MethodPut)
+	admin.HandleFunc("/groups/{name}", middleware.AdminAuthorized("iam:DeleteGroup", "group", routers.HandleAdminDeleteGroup)).Methods(http.MethodDelete)
+	admin.HandleFunc("/groups/{name}/policies/{policy}", middleware.AdminAuthorized("iam:AttachGroupPolicy", "group", routers.HandleAdminAttachGroupPolicy)).Methods(http.MethodPut)
+	admin.HandleFunc("/groups/{name}/policies/{policy}", middleware.AdminAuthorized("iam:DetachGroupPolicy", "group", routers.HandleAdminDetachGroupPolicy)).Methods(http.MethodDelete)
+	admin.HandleFunc("/roles", middleware.AdminAuthorized("iam:ListRoles", "role", routers.HandleAdminListRoles)).Methods(http.MethodGet)
+	admin.HandleFunc("/roles/{name}", middleware.AdminAuthorized("iam:CreateRole", "role", routers.HandleAdminCreateRole)).Methods(http.MethodPut)
+	admin.HandleFunc("/roles/{name}", middleware.AdminAuthorized("iam:DeleteRole", "role", routers.HandleAdminDeleteRole)).Methods(http.MethodDelete)
+	admin.HandleFunc("/roles/{name}/policies/{policy}", middleware.AdminAuthorized("iam:AttachRolePolicy", "role", routers.HandleAdminAttachRolePolicy)).Methods(http.MethodPut)
+	admin.HandleFunc("/roles/{name}/policies/{policy}", middleware.AdminAuthorized("iam:DetachRolePolicy", "role", routers.HandleAdminDetachRolePolicy)).Methods(http.MethodDelete)
+	admin.HandleFunc("/policies", middleware.AdminAuthorized("iam:ListPolicies", "policy", routers.HandleAdminListPolicies)).Methods(http.MethodGet)
+	admin.HandleFunc("/policies/{name}", middleware.AdminAuthorized("iam:GetPolicy", "policy", routers.HandleAdminGetPolicy)).Methods(http.MethodGet)
+	admin.HandleFunc("/policies/{name}", middleware.AdminAuthorized("iam:CreatePolicy", "policy", routers.HandleAdminPutPolicy)).Methods(http.MethodPut)
+	admin.HandleFunc("/policies/{name}", middleware.AdminAuthorized("iam:DeletePolicy", "policy", routers.HandleAdminDeletePolicy)).Methods(http.MethodDelete)
+
+	// Admin API for the storage CLI commands, which run here while the server holds the metadata index
+	admin.HandleFunc("/buckets/{name}/objects", middleware.AdminAuthorized("admin:ListObjects", "bucket", routers.HandleAdminListObjects)).Methods(http.MethodGet)
+	admin.HandleFunc("/lifecycle", middleware.AdminAuthorized("admin:LifecycleDryRun", "bucket", routers.HandleAdminLifecycleDryRun)).Methods(http.MethodGet)
+	admin.HandleFunc("/stats", middleware.AdminAuthorized("admin:GetStorageStats", "storage", routers.HandleAdminStorageStats)).Methods(http.MethodGet)
+	admin.HandleFunc("/sse/rotate", middleware.AdminAuthorized("admin:RotateEncryptionKey", "storage", routers.HandleAdminRotateEncryptionKey)).Methods(http.MethodPost)
+	admin.HandleFunc("/reindex", middleware.AdminAuthorized("admin:Reindex", "storage", routers.HandleAdminReindex)).Methods(http.MethodPost)
+
+	// STS query API for temporary credentials
+	r.HandleFunc("/", middleware.STSAuthorized(routers.HandleSTS)).Methods(http.MethodPost)
+
+	// S3 API, authorized per action through the central route table
+	routers.RegisterRoutes(r)
+
+	// Unmatched requests get S3 errors too. Router middleware only runs for
+	// matched routes, so these set up the request state themselves.
+	r.MethodNotAllowedHandler = middleware.RequestState(http.HandlerFunc(routers.HandleMethodNotAllowed))
+	r.NotFoundHandler = middleware.RequestState(http.HandlerFunc(routers.HandleNotImplemented))
+
+	// Object content is stored in plain files or deduplicated in the chunk store
+	if env.StorageMode != handler.StorageFiles && env.StorageMode != handler.StorageDedup {
+		log.Fatal("Invalid STORAGE_MODE: ", env.StorageMode)
+	}
+
+	// Open the metadata index, which is built from the sidecar files on first run
+	if err := metadata.OpenIndex(); err != nil {
+		log.Fatal("Error opening metadata index: ", err)
+	}
+
+	// Apply bucket lifecycle rules in the background
+	interval, err := time.ParseDuration(env.LifecycleInterval)
+	if err != nil {
+		log.Fatal("Invalid LIFECYCLE_INTERVAL:", err)
+	}
+	lifecycle.Start(interval)
+
+	// Deliver bucket event notifications, including any queued before a restart
+	if _, err := events.LoadTargets(); err != nil {
+		log.Fatal("Error loading notification targets: ", err)
+	}
+	events.Start()
 
-	r.HandleFunc("/{bucket}", middleware.Authorized(routers.HandleBucket)).Methods(http.MethodGet)
-	r.HandleFunc("/{bucket}", middleware.Authorized(routers.HandleCreateBucket)).Methods(http.MethodPut)
-	r.HandleFunc("/{bucket}", middleware.Authorized(routers.HandleDeleteBucket)).Methods(http.MethodDelete)
+	// Replicate objects to the destinations of bucket replication rules
+	replication.Start()
 
-	r.HandleFunc("/{bucket}/{key:.*}", middleware.Authorized(routers.HandleHeadObject)).Methods(http.MethodHead)
-	r.HandleFunc("/{bucket}/{key:.*}", middleware.Authorized(routers.HandleDownload)).Methods(http.MethodGet)
-	r.HandleFunc("/{bucket}/{key:.*}", middleware.Authorized(routers.HandleDelete)).Methods(http.MethodDelete)
-	r.HandleFunc("/{bucket}/{key:.*}", middleware.Authorized(routers.HandleUpload)).Methods(http.MethodPut)
+	// Serve bucket websites on their own port when one is configured
+	if env.WebsitePort != "" {
+		website := middleware.RequestState(middleware.LoggingMiddleware(http.HandlerFunc(routers.HandleWebsite)))
+		go func() {
+			log.Println("✅ Website endpoint started at http://localhost:" + env.WebsitePort)
+			if err := http.ListenAndServe(":"+env.WebsitePort, website); err != nil {
+				log.Fatal("Error starting website endpoint:", err)
+			}
+		}()
+	}
 
 	// Start the server
 	log.Println("✅ Server started at http://localhost:" + env.Port)
-	err := http.ListenAndServe(":"+env.Port, r)
+	err = http.ListenAndServe(":"+env.Port, r)
 	if err != nil {
 		log.Fatal("Error starting server:", err)
 	}
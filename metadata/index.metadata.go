@@ -0,0 +1,304 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/env"
+	"github.com/aidenappl/openbucket-go/tools"
+	"github.com/aidenappl/openbucket-go/types"
+	bolt "go.etcd.io/bbolt"
+)
+
+// The index keeps the metadata of every object in env.MetadataIndexFile, in
+// one bbolt bucket per bucket keyed by object key, so listings are range
+// scans instead of directory walks. It is the source of truth; the .obmeta
+// sidecar files are kept next to the objects unless METADATA_SIDECARS is
+// false, and the index can be rebuilt from them with Reindex.
+var (
+	indexDB   *bolt.DB
+	indexErr  error
+	indexOnce sync.Once
+)
+
+var (
+	// ErrIndexInUse is returned when another process, usually the server,
+	// holds the metadata index.
+	ErrIndexInUse = errors.New("metadata index is in use by another process")
+	// ErrSidecarsDisabled is returned by Reindex when METADATA_SIDECARS is
+	// false, as the index would lose the metadata the sidecars do not hold.
+	ErrSidecarsDisabled = errors.New("metadata sidecars are turned off (METADATA_SIDECARS=false); reindexing would drop the metadata they do not hold")
+)
+
+var (
+	// objectsBucket is the top-level bbolt bucket holding one bucket per bucket.
+	objectsBucket = []byte("objects")
+	// stateBucket holds the completeKey marker, which a rebuild sets in the
+	// same transaction as the entries it indexes.
+	stateBucket = []byte("state")
+	completeKey = []byte("complete")
+)
+
+// index opens the metadata index on first use. An index that was never fully
+// built is built from the sidecar files, so existing data is picked up on
+// upgrade and an interrupted build is retried. Only one process can hold the
+// index at a time.
+func index() (*bolt.DB, error) {
+	indexOnce.Do(func() {
+		indexDB, indexErr = bolt.Open(env.MetadataIndexFile, 0644, &bolt.Options{Timeout: time.Second})
+		if errors.Is(indexErr, bolt.ErrTimeout) {
+			indexErr = fmt.Errorf("%w: %s", ErrIndexInUse, env.MetadataIndexFile)
+			return
+		} else if indexErr != nil {
+			indexErr = fmt.Errorf("failed to open metadata index: %v", indexErr)
+			return
+		}
+
+		complete := false
+		indexErr = indexDB.View(func(tx *bolt.Tx) error {
+			if b := tx.Bucket(stateBucket); b != nil {
+				complete = b.Get(completeKey) != nil
+			}
+			return nil
+		})
+		if indexErr == nil && !complete {
+			_, indexErr = rebuild(indexDB)
+		}
+	})
+	return indexDB, indexErr
+}
+
+// OpenIndex opens the metadata index ahead of its first use, so a locked or
+// unreadable index is reported early.
+func OpenIndex() error {
+	_, err := index()
+	return err
+}
+
+func indexGet(bucket, key string) (*types.ObjectMetadata, error) {
+	db, err := index()
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	err = db.View(func(tx *bolt.Tx) error {
+		if b := objects(tx, bucket); b != nil {
+			data = bytes.Clone(b.Get([]byte(key)))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata index: %v", err)
+	}
+	if data == nil {
+		return nil, fmt.Errorf("no metadata for %s/%s: %w", bucket, key, os.ErrNotExist)
+	}
+
+	var md types.ObjectMetadata
+	if err := xml.Unmarshal(data, &md); err != nil {
+		return nil, fmt.Errorf("failed to decode object metadata: %v", err)
+	}
+	// XML cannot hold every character a key may contain
+	md.Key = key
+	return &md, nil
+}
+
+func indexPut(md *types.ObjectMetadata, data []byte) error {
+	db, err := index()
+	if err != nil {
+		return err
+	}
+	return db.Update(func(tx *bolt.Tx) error {
+		return put(tx, md.Bucket, md.Key, data)
+	})
+}
+
+func indexDelete(bucket, key string) error {
+	db, err := index()
+	if err != nil {
+		return err
+	}
+	return db.Update(func(tx *bolt.Tx) error {
+		if b := objects(tx, bucket); b != nil {
+			return b.Delete([]byte(key))
+		}
+		return nil
+	})
+}
+
+// objects returns the bbolt bucket of a bucket, or nil when it has no
+// objects yet.
+func objects(tx *bolt.Tx, bucket string) *bolt.Bucket {
+	root := tx.Bucket(objectsBucket)
+	if root == nil {
+		return nil
+	}
+	return root.Bucket([]byte(bucket))
+}
+
+func put(tx *bolt.Tx, bucket, key string, data []byte) error {
+	root, err := tx.CreateBucketIfNotExists(objectsBucket)
+	if err != nil {
+		return err
+	}
+	b, err := root.CreateBucketIfNotExists([]byte(bucket))
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(key), data)
+}
+
+// List returns the metadata of the objects in bucket whose key starts with
+// prefix, in key order. Directory markers have keys ending in "/".
+func List(bucket, prefix string) ([]types.ObjectMetadata, error) {
+	db, err := index()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []types.ObjectMetadata
+	err = db.View(func(tx *bolt.Tx) error {
+		b := objects(tx, bucket)
+		if b == nil {
+			return nil
+		}
+		c := b.Cursor()
+		for k, v := c.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, v = c.Next() {
+			var md types.ObjectMetadata
+			if err := xml.Unmarshal(v, &md); err != nil {
+				return fmt.Errorf("failed to decode metadata of %s/%s: %v", bucket, k, err)
+			}
+			md.Key = string(k)
+			out = append(out, md)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Reindex rebuilds the metadata index from the sidecar files and returns the
+// number of entries indexed. Metadata saved while sidecars were turned off
+// is lost, so it is refused while they are off.
+func Reindex() (int, error) {
+	if !env.MetadataSidecars {
+		return 0, ErrSidecarsDisabled
+	}
+	db, err := index()
+	if err != nil {
+		return 0, err
+	}
+	return rebuild(db)
+}
+
+// rebuild replaces the content of the index with the sidecar files under
+// buckets/ and marks the index complete. Objects stored before keys were
+// encoded are first moved to their encoded path; the moves are not part of
+// the index transaction, so a failed rebuild leaves them done and the next
+// one picks them up where they are.
+func rebuild(db *bolt.DB) (int, error) {
+	err := walkSidecars(func(bucket, path string, md *types.ObjectMetadata) error {
+		return migrate(strings.TrimSuffix(path, ".obmeta"), md)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to rebuild metadata index: %v", err)
+	}
+
+	count := 0
+	err = db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(objectsBucket); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+			return err
+		}
+		if _, err := tx.CreateBucket(objectsBucket); err != nil {
+			return err
+		}
+
+		err := walkSidecars(func(bucket, path string, md *types.ObjectMetadata) error {
+			data, err := xml.MarshalIndent(md, "", "  ")
+			if err != nil {
+				return err
+			}
+			count++
+			return put(tx, bucket, md.Key, data)
+		})
+		if err != nil {
+			return err
+		}
+
+		state, err := tx.CreateBucketIfNotExists(stateBucket)
+		if err != nil {
+			return err
+		}
+		return state.Put(completeKey, []byte(time.Now().UTC().Format(time.RFC3339)))
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to rebuild metadata index: %v", err)
+	}
+	return count, nil
+}
+
+// walkSidecars calls fn with the metadata of every sidecar file under
+// buckets/, with its Bucket set to the bucket it was found in.
+func walkSidecars(fn func(bucket, path string, md *types.ObjectMetadata) error) error {
+	entries, err := os.ReadDir("buckets")
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read buckets directory: %v", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		bucket := entry.Name()
+		err := filepath.WalkDir(filepath.Join("buckets", bucket), func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(path, ".obmeta") {
+				return err
+			}
+			md, err := readSidecar(path)
+			if err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+			md.Bucket = bucket
+			return fn(bucket, path, md)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrate moves an object stored at filePath, and its sidecar, to the path
+// its key is encoded to. Objects already there are left alone. Before keys
+// were encoded, "a/b" was stored as the file b in the directory a.
+func migrate(filePath string, md *types.ObjectMetadata) error {
+	target := tools.ObjectPath(md.Bucket, md.Key)
+	if filePath == target {
+		return nil
+	}
+	if err := os.Rename(filePath, target); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to move %s: %v", filePath, err)
+	}
+	if err := os.Rename(filePath+".obmeta", Path(md.Bucket, md.Key)); err != nil {
+		return fmt.Errorf("failed to move %s.obmeta: %v", filePath, err)
+	}
+
+	// Remove the directories the move left empty
+	root := filepath.Join("buckets", md.Bucket)
+	for dir := filepath.Dir(filePath); dir != root && os.Remove(dir) == nil; dir = filepath.Dir(dir) {
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package metadata
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"unicode/utf8"
+
+	"github.com/aidenappl/openbucket-go/env"
+	"github.com/aidenappl/openbucket-go/tools"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// Path returns the location of the .obmeta file for an object, next to the
+// object's file.
+func Path(bucket, key string) string {
+	return tools.ObjectPath(bucket, key) + ".obmeta"
+}
+
+// Load reads the metadata of an object from the index. The returned error
+// wraps os.ErrNotExist when the object has no metadata.
+func Load(bucket, key string) (*types.ObjectMetadata, error) {
+	return indexGet(bucket, key)
+}
+
+// readSidecar reads an .obmeta file.
+func readSidecar(path string) (*types.ObjectMetadata, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var md types.ObjectMetadata
+	if err := xml.NewDecoder(f).Decode(&md); err != nil {
+		return nil, fmt.Errorf("failed to decode object metadata: %v", err)
+	}
+	if md.KeyBase64 != "" {
+		key, err := base64.StdEncoding.DecodeString(md.KeyBase64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode object key: %v", err)
+		}
+		md.Key = string(key)
+	}
+	return &md, nil
+}
+
+// xmlSafe reports whether XML can hold s unchanged.
+func xmlSafe(s string) bool {
+	for _, r := range s {
+		if r == utf8.RuneError || (r < 0x20 && r != '\t' && r != '\n' && r != '\r') || r == 0xFFFE || r == 0xFFFF {
+			return false
+		}
+	}
+	return true
+}
+
+// Save writes the metadata of an object to the index and its .obmeta file,
+// replacing any existing metadata. Keys XML cannot represent are written in
+// base64 as well, so the index can be rebuilt from the .obmeta files.
+func Save(md *types.ObjectMetadata) error {
+	md.KeyBase64 = ""
+	if !xmlSafe(md.Key) {
+		md.KeyBase64 = base64.StdEncoding.EncodeToString([]byte(md.Key))
+	}
+	data, err := xml.MarshalIndent(md, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling metadata to XML: %v", err)
+	}
+	if env.MetadataSidecars {
+		if err := tools.WriteFileAtomic(Path(md.Bucket, md.Key), data, 0644); err != nil {
+			return fmt.Errorf("error writing metadata file: %v", err)
+		}
+	}
+	if err := indexPut(md, data); err != nil {
+		return fmt.Errorf("error indexing metadata: %v", err)
+	}
+	return nil
+}
+
+// Delete removes the metadata of an object from the index and its .obmeta
+// file. Deleting missing metadata is not an error.
+func Delete(bucket, key string) error {
+	if err := indexDelete(bucket, key); err != nil {
+		return fmt.Errorf("error removing metadata from index: %v", err)
+	}
+	if err := os.Remove(Path(bucket, key)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error deleting metadata file: %v", err)
+	}
+	return nil
+}
+
+// EffectiveGrants returns the object's ACL. Objects written before per-object
+// grants existed only carry the owner and the Public flag, so their ACL is
+// derived from those.
+func EffectiveGrants(md *types.ObjectMetadata) []types.Grant {
+	if len(md.Grants) > 0 {
+		return md.Grants
+	}
+	grants := []types.Grant{{
+		Grantee:    types.Grantee{Type: types.GranteeCanonicalUser, ID: md.Owner.ID, DisplayName: md.Owner.DisplayName},
+		Permission: types.FULL_CONTROL,
+	}}
+	if md.Public {
+		grants = append(grants, types.Grant{
+			Grantee:    types.Grantee{Type: types.GranteeGroup, URI: types.AllUsersGroup},
+			Permission: types.READ,
+		})
+	}
+	return grants
+}
+
+// SetGrants replaces the object's ACL and keeps the Public flag in sync with it.
+func SetGrants(md *types.ObjectMetadata, grants []types.Grant) {
+	md.Grants = grants
+	md.Public = types.HasGrant(grants, "", types.READ)
+}
+
+// ContentSize returns the size of an object's content given the size of its
+// file. Encrypted, compressed and deduplicated objects are stored in files
+// that differ in size from their content, so the metadata's Size is used.
+func ContentSize(md *types.ObjectMetadata, fileSize int64) int64 {
+	if md.Encryption != nil || md.Compression != nil || md.ContentHash != "" {
+		return md.Size
+	}
+	return fileSize
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/aidenappl/openbucket-go/bucketconfig"
+	"github.com/aidenappl/openbucket-go/types"
+	"github.com/gorilla/mux"
+)
+
+// CORS adds Access-Control-* headers to cross-origin requests that match a
+// rule in the bucket's CORS configuration. Requests that match no rule are
+// served without them, leaving the browser to block the response. Preflight
+// OPTIONS requests are answered by routers.HandleCORSPreflight instead.
+func CORS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bucket := mux.Vars(r)["bucket"]
+		origin := r.Header.Get("Origin")
+		if bucket == "" || origin == "" || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cfg, err := bucketconfig.LoadCORS(bucket)
+		if err != nil {
+			log.Println("Error loading CORS configuration for bucket", bucket+":", err)
+		}
+		if cfg != nil {
+			w.Header().Add("Vary", "Origin")
+			if rule := bucketconfig.MatchCORS(cfg, origin, r.Method, nil); rule != nil {
+				SetCORSHeaders(w.Header(), rule, origin)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// SetCORSHeaders sets the headers shared by preflight and actual responses
+// for a request from origin allowed by rule. Rules allowing any origin answer
+// with "*" and no credentials; otherwise the origin is echoed back.
+func SetCORSHeaders(h http.Header, rule *types.CORSRule, origin string) {
+	if slices.Contains(rule.AllowedOrigins, "*") {
+		h.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		h.Set("Access-Control-Allow-Origin", origin)
+		h.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if len(rule.ExposeHeaders) > 0 {
+		h.Set("Access-Control-Expose-Headers", strings.Join(rule.ExposeHeaders, ", "))
+	}
+}
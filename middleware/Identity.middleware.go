@@ -0,0 +1,222 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/aidenappl/openbucket-go/auth"
+	"github.com/aidenappl/openbucket-go/aws"
+	"github.com/aidenappl/openbucket-go/policy"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/aidenappl/openbucket-go/sts"
+	"github.com/aidenappl/openbucket-go/types"
+	"github.com/gorilla/mux"
+)
+
+// evaluatePolicies combines the bucket policy with the identity policies of
+// the user owning keyID, or of the session when the request carries a
+// security token. An explicit Deny in either wins.
+func evaluatePolicies(r *http.Request, keyID, action, bucket, key string, bucketPolicy *types.BucketPolicy) policy.Decision {
+	req := policy.NewRequest(r, keyID, action, bucket, key)
+	return evaluateCaller(r, req, keyID, bucketPolicy)
+}
+
+// evaluateCaller evaluates a prepared request for the caller identified by
+// keyID, dispatching on whether it uses temporary credentials.
+func evaluateCaller(r *http.Request, req *policy.Request, keyID string, bucketPolicy *types.BucketPolicy) policy.Decision {
+	token := aws.SecurityToken(r)
+	if token == "" {
+		return evaluateRequest(req, keyID, bucketPolicy)
+	}
+
+	claims, err := sts.Verify(token, keyID)
+	if err != nil {
+		log.Println("Rejected session token for", keyID+":", err)
+		return policy.NoDecision
+	}
+	return evaluateSession(req, claims, bucketPolicy)
+}
+
+// evaluateSession evaluates a request made with temporary credentials. A
+// GetSessionToken session acts as its parent key, an assumed role as the role
+// with the role's policies. A session policy can only narrow access: anything
+// it does not allow is denied.
+func evaluateSession(req *policy.Request, claims *sts.Claims, bucketPolicy *types.BucketPolicy) policy.Decision {
+	var decision policy.Decision
+	if claims.Role == "" {
+		req.Principals = []string{claims.Parent}
+		req.Conditions["aws:userid"] = []string{claims.Parent}
+		decision = evaluateRequest(req, claims.Parent, bucketPolicy)
+	} else {
+		req.Principals = []string{policy.RoleARN(claims.Role), policy.AssumedRoleARN(claims.Role, claims.SessionName)}
+		req.Conditions["aws:userid"] = []string{claims.AccessKeyID + ":" + claims.SessionName}
+		if claims.Issuer != "" {
+			// Web identity sessions expose the token subject as <issuer>:sub
+			issuer := strings.TrimPrefix(claims.Issuer, "https://")
+			req.Conditions[issuer+":sub"] = []string{claims.Subject}
+		}
+		decision = policy.Combine(
+			policy.EvaluateBucketPolicy(bucketPolicy, req),
+			policy.EvaluateIdentityPolicies(loadRolePolicies(claims.Role), req),
+		)
+	}
+
+	if claims.Policy == "" || decision == policy.Deny {
+		return decision
+	}
+	sessionPolicy, err := policy.ParseIdentityPolicy([]byte(claims.Policy))
+	if err != nil {
+		log.Println("Invalid session policy in token for", claims.AccessKeyID+":", err)
+		return policy.Deny
+	}
+	if policy.EvaluateIdentityPolicies([]*types.IdentityPolicy{sessionPolicy}, req) != policy.Allow {
+		return policy.Deny
+	}
+	return decision
+}
+
+// resolveSession returns the identity a verified request acts as. Requests
+// with a security token act as the parent key of the session, or as the
+// assumed role, which must both still exist. Web identity sessions have no
+// parent key.
+func resolveSession(r *http.Request, keyID string) (*types.Authorization, error) {
+	token := aws.SecurityToken(r)
+	if token == "" {
+		return auth.CheckUserExists(keyID)
+	}
+
+	claims, err := sts.Verify(token, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Parent != "" {
+		parent, err := auth.CheckUserExists(claims.Parent)
+		if err != nil || parent == nil {
+			return nil, fmt.Errorf("parent access key %s of session %s no longer exists", claims.Parent, keyID)
+		}
+		if claims.Role == "" {
+			return parent, nil
+		}
+	}
+	if claims.Role == "" {
+		return nil, fmt.Errorf("session %s has neither a parent key nor a role", keyID)
+	}
+
+	identities, err := auth.LoadIdentities()
+	if err != nil {
+		return nil, err
+	}
+	if auth.FindRole(identities, claims.Role) == nil {
+		return nil, fmt.Errorf("role %s of session %s no longer exists", claims.Role, keyID)
+	}
+	return &types.Authorization{
+		Name:  claims.Role + "/" + claims.SessionName,
+		KeyID: policy.RoleARN(claims.Role),
+	}, nil
+}
+
+// evaluateRequest evaluates a prepared request against the bucket policy and
+// the caller's identity policies.
+func evaluateRequest(req *policy.Request, keyID string, bucketPolicy *types.BucketPolicy) policy.Decision {
+	identityPolicies, user := loadIdentityPolicies(keyID)
+	if user != nil {
+		req.Principals = append(req.Principals, policy.UserARN(user.Name))
+		req.Conditions["aws:username"] = []string{user.Name}
+	}
+
+	return policy.Combine(
+		policy.EvaluateBucketPolicy(bucketPolicy, req),
+		policy.EvaluateIdentityPolicies(identityPolicies, req),
+	)
+}
+
+// loadIdentityPolicies returns the parsed identity policies of the user
+// owning keyID.
+func loadIdentityPolicies(keyID string) ([]*types.IdentityPolicy, *types.User) {
+	user, identities, err := auth.UserForKey(keyID)
+	if err != nil {
+		log.Println("Error loading user for key", keyID+":", err)
+		return nil, nil
+	}
+	if user == nil {
+		return nil, nil
+	}
+
+	return parsePolicies(auth.EffectivePolicies(identities, user)), user
+}
+
+// loadRolePolicies returns the parsed identity policies attached to a role.
+func loadRolePolicies(roleName string) []*types.IdentityPolicy {
+	identities, err := auth.LoadIdentities()
+	if err != nil {
+		log.Println("Error loading identities for role", roleName+":", err)
+		return nil
+	}
+	role := auth.FindRole(identities, roleName)
+	if role == nil {
+		return nil
+	}
+	return parsePolicies(auth.RolePolicies(identities, role))
+}
+
+// parsePolicies parses identity policy documents. Invalid documents are
+// logged and skipped.
+func parsePolicies(managed []types.ManagedPolicy) []*types.IdentityPolicy {
+	var out []*types.IdentityPolicy
+	for _, mp := range managed {
+		p, err := policy.ParseIdentityPolicy([]byte(mp.Document))
+		if err != nil {
+			log.Printf("Skipping invalid identity policy %s: %v", mp.Name, err)
+			continue
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// AdminAuthorized protects the admin API. The caller must present a valid
+// signature and hold an identity policy allowing the action on the resource
+// of the given kind ("user", "group", "policy", "bucket", ...) named by the
+// {name} route variable.
+func AdminAuthorized(action, kind string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !validateAWSSignature(r) {
+			responder.SendError(w, r, responder.ErrAccessDenied, "")
+			log.Println("Invalid AWS signature for admin request", r.Method, r.URL.Path)
+			return
+		}
+
+		keyID, err := GetAccessKeyFromRequest(r)
+		if err != nil {
+			responder.SendError(w, r, responder.ErrAccessDenied, "")
+			log.Println("Unauthorized admin request: missing or invalid access key")
+			return
+		}
+
+		session, err := resolveSession(r, keyID)
+		if err != nil || session == nil {
+			responder.SendError(w, r, responder.ErrAccessDenied, "")
+			log.Println("Unauthorized admin request: unknown access key", keyID)
+			return
+		}
+
+		resource := "arn:aws:iam:::" + kind + "/*"
+		if name := mux.Vars(r)["name"]; name != "" {
+			resource = "arn:aws:iam:::" + kind + "/" + name
+		}
+
+		req := policy.NewRequest(r, keyID, action, "", "")
+		req.Resource = resource
+		if evaluateCaller(r, req, keyID, nil) != policy.Allow {
+			responder.SendError(w, r, responder.ErrAccessDenied, "")
+			log.Printf("Forbidden: %s is not allowed to %s on %s", keyID, action, resource)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), SessionContextKey, session)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	}
+}
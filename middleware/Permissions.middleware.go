@@ -2,19 +2,21 @@ package middleware
 
 import (
 	"context"
-	"encoding/xml"
 	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
-	"path/filepath"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/aidenappl/openbucket-go/auth"
 	"github.com/aidenappl/openbucket-go/aws"
 	"github.com/aidenappl/openbucket-go/env"
+	"github.com/aidenappl/openbucket-go/metadata"
+	"github.com/aidenappl/openbucket-go/policy"
 	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/aidenappl/openbucket-go/tools"
 	"github.com/aidenappl/openbucket-go/types"
 	"github.com/gorilla/mux"
 )
@@ -26,16 +28,25 @@ var MetadataContextKey contextKey = "metadata"
 var SessionContextKey contextKey = "session"
 
 // Authorized is a middleware that checks if the user is authorized to access the requested resource.
+// The S3 action being performed is taken from the name of the matched route (see routers.Routes).
 func Authorized(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// Browser POST uploads carry their credentials and object key in the
+		// form; POSTs to an object are multipart upload requests
+		if r.Method == http.MethodPost && RouteAction(r) == types.ActionPutObject && mux.Vars(r)["key"] == "" {
+			var ok bool
+			if r, ok = preparePostUpload(w, r); !ok {
+				return
+			}
+		}
+
 		vars := mux.Vars(r)
 		bucket, key := vars["bucket"], vars["key"]
-		requestID, hostID := GetRequestID(r), GetHostID(r)
 		ctx := r.Context()
 
 		// deny handles general access denial with logging
 		deny := func(msg string, err error) {
-			responder.SendAccessDeniedXML(w, &requestID, &hostID)
+			responder.SendError(w, r, responder.ErrAccessDenied, "")
 			if err != nil {
 				log.Printf("%s: %v", msg, err)
 			} else {
@@ -43,32 +54,84 @@ func Authorized(next http.HandlerFunc) http.HandlerFunc {
 			}
 		}
 
-		// Get the permissions for the bucket
-		perms, err := auth.LoadBucketPermissions(bucket)
-		if err != nil {
-			deny("Error loading permissions for bucket "+bucket, err)
+		// Resolve the action from the route table; unknown routes fail closed
+		action := RouteAction(r)
+		info, ok := types.LookupAction(action)
+		if !ok {
+			deny("No action registered for route "+r.Method+" "+r.URL.Path, nil)
 			return
 		}
-		if perms != nil {
-			ctx = context.WithValue(ctx, PermissionsContextKey, perms)
+
+		// Bucket names are used in file paths, so only valid names go further
+		if bucket != "" && !tools.ValidBucketName(bucket) {
+			responder.SendError(w, r, responder.ErrInvalidBucketName, "The specified bucket is not valid.")
+			return
 		}
 
-		// Deny access to metadata files directly
-		if strings.HasSuffix(key, ".obmeta") {
-			deny("Attempted to access metadata file directly: "+key, nil)
+		// Keys are encoded before they reach the file system, so any key S3
+		// allows can be stored; see tools.ObjectPath
+		if len(key) > tools.MaxKeyLength {
+			responder.SendError(w, r, responder.ErrKeyTooLong, "Your key is too long")
+			return
+		} else if !utf8.ValidString(key) {
+			responder.SendError(w, r, responder.ErrInvalidArgument, "Object keys must be UTF-8")
 			return
 		}
 
+		// Get the permissions for the bucket. A missing bucket is only
+		// acceptable when it is about to be created.
+		var perms *types.Bucket
+		if bucket != "" {
+			p, err := auth.LoadBucketPermissions(bucket)
+			if errors.Is(err, os.ErrNotExist) && action != types.ActionCreateBucket {
+				responder.SendError(w, r, responder.ErrNoSuchBucket, "")
+				return
+			} else if err != nil && action != types.ActionCreateBucket {
+				deny("Error loading permissions for bucket "+bucket, err)
+				return
+			}
+			perms = p
+		}
+		if perms != nil {
+			ctx = context.WithValue(ctx, PermissionsContextKey, perms)
+		}
+
 		// Load object metadata if available
-		if md, err := loadObjectMetadata(bucket, key); err == nil {
+		var err error
+		var md *types.ObjectMetadata
+		if bucket != "" && key != "" {
+			md, err = metadata.Load(bucket, key)
+		}
+		if err == nil && md != nil {
 			ctx = context.WithValue(ctx, MetadataContextKey, md)
-		} else if !errors.Is(err, os.ErrNotExist) {
+		} else if err != nil && !errors.Is(err, os.ErrNotExist) {
 			deny("Error loading object metadata", err)
 			return
 		}
 
-		// Do a fast path check for public access or ACL permissions
-		if isFastPathAllowed(perms, ctx, r) {
+		// Load the bucket policy, if one is attached
+		bucketPolicy, err := auth.LoadBucketPolicy(bucket)
+		if err != nil {
+			deny("Error loading bucket policy for bucket "+bucket, err)
+			return
+		}
+
+		// Evaluate the bucket policy for anonymous callers, and the bucket and
+		// identity policies for the key the request claims to be signed with.
+		// An explicit Deny wins over everything else, so it is checked before
+		// any fast path.
+		anonymousDecision := policy.EvaluateBucketPolicy(bucketPolicy, policy.NewRequest(r, "", string(action), bucket, key))
+		claimedDecision := anonymousDecision
+		if claimedKeyID, err := GetAccessKeyFromRequest(r); err == nil {
+			claimedDecision = evaluatePolicies(r, claimedKeyID, string(action), bucket, key, bucketPolicy)
+		}
+		if policy.Combine(anonymousDecision, claimedDecision) == policy.Deny {
+			deny("Explicitly denied by policy: "+string(action)+" on "+bucket, nil)
+			return
+		}
+
+		// Do a fast path check for public access
+		if anonymousDecision == policy.Allow || isFastPathAllowed(perms, md, info) {
 			next.ServeHTTP(w, r.WithContext(ctx))
 			return
 		}
@@ -86,99 +149,212 @@ func Authorized(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		// Check if the user exists
-		if bucket == "" {
-			session, err := auth.CheckUserExists(keyID)
-			if err != nil {
-				deny("Unauthorized: "+err.Error(), nil)
-				return
-			}
-			ctx = context.WithValue(ctx, SessionContextKey, session)
-		}
-
-		// Authorize against the bucket ACL
-		session, err := authoriseByACL(keyID, bucket, r)
-		if err != nil {
-			deny("Forbidden: "+err.Error(), nil)
+		// Check if the user exists, or resolve the identity of a session
+		session, err := resolveSession(r, keyID)
+		if err != nil || session == nil {
+			deny("Unauthorized: unknown access key "+keyID, err)
 			return
 		}
-		// Store the session in the context
 		ctx = context.WithValue(ctx, SessionContextKey, session)
 
+		// A policy Allow for the verified key grants access on its own,
+		// otherwise fall back to the bucket ACL
+		if claimedDecision != policy.Allow {
+			if err := authoriseByACL(session.KeyID, perms, md, info); err != nil {
+				deny("Forbidden: "+err.Error(), nil)
+				return
+			}
+		}
+
 		// serve the request with the updated context
 		next.ServeHTTP(w, r.WithContext(ctx))
 	}
 }
 
-// loadObjectMetadata loads the metadata for the specified object.
-func loadObjectMetadata(bucket, key string) (*types.ObjectMetadata, error) {
-	if bucket == "" || key == "" {
-		return nil, nil
+// RouteAction returns the S3 action of the matched route, which routers.Routes
+// registers as the route name.
+func RouteAction(r *http.Request) types.Action {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return ""
 	}
-	metaPath := filepath.Join("buckets", bucket, key+".obmeta")
-	f, err := os.Open(metaPath)
+	return types.Action(route.GetName())
+}
+
+// AllowedTo reports whether the caller of an authorized request may also
+// perform action on the request's bucket and key. It is used for actions that
+// qualify the one being served, such as s3:PutObjectTagging for an upload
+// that sets tags.
+func AllowedTo(r *http.Request, action types.Action) bool {
+	vars := mux.Vars(r)
+	return AllowedOn(r, action, vars["bucket"], vars["key"])
+}
+
+// AllowedOn reports whether the caller of an authorized request may perform
+// action on the given bucket and key, such as the source of a copy. Policies
+// and ACLs are evaluated as in Authorized.
+func AllowedOn(r *http.Request, action types.Action, bucket, key string) bool {
+	keyID, err := GetAccessKeyFromRequest(r)
 	if err != nil {
-		return nil, err
+		keyID = ""
 	}
-	defer f.Close()
+	return allowedFor(r, keyID, RetrieveSession(r), action, bucket, key)
+}
 
-	var md types.ObjectMetadata
-	if err := xml.NewDecoder(f).Decode(&md); err != nil {
-		return nil, err
+// KeyAllowedOn reports whether the user owning keyID may perform action on
+// the given bucket and key, as AllowedOn decides for a request signed with
+// the key. It is used by the CLI, which has no request of its own, so policy
+// conditions see a request without a source address, TLS or headers.
+func KeyAllowedOn(keyID string, action types.Action, bucket, key string) bool {
+	session, err := auth.CheckUserExists(keyID)
+	if err != nil || session == nil {
+		return false
 	}
-	return &md, nil
+	r, err := http.NewRequest(http.MethodGet, "/", nil)
+	if err != nil {
+		return false
+	}
+	return allowedFor(r, keyID, session, action, bucket, key)
 }
 
-// isFastPathAllowed checks if the request can be served without further permission checks
-func isFastPathAllowed(perms *types.Bucket, ctx context.Context, r *http.Request) bool {
+// allowedFor decides on action for the caller of r, who claims keyID, or no
+// key when empty, and has been verified as session, or not when nil.
+func allowedFor(r *http.Request, keyID string, session *types.Authorization, action types.Action, bucket, key string) bool {
+	info, ok := types.LookupAction(action)
+	if !ok {
+		return false
+	}
+	perms, md, bucketPolicy, ok := loadAccess(bucket, key)
+	if !ok {
+		return false
+	}
+
+	anonymousDecision := policy.EvaluateBucketPolicy(bucketPolicy, policy.NewRequest(r, "", string(action), bucket, key))
+	claimedDecision := anonymousDecision
+	if keyID != "" {
+		claimedDecision = evaluatePolicies(r, keyID, string(action), bucket, key, bucketPolicy)
+	}
+	if policy.Combine(anonymousDecision, claimedDecision) == policy.Deny {
+		return false
+	}
+	if anonymousDecision == policy.Allow || isFastPathAllowed(perms, md, info) {
+		return true
+	}
+
+	// Only a verified caller can be allowed by their own identity
+	if session == nil {
+		return false
+	}
+	return claimedDecision == policy.Allow || authoriseByACL(session.KeyID, perms, md, info) == nil
+}
+
+// AllowedAnonymously reports whether anyone may perform action on the given
+// bucket and key without credentials, because the bucket policy allows it or
+// the bucket or object ACL is public. It is used by the website endpoint,
+// which only serves anonymous requests.
+func AllowedAnonymously(r *http.Request, action types.Action, bucket, key string) bool {
+	info, ok := types.LookupAction(action)
+	if !ok {
+		return false
+	}
+	perms, md, bucketPolicy, ok := loadAccess(bucket, key)
+	if !ok {
+		return false
+	}
+
+	switch policy.EvaluateBucketPolicy(bucketPolicy, policy.NewRequest(r, "", string(action), bucket, key)) {
+	case policy.Deny:
+		return false
+	case policy.Allow:
+		return true
+	}
+	return isFastPathAllowed(perms, md, info)
+}
+
+// loadAccess loads what access to bucket and key is decided on: the bucket
+// permissions, the object's metadata if it exists and the bucket policy. It
+// logs failures and returns false.
+func loadAccess(bucket, key string) (*types.Bucket, *types.ObjectMetadata, *types.BucketPolicy, bool) {
+	perms, err := auth.LoadBucketPermissions(bucket)
+	if err != nil {
+		log.Println("Error loading permissions for bucket "+bucket+":", err)
+		return nil, nil, nil, false
+	}
+	var md *types.ObjectMetadata
+	if key != "" {
+		if md, err = metadata.Load(bucket, key); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Println("Error loading object metadata:", err)
+			return nil, nil, nil, false
+		}
+	}
+	bucketPolicy, err := auth.LoadBucketPolicy(bucket)
+	if err != nil {
+		log.Println("Error loading bucket policy for bucket "+bucket+":", err)
+		return nil, nil, nil, false
+	}
+	return perms, md, bucketPolicy, true
+}
+
+// isFastPathAllowed checks if the request can be served anonymously because
+// the bucket or object ACL grants the action's permission to everyone.
+func isFastPathAllowed(perms *types.Bucket, md *types.ObjectMetadata, info types.ActionInfo) bool {
+	if info.Perm == "" {
+		return false
+	}
 	if perms != nil {
-		if isWriteRoute(r) && types.IsBucketACLWrite(perms.ACL) {
+		if info.Perm == types.WRITE && types.IsBucketACLWrite(perms.ACL) {
 			return true
 		}
-		if isReadRoute(r) && types.IsBucketACLRead(perms.ACL) {
+		if info.Perm == types.READ && types.IsBucketACLRead(perms.ACL) {
 			return true
 		}
-	}
-
-	// Object‑level public flag
-	if mdRaw := r.Context().Value(MetadataContextKey); mdRaw != nil && isReadRoute(r) {
-		if md, ok := mdRaw.(*types.ObjectMetadata); ok && md.Public {
+		if types.HasGrant(perms.Grants, "", info.Perm) {
 			return true
 		}
 	}
 
+	// Object-level grants, including the legacy Public flag
+	if md != nil && info.Resource == types.ResourceObject && types.HasGrant(metadata.EffectiveGrants(md), "", info.Perm) {
+		return true
+	}
+
 	return false
 }
 
-// authoriseByACL checks the user's permissions against the bucket ACL
-func authoriseByACL(keyID, bucket string, r *http.Request) (*types.Authorization, error) {
-	session, err := auth.CheckUserExists(keyID)
-	if err != nil {
-		return nil, err
-	}
-	if session == nil {
-		return nil, fmt.Errorf("user with KEY_ID %s not found", keyID)
+// authoriseByACL checks the user's permissions against the bucket ACL and, for
+// object actions, the object's grants. The bucket owner implicitly holds
+// FULL_CONTROL on the bucket and everything in it.
+func authoriseByACL(keyID string, perms *types.Bucket, md *types.ObjectMetadata, info types.ActionInfo) error {
+	// Actions outside the ACL model only need an authenticated caller
+	if info.Perm == "" {
+		return nil
 	}
-
-	userACL, err := auth.CheckUserPermissions(keyID, bucket)
-	if err != nil {
-		return nil, err
+	if perms == nil {
+		return fmt.Errorf("no ACL available for %s", info.Action)
 	}
-	if userACL == nil {
-		return nil, fmt.Errorf("user %s has no ACL for bucket %s", keyID, bucket)
+	if perms.Owner.ID == keyID || types.HasGrant(perms.Grants, keyID, info.Perm) {
+		return nil
 	}
 
-	switch {
-	case isWriteRoute(r) && !types.IsWritePermission(userACL.Permission):
-		return nil, fmt.Errorf("user %s lacks write permission on bucket %s", keyID, bucket)
-	case isReadRoute(r) && !types.IsReadPermission(userACL.Permission):
-		return nil, fmt.Errorf("user %s lacks read permission on bucket %s", keyID, bucket)
+	if md != nil && info.Resource == types.ResourceObject {
+		if md.Owner.ID == keyID || types.HasGrant(metadata.EffectiveGrants(md), keyID, info.Perm) {
+			return nil
+		}
+		return fmt.Errorf("user %s lacks %s permission on object %s/%s for %s", keyID, info.Perm, perms.Name, md.Key, info.Action)
 	}
-	return session, nil
+	return fmt.Errorf("user %s lacks %s permission on bucket %s for %s", keyID, info.Perm, perms.Name, info.Action)
 }
 
-// GetAccessKeyFromRequest extracts the access key from the request's Authorization header.
+// GetAccessKeyFromRequest extracts the access key from the request's Authorization header,
+// from the query string of a SigV2 presigned request when SigV2 is enabled, or from the
+// verified policy of a browser POST upload.
 func GetAccessKeyFromRequest(r *http.Request) (string, error) {
+	if upload := RetrievePostUpload(r); upload != nil {
+		if upload.KeyID == "" {
+			return "", fmt.Errorf("POST upload has no policy signature")
+		}
+		return upload.KeyID, nil
+	}
 
 	if env.BypassPermissions {
 		authorizationHeader := r.Header.Get("Authorization")
@@ -188,6 +364,10 @@ func GetAccessKeyFromRequest(r *http.Request) (string, error) {
 		return authorizationHeader, nil
 	}
 
+	if env.SigV2Enabled && aws.IsSignatureV2(r) {
+		return aws.AccessKeyV2(r)
+	}
+
 	authorizationHeader := r.Header.Get("Authorization")
 	if authorizationHeader == "" {
 		return "", fmt.Errorf("authorization header is missing")
@@ -215,13 +395,21 @@ func GetAccessKeyFromRequest(r *http.Request) (string, error) {
 }
 
 // validateAWSSignature checks if the request has a valid AWS signature.
+// SigV2 signatures are only accepted when env.SigV2Enabled is set. Browser POST
+// uploads have already had their policy signature verified.
 func validateAWSSignature(r *http.Request) bool {
+	if upload := RetrievePostUpload(r); upload != nil {
+		return upload.KeyID != ""
+	}
+	if env.SigV2Enabled && aws.IsSignatureV2(r) {
+		return aws.ValidateSignatureV2(r)
+	}
 
 	authorizationHeader := r.Header.Get("Authorization")
 	dateHeader := r.Header.Get("X-Amz-Date")
 	amzContentSHA256 := r.Header.Get("X-Amz-Content-SHA256")
 
-	return aws.ValidateSignature(r, authorizationHeader, dateHeader, amzContentSHA256)
+	return aws.ValidateSignature(r, "s3", authorizationHeader, dateHeader, amzContentSHA256)
 }
 
 // RetrievePermissions retrieves the permissions from the request context.
@@ -277,23 +465,3 @@ func RetrieveSession(r *http.Request) *types.Authorization {
 	}
 	return session
 }
-
-// isReadRoute checks if the request method is a read operation (GET or HEAD).
-func isReadRoute(r *http.Request) bool {
-	switch r.Method {
-	case http.MethodGet, http.MethodHead:
-		return true
-	default:
-		return false
-	}
-}
-
-// isWriteRoute checks if the request method is a write operation (PUT, POST, DELETE).
-func isWriteRoute(r *http.Request) bool {
-	switch r.Method {
-	case http.MethodPut, http.MethodPost, http.MethodDelete:
-		return true
-	default:
-		return false
-	}
-}
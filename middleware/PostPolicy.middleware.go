@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/aidenappl/openbucket-go/aws"
+	"github.com/aidenappl/openbucket-go/env"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/gorilla/mux"
+)
+
+var PostUploadContextKey contextKey = "postUpload"
+
+const (
+	// maxPostFieldSize caps a single form field of a browser POST upload.
+	maxPostFieldSize = 64 * 1024
+	// maxPostFieldsSize caps all form fields before the file.
+	maxPostFieldsSize = 1024 * 1024
+)
+
+// PostUpload is a browser POST upload whose form fields have been read up to
+// the file field.
+type PostUpload struct {
+	KeyID    string            // verified signer of the policy, empty for anonymous uploads
+	Fields   map[string]string // form fields with lowercased names
+	File     io.Reader         // file contents, limited to the policy's content-length-range
+	FileName string
+}
+
+// preparePostUpload reads the form of a browser POST upload to /{bucket},
+// verifies its policy signature and conditions, and returns the request with
+// the object key in its route variables and the upload in its context. Form
+// fields after the file are ignored. The returned bool is false when an error
+// response has been written.
+func preparePostUpload(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	bucket := mux.Vars(r)["bucket"]
+	fail := func(e responder.APIError, message string, err error) (*http.Request, bool) {
+		responder.SendError(w, r, e, message)
+		log.Println("Rejected POST upload to bucket", bucket+":", message, err)
+		return nil, false
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return fail(responder.ErrMalformedPOSTRequest, "The body of your POST request is not well-formed multipart/form-data.", err)
+	}
+
+	upload := &PostUpload{Fields: map[string]string{}}
+	var total int64
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return fail(responder.ErrInvalidArgument, "POST requires exactly one file upload per request.", nil)
+		} else if err != nil {
+			return fail(responder.ErrMalformedPOSTRequest, "The body of your POST request is not well-formed multipart/form-data.", err)
+		}
+
+		name := strings.ToLower(part.FormName())
+		if name == "file" {
+			upload.File, upload.FileName = part, part.FileName()
+			break
+		}
+
+		value, err := io.ReadAll(io.LimitReader(part, maxPostFieldSize+1))
+		total += int64(len(value))
+		if err != nil || len(value) > maxPostFieldSize || total > maxPostFieldsSize {
+			return fail(responder.ErrMaxPostPreDataLengthExceeded, "Your POST request fields preceding the upload file were too large.", err)
+		}
+		upload.Fields[name] = string(value)
+	}
+
+	key := upload.Fields["key"]
+	if key == "" {
+		return fail(responder.ErrInvalidArgument, "Bucket POST must contain a field named 'key'.", nil)
+	}
+
+	// Uploads without a policy are anonymous and only succeed on buckets that
+	// allow anonymous writes
+	if upload.Fields["policy"] != "" {
+		if upload.Fields["signature"] != "" && !env.SigV2Enabled {
+			return fail(responder.ErrInvalidArgument, "Signature Version 2 is not enabled.", nil)
+		}
+		keyID, err := aws.ValidatePostPolicySignature(upload.Fields)
+		if err != nil {
+			return fail(responder.ErrSignatureDoesNotMatch, "The request signature we calculated does not match the signature you provided.", err)
+		}
+
+		policy, err := aws.ParsePostPolicy(upload.Fields["policy"])
+		if err != nil {
+			return fail(responder.ErrInvalidPolicyDocument, err.Error(), err)
+		}
+
+		// The bucket is part of the form as far as the policy is concerned
+		fields := map[string]string{}
+		for name, value := range upload.Fields {
+			fields[name] = value
+		}
+		fields["bucket"] = bucket
+		if err := aws.CheckPostPolicy(policy, fields); err != nil {
+			return fail(responder.ErrAccessDenied, "Invalid according to Policy: "+strings.TrimPrefix(err.Error(), aws.ErrPostPolicyCondition.Error()+": "), err)
+		}
+
+		upload.KeyID = keyID
+		upload.File = aws.LimitPostBody(policy, upload.File)
+
+		// Sessions are resolved from the security token like any signed request
+		if token := upload.Fields["x-amz-security-token"]; token != "" {
+			r.Header.Set("X-Amz-Security-Token", token)
+		}
+	}
+
+	if upload.FileName != "" {
+		key = strings.ReplaceAll(key, "${filename}", path.Base(upload.FileName))
+	}
+	r = mux.SetURLVars(r, map[string]string{"bucket": bucket, "key": key})
+	return r.WithContext(context.WithValue(r.Context(), PostUploadContextKey, upload)), true
+}
+
+// RetrievePostUpload retrieves the browser POST upload from the request
+// context, or nil for any other request.
+func RetrievePostUpload(r *http.Request) *PostUpload {
+	upload, _ := r.Context().Value(PostUploadContextKey).(*PostUpload)
+	return upload
+}
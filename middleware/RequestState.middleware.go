@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 
+	"github.com/aidenappl/openbucket-go/responder"
 	"github.com/google/uuid"
 )
 
@@ -22,6 +23,10 @@ func RequestState(next http.Handler) http.Handler {
 
 		r = r.WithContext(ctx)
 
+		// Every response carries the IDs, so clients can report them
+		w.Header().Set(responder.RequestIDHeader, requestID)
+		w.Header().Set(responder.HostIDHeader, hostID)
+
 		next.ServeHTTP(w, r)
 	})
 }
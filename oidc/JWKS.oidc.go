@@ -0,0 +1,193 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+const (
+	// jwksCacheTTL is how long fetched keys are trusted before refetching.
+	jwksCacheTTL = 10 * time.Minute
+	// jwksRefreshInterval limits refetches triggered by unknown key IDs.
+	jwksRefreshInterval = 30 * time.Second
+	// maxJWKSSize caps the size of a key set document.
+	maxJWKSSize = 1 << 20
+)
+
+// jwk is a single JSON Web Key. Only RSA and EC signing keys are used.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type cachedKeys struct {
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+}
+
+var (
+	keyCacheMu sync.Mutex
+	keyCache   = map[string]*cachedKeys{}
+	httpClient = &http.Client{Timeout: 10 * time.Second}
+)
+
+// publicKey returns the provider's signing key with the given key ID. Keys
+// are cached and refetched when they expire or an unknown kid shows up.
+func publicKey(p *types.OIDCProvider, kid string) (crypto.PublicKey, error) {
+	keyCacheMu.Lock()
+	defer keyCacheMu.Unlock()
+
+	cached := keyCache[p.Issuer]
+	stale := cached == nil || time.Since(cached.fetched) > jwksCacheTTL
+	if !stale {
+		if key := lookupKey(cached.keys, kid); key != nil {
+			return key, nil
+		}
+		stale = time.Since(cached.fetched) > jwksRefreshInterval
+	}
+	if stale {
+		keys, err := fetchKeys(p)
+		if err != nil {
+			return nil, err
+		}
+		cached = &cachedKeys{keys: keys, fetched: time.Now()}
+		keyCache[p.Issuer] = cached
+	}
+
+	if key := lookupKey(cached.keys, kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("no signing key with kid %q for issuer %s", kid, p.Issuer)
+}
+
+// lookupKey finds a key by ID. Tokens without a kid may only be used with
+// single-key sets.
+func lookupKey(keys map[string]crypto.PublicKey, kid string) crypto.PublicKey {
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key
+		}
+	}
+	return keys[kid]
+}
+
+// fetchKeys loads the provider's key set from its JWKS file or URL.
+func fetchKeys(p *types.OIDCProvider) (map[string]crypto.PublicKey, error) {
+	var data []byte
+	var err error
+	if p.JWKSFile != "" {
+		data, err = os.ReadFile(p.JWKSFile)
+	} else {
+		data, err = fetchURL(p.JWKSURL)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWKS for issuer %s: %v", p.Issuer, err)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS for issuer %s: %v", p.Issuer, err)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q for issuer %s: %v", k.Kid, p.Issuer, err)
+		}
+		if key != nil {
+			keys[k.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+func fetchURL(url string) ([]byte, error) {
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+}
+
+// publicKey converts the JWK to a Go public key. Unsupported key types are
+// skipped by returning nil.
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,49 @@
+package oidc
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aidenappl/openbucket-go/env"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// LoadProviders reads the OIDC provider configuration from env.OIDCConfigFile.
+// A missing file means no providers are trusted.
+func LoadProviders() (*types.OIDCProviders, error) {
+	data, err := os.ReadFile(env.OIDCConfigFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return &types.OIDCProviders{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read OIDC configuration: %v", err)
+	}
+
+	var providers types.OIDCProviders
+	if err := xml.Unmarshal(data, &providers); err != nil {
+		return nil, fmt.Errorf("failed to decode OIDC configuration: %v", err)
+	}
+	for _, p := range providers.Providers {
+		if p.Issuer == "" || (p.JWKSURL == "") == (p.JWKSFile == "") {
+			return nil, fmt.Errorf("OIDC provider %q needs an issuer and exactly one of JWKSURL or JWKSFile", p.Issuer)
+		}
+		if len(p.Audiences) == 0 {
+			return nil, fmt.Errorf("OIDC provider %q has no audience", p.Issuer)
+		}
+	}
+	return &providers, nil
+}
+
+// FindProvider returns the provider for the issuer, or nil if it is not
+// trusted. A trailing slash on either side is ignored.
+func FindProvider(providers *types.OIDCProviders, issuer string) *types.OIDCProvider {
+	issuer = strings.TrimSuffix(issuer, "/")
+	for i := range providers.Providers {
+		if strings.TrimSuffix(providers.Providers[i].Issuer, "/") == issuer {
+			return &providers.Providers[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package oidc
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// clockSkew is the leeway allowed when checking token times.
+const clockSkew = time.Minute
+
+// Errors returned by Verify. Expired tokens are reported separately so STS
+// can return ExpiredTokenException.
+var (
+	ErrInvalidToken = errors.New("invalid identity token")
+	ErrExpiredToken = errors.New("identity token has expired")
+)
+
+// Identity is the verified content of a web identity token.
+type Identity struct {
+	Provider *types.OIDCProvider
+	Issuer   string
+	Subject  string
+	Audience string // the configured audience the token was issued for
+	Claims   map[string]any
+}
+
+// Verify checks the signature, issuer, audience and validity period of a
+// JWT against the configured providers and returns its identity.
+func Verify(providers *types.OIDCProviders, rawToken string) (*Identity, error) {
+	parts := strings.Split(rawToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: not a JWT", ErrInvalidToken)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: bad header: %v", ErrInvalidToken, err)
+	}
+
+	claims := map[string]any{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: bad payload: %v", ErrInvalidToken, err)
+	}
+
+	// The issuer selects which keys to trust, so nothing else in the claims
+	// is looked at before the signature is verified
+	issuer, _ := claims["iss"].(string)
+	provider := FindProvider(providers, issuer)
+	if provider == nil {
+		return nil, fmt.Errorf("%w: untrusted issuer %q", ErrInvalidToken, issuer)
+	}
+
+	key, err := publicKey(provider, header.Kid)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: bad signature encoding", ErrInvalidToken)
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	now := time.Now()
+	exp, ok := numericDate(claims["exp"])
+	if !ok {
+		return nil, fmt.Errorf("%w: missing exp claim", ErrInvalidToken)
+	}
+	if now.After(exp.Add(clockSkew)) {
+		return nil, fmt.Errorf("%w: expired at %s", ErrExpiredToken, exp.UTC().Format(time.RFC3339))
+	}
+	if nbf, ok := numericDate(claims["nbf"]); ok && now.Add(clockSkew).Before(nbf) {
+		return nil, fmt.Errorf("%w: not valid before %s", ErrInvalidToken, nbf.UTC().Format(time.RFC3339))
+	}
+
+	id := &Identity{Provider: provider, Issuer: issuer, Claims: claims}
+	id.Subject, _ = claims["sub"].(string)
+	if id.Subject == "" {
+		return nil, fmt.Errorf("%w: missing sub claim", ErrInvalidToken)
+	}
+	for _, aud := range id.ClaimValues("aud") {
+		if slices.Contains(provider.Audiences, aud) {
+			id.Audience = aud
+			break
+		}
+	}
+	if id.Audience == "" {
+		return nil, fmt.Errorf("%w: audience is not accepted for issuer %s", ErrInvalidToken, issuer)
+	}
+	return id, nil
+}
+
+// ClaimValues returns a claim as strings. Array claims return every element.
+func (id *Identity) ClaimValues(name string) []string {
+	switch v := id.Claims[name].(type) {
+	case nil:
+		return nil
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, e := range v {
+			out = append(out, claimString(e))
+		}
+		return out
+	default:
+		return []string{claimString(v)}
+	}
+}
+
+func claimString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func decodeSegment(seg string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(seg, "="))
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+func numericDate(v any) (time.Time, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(f), 0), true
+}
+
+// verifySignature checks a JWS signature. Only asymmetric algorithms are
+// accepted; "none" and HMAC algorithms are rejected.
+func verifySignature(alg string, key crypto.PublicKey, signingInput string, signature []byte) error {
+	if len(alg) != 5 {
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	var hash crypto.Hash
+	switch alg[2:] {
+	case "256":
+		hash = crypto.SHA256
+	case "384":
+		hash = crypto.SHA384
+	case "512":
+		hash = crypto.SHA512
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+	h := hash.New()
+	h.Write([]byte(signingInput))
+	digest := h.Sum(nil)
+
+	switch alg[:2] {
+	case "RS", "PS":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type does not match algorithm %s", alg)
+		}
+		if alg[:2] == "PS" {
+			return rsa.VerifyPSS(pub, hash, digest, signature, nil)
+		}
+		return rsa.VerifyPKCS1v15(pub, hash, digest, signature)
+
+	case "ES":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type does not match algorithm %s", alg)
+		}
+		// Each ES algorithm is bound to one curve, e.g. ES256 to P-256
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		if want := map[crypto.Hash]int{crypto.SHA256: 32, crypto.SHA384: 48, crypto.SHA512: 66}[hash]; size != want {
+			return fmt.Errorf("curve %s cannot be used with %s", pub.Curve.Params().Name, alg)
+		}
+		if len(signature) != 2*size {
+			return fmt.Errorf("invalid signature length")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return fmt.Errorf("signature verification failed")
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported algorithm %q", alg)
+}
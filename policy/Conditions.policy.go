@@ -0,0 +1,294 @@
+package policy
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// compareFunc reports whether a request value satisfies a policy value.
+type compareFunc func(have, want string) (bool, error)
+
+// operator describes a condition operator in terms of a positive comparison,
+// optionally negated (e.g. StringNotEquals is the negation of StringEquals).
+// validate, when set, checks the policy values the operator is given.
+type operator struct {
+	compare  compareFunc
+	negate   bool
+	validate func(want string) error
+}
+
+var operators = map[string]operator{
+	"StringEquals":              {compare: stringEquals},
+	"StringNotEquals":           {compare: stringEquals, negate: true},
+	"StringEqualsIgnoreCase":    {compare: stringEqualsFold},
+	"StringNotEqualsIgnoreCase": {compare: stringEqualsFold, negate: true},
+	"StringLike":                {compare: stringLike},
+	"StringNotLike":             {compare: stringLike, negate: true},
+	"ArnEquals":                 {compare: stringEquals},
+	"ArnNotEquals":              {compare: stringEquals, negate: true},
+	"ArnLike":                   {compare: stringLike},
+	"ArnNotLike":                {compare: stringLike, negate: true},
+	"NumericEquals":             {compare: numericCompare(func(a, b float64) bool { return a == b }), validate: validNumber},
+	"NumericNotEquals":          {compare: numericCompare(func(a, b float64) bool { return a == b }), negate: true, validate: validNumber},
+	"NumericLessThan":           {compare: numericCompare(func(a, b float64) bool { return a < b }), validate: validNumber},
+	"NumericLessThanEquals":     {compare: numericCompare(func(a, b float64) bool { return a <= b }), validate: validNumber},
+	"NumericGreaterThan":        {compare: numericCompare(func(a, b float64) bool { return a > b }), validate: validNumber},
+	"NumericGreaterThanEquals":  {compare: numericCompare(func(a, b float64) bool { return a >= b }), validate: validNumber},
+	"DateEquals":                {compare: dateCompare(func(a, b time.Time) bool { return a.Equal(b) }), validate: validDate},
+	"DateNotEquals":             {compare: dateCompare(func(a, b time.Time) bool { return a.Equal(b) }), negate: true, validate: validDate},
+	"DateLessThan":              {compare: dateCompare(func(a, b time.Time) bool { return a.Before(b) }), validate: validDate},
+	"DateLessThanEquals":        {compare: dateCompare(func(a, b time.Time) bool { return !a.After(b) }), validate: validDate},
+	"DateGreaterThan":           {compare: dateCompare(func(a, b time.Time) bool { return a.After(b) }), validate: validDate},
+	"DateGreaterThanEquals":     {compare: dateCompare(func(a, b time.Time) bool { return !a.Before(b) }), validate: validDate},
+	"Bool":                      {compare: stringEqualsFold, validate: validBool},
+	"IpAddress":                 {compare: ipInRange, validate: validIP},
+	"NotIpAddress":              {compare: ipInRange, negate: true, validate: validIP},
+}
+
+// ValidateCondition checks that every operator in the condition block is
+// known and that every value given to it can be compared, so a condition
+// never fails to evaluate once the policy is stored.
+func ValidateCondition(cond types.PolicyCondition) error {
+	for name, keys := range cond {
+		op, qualifier, _ := splitOperator(name)
+		if qualifier != "" && qualifier != "ForAnyValue" && qualifier != "ForAllValues" {
+			return fmt.Errorf("unsupported condition qualifier %q", name)
+		}
+
+		validate := validBool
+		if op != "Null" {
+			o, ok := operators[op]
+			if !ok {
+				return fmt.Errorf("unsupported condition operator %q", name)
+			}
+			validate = o.validate
+		}
+		for key, want := range keys {
+			if len(want) == 0 {
+				return fmt.Errorf("%s condition on %s has no value", name, key)
+			}
+			if validate == nil {
+				continue
+			}
+			for _, w := range want {
+				if err := validate(w); err != nil {
+					return fmt.Errorf("%s condition on %s: %v", name, key, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// splitOperator strips the ForAnyValue:/ForAllValues: qualifier and the
+// IfExists suffix from an operator name.
+func splitOperator(name string) (op, qualifier string, ifExists bool) {
+	op = name
+	if i := strings.Index(op, ":"); i != -1 {
+		qualifier, op = op[:i], op[i+1:]
+	}
+	if strings.HasSuffix(op, "IfExists") {
+		op, ifExists = strings.TrimSuffix(op, "IfExists"), true
+	}
+	return op, qualifier, ifExists
+}
+
+// conditionsMatch reports whether every condition in the block holds. All
+// operators and all keys within an operator are ANDed together.
+func conditionsMatch(cond types.PolicyCondition, values map[string][]string) (bool, error) {
+	for name, keys := range cond {
+		op, qualifier, ifExists := splitOperator(name)
+		for key, want := range keys {
+			have, present := lookupCondition(values, key)
+
+			if op == "Null" {
+				// Null:true means the key must be absent
+				if len(want) == 0 {
+					return false, fmt.Errorf("Null condition on %s has no value", key)
+				}
+				if strings.EqualFold(want[0], "true") == present {
+					return false, nil
+				}
+				continue
+			}
+
+			o, ok := operators[op]
+			if !ok {
+				return false, fmt.Errorf("unsupported condition operator %q", name)
+			}
+
+			if !present {
+				if ifExists || o.negate || qualifier == "ForAllValues" {
+					continue
+				}
+				return false, nil
+			}
+
+			ok, err := evaluateOperator(o, qualifier, have, want)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+	}
+	return true, nil
+}
+
+func evaluateOperator(o operator, qualifier string, have, want []string) (bool, error) {
+	matchesAny := func(h string) (bool, error) {
+		for _, w := range want {
+			ok, err := o.compare(h, w)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	if qualifier == "ForAllValues" {
+		for _, h := range have {
+			ok, err := matchesAny(h)
+			if err != nil {
+				return false, err
+			}
+			if ok == o.negate {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+
+	// Single-valued keys and ForAnyValue: any request value may match
+	for _, h := range have {
+		ok, err := matchesAny(h)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return !o.negate, nil
+		}
+	}
+	return o.negate, nil
+}
+
+// lookupCondition finds a condition key; key names are case-insensitive.
+func lookupCondition(values map[string][]string, key string) ([]string, bool) {
+	if v, ok := values[key]; ok {
+		return v, true
+	}
+	for k, v := range values {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func stringEquals(have, want string) (bool, error) {
+	return have == want, nil
+}
+
+func stringEqualsFold(have, want string) (bool, error) {
+	return strings.EqualFold(have, want), nil
+}
+
+func stringLike(have, want string) (bool, error) {
+	return WildcardMatch(want, have), nil
+}
+
+func numericCompare(cmp func(a, b float64) bool) compareFunc {
+	return func(have, want string) (bool, error) {
+		a, err := strconv.ParseFloat(have, 64)
+		if err != nil {
+			return false, nil
+		}
+		b, err := strconv.ParseFloat(want, 64)
+		if err != nil {
+			return false, fmt.Errorf("invalid numeric value %q", want)
+		}
+		return cmp(a, b), nil
+	}
+}
+
+func dateCompare(cmp func(a, b time.Time) bool) compareFunc {
+	return func(have, want string) (bool, error) {
+		a, err := parseConditionDate(have)
+		if err != nil {
+			return false, nil
+		}
+		b, err := parseConditionDate(want)
+		if err != nil {
+			return false, fmt.Errorf("invalid date value %q", want)
+		}
+		return cmp(a, b), nil
+	}
+}
+
+// parseConditionDate accepts ISO 8601 dates, with or without a time, and epoch seconds.
+func parseConditionDate(v string) (time.Time, error) {
+	if epoch, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(epoch, 0).UTC(), nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05Z0700", "2006-01-02"} {
+		if t, err := time.Parse(layout, v); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised date %q", v)
+}
+
+func ipInRange(have, want string) (bool, error) {
+	ip := net.ParseIP(have)
+	if ip == nil {
+		return false, nil
+	}
+	if !strings.Contains(want, "/") {
+		other := net.ParseIP(want)
+		if other == nil {
+			return false, fmt.Errorf("invalid IP address %q", want)
+		}
+		return ip.Equal(other), nil
+	}
+	_, network, err := net.ParseCIDR(want)
+	if err != nil {
+		return false, fmt.Errorf("invalid CIDR %q", want)
+	}
+	return network.Contains(ip), nil
+}
+
+func validNumber(v string) error {
+	if _, err := strconv.ParseFloat(v, 64); err != nil {
+		return fmt.Errorf("invalid numeric value %q", v)
+	}
+	return nil
+}
+
+func validDate(v string) error {
+	_, err := parseConditionDate(v)
+	return err
+}
+
+func validBool(v string) error {
+	if !strings.EqualFold(v, "true") && !strings.EqualFold(v, "false") {
+		return fmt.Errorf("invalid boolean value %q", v)
+	}
+	return nil
+}
+
+func validIP(v string) error {
+	if strings.Contains(v, "/") {
+		if _, _, err := net.ParseCIDR(v); err != nil {
+			return fmt.Errorf("invalid CIDR %q", v)
+		}
+	} else if net.ParseIP(v) == nil {
+		return fmt.Errorf("invalid IP address %q", v)
+	}
+	return nil
+}
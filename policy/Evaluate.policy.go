@@ -0,0 +1,182 @@
+package policy
+
+import (
+	"log"
+	"strings"
+
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// Decision is the outcome of evaluating one or more policies.
+type Decision int
+
+const (
+	// NoDecision means no statement applied to the request.
+	NoDecision Decision = iota
+	// Allow means at least one Allow statement applied and no Deny did.
+	Allow
+	// Deny means an explicit Deny statement applied.
+	Deny
+)
+
+func (d Decision) String() string {
+	switch d {
+	case Allow:
+		return "Allow"
+	case Deny:
+		return "Deny"
+	default:
+		return "NoDecision"
+	}
+}
+
+// Combine merges decisions from several policy sources. An explicit Deny
+// always wins, followed by Allow.
+func Combine(decisions ...Decision) Decision {
+	out := NoDecision
+	for _, d := range decisions {
+		if d == Deny {
+			return Deny
+		}
+		if d == Allow {
+			out = Allow
+		}
+	}
+	return out
+}
+
+// EvaluateBucketPolicy evaluates a bucket policy. Statements must name the
+// caller in their Principal to apply.
+func EvaluateBucketPolicy(p *types.BucketPolicy, req *Request) Decision {
+	if p == nil {
+		return NoDecision
+	}
+	return evaluate(p.Statement, req, true)
+}
+
+// EvaluateIdentityPolicies evaluates the identity policies attached to the
+// caller. Their statements apply without a Principal check.
+func EvaluateIdentityPolicies(policies []*types.IdentityPolicy, req *Request) Decision {
+	decisions := make([]Decision, 0, len(policies))
+	for _, p := range policies {
+		decisions = append(decisions, evaluate(p.Statement, req, false))
+	}
+	return Combine(decisions...)
+}
+
+// EvaluateTrustPolicy evaluates a role trust policy. Statements must name the
+// caller in their Principal and apply regardless of the request resource.
+func EvaluateTrustPolicy(p *types.TrustPolicy, req *Request) Decision {
+	if p == nil {
+		return NoDecision
+	}
+	return evaluate(p.Statement, req, true)
+}
+
+// evaluate runs the statements against the request. When checkPrincipal is
+// false the statements are assumed to already be bound to the caller.
+func evaluate(statements []types.PolicyStatement, req *Request, checkPrincipal bool) Decision {
+	decision := NoDecision
+	for _, st := range statements {
+		if checkPrincipal && !principalMatches(st.Principal, req.Principals) {
+			continue
+		}
+		if !actionMatches(st, req.Action) || !resourceMatches(st, req.Resource) {
+			continue
+		}
+		// A condition that cannot be evaluated never grants access, but it
+		// must not lift a Deny either, so it fails closed
+		ok, err := conditionsMatch(st.Condition, req.Conditions)
+		if err != nil {
+			log.Printf("Policy statement %q has an invalid condition: %v", st.Sid, err)
+			ok = st.Effect == types.EffectDeny
+		}
+		if !ok {
+			continue
+		}
+
+		if st.Effect == types.EffectDeny {
+			return Deny
+		}
+		decision = Allow
+	}
+	return decision
+}
+
+// principalMatches reports whether the statement principal covers the caller.
+func principalMatches(p *types.PolicyPrincipal, principals []string) bool {
+	if p == nil {
+		return false
+	}
+	if p.Wildcard {
+		return true
+	}
+	for _, want := range p.AWS {
+		for _, have := range principals {
+			if WildcardMatch(want, have) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func actionMatches(st types.PolicyStatement, action string) bool {
+	if len(st.NotAction) > 0 {
+		return !matchAny(st.NotAction, action, true)
+	}
+	return matchAny(st.Action, action, true)
+}
+
+func resourceMatches(st types.PolicyStatement, resource string) bool {
+	// Only trust policies omit resources; they apply to the role itself
+	if len(st.Resource) == 0 && len(st.NotResource) == 0 {
+		return true
+	}
+	if len(st.NotResource) > 0 {
+		return !matchAny(st.NotResource, resource, false)
+	}
+	return matchAny(st.Resource, resource, false)
+}
+
+func matchAny(patterns []string, value string, foldCase bool) bool {
+	if foldCase {
+		value = strings.ToLower(value)
+	}
+	for _, p := range patterns {
+		if foldCase {
+			p = strings.ToLower(p)
+		}
+		if WildcardMatch(p, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// WildcardMatch matches value against a pattern where '*' matches any
+// sequence of characters and '?' matches exactly one.
+func WildcardMatch(pattern, value string) bool {
+	p, v := 0, 0
+	star, mark := -1, 0
+	for v < len(value) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == value[v]):
+			p++
+			v++
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, v
+			p++
+		case star != -1:
+			p = star + 1
+			mark++
+			v = mark
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
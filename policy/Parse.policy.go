@@ -0,0 +1,127 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// MaxBucketPolicySize is the largest policy document accepted, matching S3's 20 KB limit.
+const MaxBucketPolicySize = 20 * 1024
+
+// ParseBucketPolicy decodes and validates a bucket policy document for the
+// given bucket.
+func ParseBucketPolicy(data []byte, bucket string) (*types.BucketPolicy, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("policy document is empty")
+	}
+	if len(data) > MaxBucketPolicySize {
+		return nil, fmt.Errorf("policy document exceeds %d bytes", MaxBucketPolicySize)
+	}
+
+	var p types.BucketPolicy
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("invalid policy document: %v", err)
+	}
+
+	if err := validateStatements(p.Version, p.Statement, true, true); err != nil {
+		return nil, err
+	}
+
+	bucketARN := ResourceARN(bucket, "")
+	for i, st := range p.Statement {
+		for _, res := range append(append([]string{}, st.Resource...), st.NotResource...) {
+			if res != bucketARN && !strings.HasPrefix(res, bucketARN+"/") {
+				return nil, fmt.Errorf("statement %d: resource %q must refer to bucket %s", i, res, bucket)
+			}
+		}
+	}
+	return &p, nil
+}
+
+// ParseIdentityPolicy decodes and validates an identity policy document.
+func ParseIdentityPolicy(data []byte) (*types.IdentityPolicy, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("policy document is empty")
+	}
+
+	var p types.IdentityPolicy
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("invalid policy document: %v", err)
+	}
+
+	if err := validateStatements(p.Version, p.Statement, false, true); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ParseTrustPolicy decodes and validates a role trust policy. Statements must
+// name a principal and only cover sts:AssumeRole actions.
+func ParseTrustPolicy(data []byte) (*types.TrustPolicy, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("policy document is empty")
+	}
+
+	var p types.TrustPolicy
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("invalid policy document: %v", err)
+	}
+
+	if err := validateStatements(p.Version, p.Statement, true, false); err != nil {
+		return nil, err
+	}
+	for i, st := range p.Statement {
+		for _, action := range st.Action {
+			if !strings.HasPrefix(strings.ToLower(action), "sts:assumerole") && action != "*" && action != "sts:*" {
+				return nil, fmt.Errorf("statement %d: action %q is not allowed in a trust policy", i, action)
+			}
+		}
+	}
+	return &p, nil
+}
+
+// validateStatements checks the structure shared by all policy documents.
+// Trust policies are the only documents without resources.
+func validateStatements(version string, statements []types.PolicyStatement, requirePrincipal, requireResource bool) error {
+	if version != "" && version != types.PolicyVersion2012 && version != types.PolicyVersion2008 {
+		return fmt.Errorf("unsupported policy version %q", version)
+	}
+	if len(statements) == 0 {
+		return fmt.Errorf("policy has no statements")
+	}
+
+	for i, st := range statements {
+		if st.Effect != types.EffectAllow && st.Effect != types.EffectDeny {
+			return fmt.Errorf("statement %d: invalid effect %q", i, st.Effect)
+		}
+		if requirePrincipal && st.Principal == nil {
+			return fmt.Errorf("statement %d: missing principal", i)
+		}
+		if !requirePrincipal && st.Principal != nil {
+			return fmt.Errorf("statement %d: principal is not allowed here", i)
+		}
+		if (len(st.Action) == 0) == (len(st.NotAction) == 0) {
+			return fmt.Errorf("statement %d: exactly one of Action or NotAction is required", i)
+		}
+		if requireResource && (len(st.Resource) == 0) == (len(st.NotResource) == 0) {
+			return fmt.Errorf("statement %d: exactly one of Resource or NotResource is required", i)
+		}
+		if !requireResource && (len(st.Resource) > 0 || len(st.NotResource) > 0) {
+			return fmt.Errorf("statement %d: resource is not allowed here", i)
+		}
+		if err := ValidateCondition(st.Condition); err != nil {
+			return fmt.Errorf("statement %d: %v", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package policy
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/tools"
+)
+
+// Request describes a single authorization question put to the evaluator.
+type Request struct {
+	// Principals lists the identities the caller is known by (e.g. the access
+	// key ID). An empty list means the caller is anonymous.
+	Principals []string
+	Action     string
+	Resource   string
+	// Conditions holds the values of the condition keys for the request,
+	// such as aws:SourceIp or s3:prefix.
+	Conditions map[string][]string
+}
+
+// ResourceARN returns the S3 ARN for a bucket or an object in a bucket.
+func ResourceARN(bucket, key string) string {
+	if bucket == "" {
+		return "arn:aws:s3:::*"
+	}
+	if key == "" {
+		return "arn:aws:s3:::" + bucket
+	}
+	return "arn:aws:s3:::" + bucket + "/" + key
+}
+
+// UserARN returns the ARN a user is known by in policy principals.
+func UserARN(name string) string {
+	return "arn:aws:iam:::user/" + name
+}
+
+// GroupARN returns the ARN of a group.
+func GroupARN(name string) string {
+	return "arn:aws:iam:::group/" + name
+}
+
+// RoleARN returns the ARN of a role.
+func RoleARN(name string) string {
+	return "arn:aws:iam:::role/" + name
+}
+
+// AssumedRoleARN returns the ARN of a session created by assuming a role.
+func AssumedRoleARN(role, session string) string {
+	return "arn:aws:sts:::assumed-role/" + role + "/" + session
+}
+
+// PolicyARN returns the ARN of an identity policy.
+func PolicyARN(name string) string {
+	return "arn:aws:iam:::policy/" + name
+}
+
+// NewRequest builds an evaluation request for an HTTP request. keyID is empty
+// for anonymous callers.
+func NewRequest(r *http.Request, keyID, action, bucket, key string) *Request {
+	req := &Request{
+		Action:     action,
+		Resource:   ResourceARN(bucket, key),
+		Conditions: requestConditions(r),
+	}
+	if keyID != "" {
+		req.Principals = []string{keyID}
+		req.Conditions["aws:userid"] = []string{keyID}
+	}
+	return req
+}
+
+// requestConditions extracts the global and S3 condition keys from the request.
+func requestConditions(r *http.Request) map[string][]string {
+	now := time.Now().UTC()
+	c := map[string][]string{
+		"aws:CurrentTime":     {now.Format(time.RFC3339)},
+		"aws:EpochTime":       {strconv.FormatInt(now.Unix(), 10)},
+		"aws:SecureTransport": {strconv.FormatBool(tools.SecureTransport(r))},
+	}
+
+	if ip := sourceIP(r); ip != "" {
+		c["aws:SourceIp"] = []string{ip}
+	}
+	if ua := r.UserAgent(); ua != "" {
+		c["aws:UserAgent"] = []string{ua}
+	}
+	if ref := r.Referer(); ref != "" {
+		c["aws:Referer"] = []string{ref}
+	}
+
+	q := r.URL.Query()
+	for _, name := range []string{"prefix", "delimiter", "max-keys", "versionId"} {
+		if q.Has(name) {
+			c["s3:"+name] = []string{q.Get(name)}
+		}
+	}
+
+	// Every x-amz-* header is exposed as an s3: condition key (s3:x-amz-acl, ...)
+	for name, values := range r.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") {
+			c["s3:"+lower] = values
+		}
+	}
+	return c
+}
+
+// sourceIP returns the IP address of the direct peer.
+func sourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
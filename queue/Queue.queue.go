@@ -0,0 +1,194 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/tools"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxBackoff is the longest wait between two attempts at an entry.
+	maxBackoff = time.Hour
+	// pollInterval is how often a queue is checked for retries that are due.
+	pollInterval = time.Second
+)
+
+// Entry is a queued job. Entries of the same Group are handled strictly in
+// order: while one waits for a retry, the entries after it wait too.
+type Entry struct {
+	Group       string          `json:"group"`
+	Created     time.Time       `json:"created"`
+	Attempts    int             `json:"attempts"`
+	NextAttempt time.Time       `json:"nextAttempt"`
+	LastError   string          `json:"lastError,omitempty"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+// Queue is a durable job queue. Every entry is a JSON file in Dir until it is
+// handled or given up, so pending jobs survive restarts. File names start
+// with the creation time, which keeps the queue in order.
+type Queue struct {
+	// Name is used in log messages.
+	Name string
+	Dir  string
+	// MaxAge is how long an entry is retried before it is given up.
+	MaxAge time.Duration
+	// Handle carries out a job. Errors wrapped with Permanent give the entry
+	// up at once, others are retried with backoff.
+	Handle func(e *Entry) error
+	// Drop is called, if set, when an entry is given up.
+	Drop func(e *Entry, err error)
+
+	wake chan struct{}
+}
+
+// errPermanent marks errors that retrying will not fix.
+var errPermanent = errors.New("permanent failure")
+
+// Permanent wraps err so the entry that failed with it is not retried.
+func Permanent(err error) error {
+	return fmt.Errorf("%w: %w", errPermanent, err)
+}
+
+// New returns a queue stored in dir. It is not worked on until Start.
+func New(name, dir string, maxAge time.Duration, handle func(e *Entry) error) *Queue {
+	return &Queue{Name: name, Dir: dir, MaxAge: maxAge, Handle: handle, wake: make(chan struct{}, 1)}
+}
+
+// Start works through the queue in the background, starting with the entries
+// left over from before a restart.
+func (q *Queue) Start() {
+	go func() {
+		for {
+			q.drain(time.Now())
+			select {
+			case <-q.wake:
+			case <-time.After(pollInterval):
+			}
+		}
+	}()
+}
+
+// Push stores a new entry with payload encoded as JSON and wakes the worker.
+func (q *Queue) Push(group string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error encoding %s entry: %v", q.Name, err)
+	}
+	if err := os.MkdirAll(q.Dir, 0755); err != nil {
+		return fmt.Errorf("error creating %s queue: %v", q.Name, err)
+	}
+
+	now := time.Now().UTC()
+	e := &Entry{Group: group, Created: now, NextAttempt: now, Payload: data}
+	name := fmt.Sprintf("%020d-%s.json", now.UnixNano(), uuid.New().String())
+	if err := q.write(filepath.Join(q.Dir, name), e); err != nil {
+		return err
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// drain handles every entry that is due at now and not held up by an earlier
+// entry of its group.
+func (q *Queue) drain(now time.Time) {
+	files, err := os.ReadDir(q.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	} else if err != nil {
+		log.Printf("Error reading %s queue: %v", q.Name, err)
+		return
+	}
+
+	blocked := map[string]bool{}
+	for _, f := range files {
+		// Skip directories and the temporary files of atomic writes
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(q.Dir, f.Name())
+
+		e, err := read(path)
+		if err != nil {
+			log.Printf("Dropping unreadable %s entry %s: %v", q.Name, f.Name(), err)
+			os.Remove(path)
+			continue
+		}
+		if blocked[e.Group] {
+			continue
+		}
+		if e.NextAttempt.After(now) {
+			blocked[e.Group] = true
+			continue
+		}
+
+		err = q.Handle(e)
+		if err == nil {
+			if err := os.Remove(path); err != nil {
+				log.Printf("Error removing handled %s entry %s: %v", q.Name, f.Name(), err)
+			}
+			continue
+		}
+
+		e.Attempts++
+		if errors.Is(err, errPermanent) || now.Sub(e.Created) >= q.MaxAge {
+			log.Printf("Giving up %s entry for %s after %d attempts: %v", q.Name, e.Group, e.Attempts, err)
+			if q.Drop != nil {
+				q.Drop(e, err)
+			}
+			os.Remove(path)
+			continue
+		}
+		blocked[e.Group] = true
+		e.NextAttempt = now.Add(backoff(e.Attempts))
+		e.LastError = err.Error()
+		if err := q.write(path, e); err != nil {
+			log.Printf("Error updating %s entry %s: %v", q.Name, f.Name(), err)
+		}
+		log.Printf("The %s entry for %s failed (attempt %d), retrying at %s: %v", q.Name, e.Group, e.Attempts, e.NextAttempt.Format(time.RFC3339), err)
+	}
+}
+
+// backoff doubles the wait after every failed attempt, up to maxBackoff.
+func backoff(attempts int) time.Duration {
+	wait := time.Second
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxBackoff)
+}
+
+func read(path string) (*Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (q *Queue) write(path string, e *Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("error encoding %s entry: %v", q.Name, err)
+	}
+	if err := tools.WriteFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing %s entry: %v", q.Name, err)
+	}
+	return nil
+}
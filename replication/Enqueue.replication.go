@@ -0,0 +1,101 @@
+package replication
+
+import (
+	"log"
+
+	"github.com/aidenappl/openbucket-go/bucketconfig"
+	"github.com/aidenappl/openbucket-go/handler"
+	"github.com/aidenappl/openbucket-go/metadata"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// Operations a replication job carries out on the destination.
+const (
+	OpPut    = "put"
+	OpDelete = "delete"
+)
+
+// job is the payload of a replication queue entry.
+type job struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+	Op     string `json:"op"`
+	Rule   string `json:"rule"`
+	// ETag is the ETag of the object when it was queued. A job whose object
+	// has been replaced since does not report its status on the object.
+	ETag string `json:"etag,omitempty"`
+}
+
+// Enqueue queues md for replication when a replication rule of its bucket
+// selects it, and marks it PENDING. Replicas written by another server are
+// never replicated again. Failures are logged and never fail the request
+// that wrote the object.
+func Enqueue(md *types.ObjectMetadata) {
+	if md == nil || md.ReplicationStatus == types.ReplicationReplica {
+		return
+	}
+	rule := ruleFor(md.Bucket, md)
+	if rule == nil {
+		return
+	}
+
+	if !markPending(md) {
+		return
+	}
+	push(rule, job{Bucket: md.Bucket, Key: md.Key, Op: OpPut, Rule: rule.ID, ETag: md.ETag})
+}
+
+// markPending sets the replication status of md to PENDING in its stored
+// metadata, unless the object has been replaced or deleted since md was
+// saved, and reports whether it did.
+func markPending(md *types.ObjectMetadata) bool {
+	defer handler.LockObject(md.Bucket, md.Key)()
+	current, err := metadata.Load(md.Bucket, md.Key)
+	if err != nil || current.ETag != md.ETag {
+		return false
+	}
+
+	md.ReplicationStatus = types.ReplicationPending
+	current.ReplicationStatus = types.ReplicationPending
+	if err := metadata.Save(current); err != nil {
+		log.Println("Error marking", md.Bucket+"/"+md.Key, "for replication:", err)
+		return false
+	}
+	return true
+}
+
+// EnqueueDelete queues the deletion of bucket/key on the destination of the
+// rule that selects it, if that rule replicates deletes. md is the deleted
+// object, if known.
+func EnqueueDelete(bucket, key string, md *types.ObjectMetadata) {
+	if md == nil {
+		md = &types.ObjectMetadata{Bucket: bucket, Key: key}
+	}
+	rule := ruleFor(bucket, md)
+	if rule == nil || rule.DeleteMarkerReplication == nil || rule.DeleteMarkerReplication.Status != "Enabled" {
+		return
+	}
+	push(rule, job{Bucket: bucket, Key: key, Op: OpDelete, Rule: rule.ID})
+}
+
+// ruleFor returns the replication rule of bucket that selects md, if any.
+func ruleFor(bucket string, md *types.ObjectMetadata) *types.ReplicationRule {
+	cfg, err := bucketconfig.LoadReplication(bucket)
+	if err != nil {
+		log.Println("Error loading replication configuration for bucket", bucket+":", err)
+		return nil
+	}
+	if cfg == nil {
+		return nil
+	}
+	return bucketconfig.ReplicationRuleFor(cfg, md)
+}
+
+// push queues a job. Jobs for the same destination bucket are carried out in
+// order, so a delete never overtakes the upload before it.
+func push(rule *types.ReplicationRule, j job) {
+	group := rule.Destination.Endpoint + "/" + rule.Destination.Bucket
+	if err := jobs.Push(group, j); err != nil {
+		log.Println("Error queueing replication of", j.Bucket+"/"+j.Key+":", err)
+	}
+}
@@ -0,0 +1,213 @@
+package replication
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/aws"
+	"github.com/aidenappl/openbucket-go/bucketconfig"
+	"github.com/aidenappl/openbucket-go/env"
+	"github.com/aidenappl/openbucket-go/handler"
+	"github.com/aidenappl/openbucket-go/metadata"
+	"github.com/aidenappl/openbucket-go/queue"
+	"github.com/aidenappl/openbucket-go/sse"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+const (
+	// StatusHeader carries the replication status of an object. Requests
+	// that write replicas set it to REPLICA.
+	StatusHeader = "x-amz-replication-status"
+	// maxJobAge is how long a job is retried before the object is marked FAILED.
+	maxJobAge = 24 * time.Hour
+)
+
+var (
+	// jobs holds the pending replication jobs, grouped by destination.
+	jobs = newQueue()
+	// httpClient has no overall timeout, as objects may take long to send.
+	httpClient = &http.Client{Transport: &http.Transport{ResponseHeaderTimeout: time.Minute}}
+)
+
+func newQueue() *queue.Queue {
+	q := queue.New("replication", env.ReplicationQueueDir, maxJobAge, handle)
+	q.Drop = drop
+	return q
+}
+
+// Start replicates queued objects in the background, starting with those
+// left over from before a restart.
+func Start() {
+	jobs.Start()
+}
+
+// handle carries out a queued job with the rule it was queued for. Jobs
+// whose rule has been removed since are given up.
+func handle(e *queue.Entry) error {
+	var j job
+	if err := json.Unmarshal(e.Payload, &j); err != nil {
+		return queue.Permanent(err)
+	}
+
+	cfg, err := bucketconfig.LoadReplication(j.Bucket)
+	if err != nil {
+		return err
+	}
+	var rule *types.ReplicationRule
+	if cfg != nil {
+		rule = bucketconfig.FindReplicationRule(cfg, j.Rule)
+	}
+	if rule == nil {
+		return queue.Permanent(fmt.Errorf("replication rule %s of bucket %s no longer exists", j.Rule, j.Bucket))
+	}
+
+	if j.Op == OpDelete {
+		return replicateDelete(rule.Destination, j)
+	}
+	return replicatePut(rule.Destination, j)
+}
+
+// drop marks the object of a job that was given up as FAILED.
+func drop(e *queue.Entry, _ error) {
+	var j job
+	if json.Unmarshal(e.Payload, &j) == nil && j.Op == OpPut {
+		setStatus(j, types.ReplicationFailed)
+	}
+}
+
+// replicatePut copies the current content of the job's object to the
+// destination. Objects deleted since have nothing left to copy.
+func replicatePut(dest types.ReplicationDestination, j job) error {
+	md, err := metadata.Load(j.Bucket, j.Key)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if sse.IsCustomerKey(md.Encryption) {
+		return queue.Permanent(fmt.Errorf("objects encrypted with a customer-provided key cannot be replicated"))
+	}
+	if md.Size > handler.MaxObjectSize {
+		return queue.Permanent(fmt.Errorf("objects larger than %d bytes cannot be replicated", handler.MaxObjectSize))
+	}
+
+	content, err := handler.OpenContent(j.Bucket, j.Key, md, nil)
+	if errors.Is(err, handler.ErrNoSuchKey) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	req, err := newRequest(http.MethodPut, dest, j.Key, io.NopCloser(content))
+	if err != nil {
+		return queue.Permanent(err)
+	}
+	req.ContentLength = md.Size
+	if md.WebsiteRedirectLocation != "" {
+		req.Header.Set("x-amz-website-redirect-location", md.WebsiteRedirectLocation)
+	}
+	if len(md.Tags) > 0 {
+		req.Header.Set("x-amz-tagging", encodeTags(md.Tags))
+	}
+	if md.Size == 0 {
+		req.Body = http.NoBody
+	}
+	if err := send(req, dest, http.StatusOK); err != nil {
+		return err
+	}
+	if err := content.Err(); err != nil {
+		return err
+	}
+
+	// The object may have been replaced while it was sent; its own job
+	// reports on the newer content
+	j.ETag = md.ETag
+	setStatus(j, types.ReplicationCompleted)
+	return nil
+}
+
+// encodeTags encodes tags as the URL query parameters of x-amz-tagging.
+func encodeTags(tags []types.Tag) string {
+	pairs := make([]string, len(tags))
+	for i, tag := range tags {
+		pairs[i] = url.QueryEscape(tag.Key) + "=" + url.QueryEscape(tag.Value)
+	}
+	return strings.Join(pairs, "&")
+}
+
+// replicateDelete deletes the job's object on the destination. An object
+// that is already gone there counts as deleted.
+func replicateDelete(dest types.ReplicationDestination, j job) error {
+	req, err := newRequest(http.MethodDelete, dest, j.Key, nil)
+	if err != nil {
+		return queue.Permanent(err)
+	}
+	return send(req, dest, http.StatusNoContent, http.StatusNotFound)
+}
+
+// newRequest builds a request for key in the destination bucket, marked as
+// writing a replica so the destination does not replicate it further.
+func newRequest(method string, dest types.ReplicationDestination, key string, body io.ReadCloser) (*http.Request, error) {
+	u, err := url.Parse(dest.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	// Keys are used as is; joining the path would clean "a/../b"
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + dest.Bucket + "/" + key
+	u.RawPath = ""
+
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set(StatusHeader, types.ReplicationReplica)
+	return req, nil
+}
+
+// send signs req with the destination's credentials and sends it. Answers
+// other than the expected statuses are retried, as they usually come from a
+// destination that is down or still being set up.
+func send(req *http.Request, dest types.ReplicationDestination, expected ...int) error {
+	region := dest.Region
+	if region == "" {
+		region = aws.Region
+	}
+	aws.SignRequest(req, region, dest.AccessKeyID, dest.SecretAccessKey, "", time.Now())
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4*1024))
+
+	for _, status := range expected {
+		if resp.StatusCode == status {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s %s answered %s: %s", req.Method, req.URL.Redacted(), resp.Status, strings.TrimSpace(string(body)))
+}
+
+// setStatus records the replication status of the job's object, unless the
+// object has been replaced or deleted since the job was queued.
+func setStatus(j job, status string) {
+	defer handler.LockObject(j.Bucket, j.Key)()
+	md, err := metadata.Load(j.Bucket, j.Key)
+	if err != nil || md.ETag != j.ETag || md.ReplicationStatus == types.ReplicationReplica {
+		return
+	}
+	md.ReplicationStatus = status
+	if err := metadata.Save(md); err != nil {
+		log.Println("Error saving replication status of", j.Bucket+"/"+j.Key+":", err)
+	}
+}
@@ -0,0 +1,58 @@
+package responder
+
+import "net/http"
+
+// APIError is an S3 error code together with the HTTP status it is sent
+// with and the message used when a handler has nothing more specific to say.
+type APIError struct {
+	Code    string
+	Status  int
+	Message string
+}
+
+// The error codes answered by the S3 API. Handlers send them with SendError
+// so every code always goes out with the same status.
+var (
+	ErrAccessDenied                     = APIError{"AccessDenied", http.StatusForbidden, "Access Denied"}
+	ErrBadRequest                       = APIError{"BadRequest", http.StatusBadRequest, "Bad Request"}
+	ErrBucketAlreadyExists              = APIError{"BucketAlreadyExists", http.StatusConflict, "The requested bucket name is not available. Please select a different name and try again."}
+	ErrBucketAlreadyOwnedByYou          = APIError{"BucketAlreadyOwnedByYou", http.StatusConflict, "Your previous request to create the named bucket succeeded and you already own it."}
+	ErrCORSResponse                     = APIError{"CORSResponse", http.StatusForbidden, "CORS is not enabled for this bucket."}
+	ErrDeleteConflict                   = APIError{"DeleteConflict", http.StatusConflict, "The entity is still in use."}
+	ErrEntityAlreadyExists              = APIError{"EntityAlreadyExists", http.StatusConflict, "The entity already exists."}
+	ErrEntityTooLarge                   = APIError{"EntityTooLarge", http.StatusBadRequest, "Your proposed upload exceeds the maximum allowed object size."}
+	ErrEntityTooSmall                   = APIError{"EntityTooSmall", http.StatusBadRequest, "Your proposed upload is smaller than the minimum allowed object size."}
+	ErrIncompleteBody                   = APIError{"IncompleteBody", http.StatusBadRequest, "You did not provide the number of bytes specified by the Content-Length HTTP header."}
+	ErrInternalError                    = APIError{"InternalError", http.StatusInternalServerError, "We encountered an internal error. Please try again."}
+	ErrInvalidArgument                  = APIError{"InvalidArgument", http.StatusBadRequest, "Invalid Argument"}
+	ErrInvalidBucketName                = APIError{"InvalidBucketName", http.StatusBadRequest, "The specified bucket is not valid."}
+	ErrInvalidBucketState               = APIError{"InvalidBucketState", http.StatusConflict, "The request is not valid with the current state of the bucket."}
+	ErrInvalidInput                     = APIError{"InvalidInput", http.StatusBadRequest, "The request contains invalid input."}
+	ErrInvalidPolicyDocument            = APIError{"InvalidPolicyDocument", http.StatusBadRequest, "The content of the form does not meet the conditions specified in the policy document."}
+	ErrInvalidPart                      = APIError{"InvalidPart", http.StatusBadRequest, "One or more of the specified parts could not be found."}
+	ErrInvalidPartOrder                 = APIError{"InvalidPartOrder", http.StatusBadRequest, "The list of parts was not in ascending order."}
+	ErrInvalidRequest                   = APIError{"InvalidRequest", http.StatusBadRequest, "Invalid Request"}
+	ErrInvalidTag                       = APIError{"InvalidTag", http.StatusBadRequest, "The tag provided was not a valid tag."}
+	ErrKeyTooLong                       = APIError{"KeyTooLongError", http.StatusBadRequest, "Your key is too long"}
+	ErrMalformedPOSTRequest             = APIError{"MalformedPOSTRequest", http.StatusBadRequest, "The body of your POST request is not well-formed multipart/form-data."}
+	ErrMalformedPolicy                  = APIError{"MalformedPolicy", http.StatusBadRequest, "Policies must be valid JSON."}
+	ErrMalformedXML                     = APIError{"MalformedXML", http.StatusBadRequest, "The XML you provided was not well-formed or did not validate against our published schema."}
+	ErrMaxPostPreDataLengthExceeded     = APIError{"MaxPostPreDataLengthExceeded", http.StatusBadRequest, "Your POST request fields preceding the upload file were too large."}
+	ErrMethodNotAllowed                 = APIError{"MethodNotAllowed", http.StatusMethodNotAllowed, "The specified method is not allowed against this resource."}
+	ErrMissingContentLength             = APIError{"MissingContentLength", http.StatusLengthRequired, "You must provide the Content-Length HTTP header."}
+	ErrNoSuchBucket                     = APIError{"NoSuchBucket", http.StatusNotFound, "The specified bucket does not exist"}
+	ErrNoSuchBucketPolicy               = APIError{"NoSuchBucketPolicy", http.StatusNotFound, "The bucket policy does not exist"}
+	ErrNoSuchCORSConfiguration          = APIError{"NoSuchCORSConfiguration", http.StatusNotFound, "The CORS configuration does not exist"}
+	ErrNoSuchCompressionConfiguration   = APIError{"NoSuchCompressionConfiguration", http.StatusNotFound, "The compression configuration does not exist"}
+	ErrNoSuchEntity                     = APIError{"NoSuchEntity", http.StatusNotFound, "The entity does not exist."}
+	ErrNoSuchKey                        = APIError{"NoSuchKey", http.StatusNotFound, "The specified key does not exist."}
+	ErrNoSuchLifecycleConfiguration     = APIError{"NoSuchLifecycleConfiguration", http.StatusNotFound, "The lifecycle configuration does not exist"}
+	ErrNoSuchObjectLockConfiguration    = APIError{"NoSuchObjectLockConfiguration", http.StatusNotFound, "The specified object does not have an ObjectLock configuration"}
+	ErrNoSuchUpload                     = APIError{"NoSuchUpload", http.StatusNotFound, "The specified multipart upload does not exist."}
+	ErrNoSuchWebsiteConfiguration       = APIError{"NoSuchWebsiteConfiguration", http.StatusNotFound, "The specified bucket does not have a website configuration"}
+	ErrNotImplemented                   = APIError{"NotImplemented", http.StatusNotImplemented, "A header you provided implies functionality that is not implemented"}
+	ErrObjectLockConfigurationNotFound  = APIError{"ObjectLockConfigurationNotFoundError", http.StatusNotFound, "Object Lock configuration does not exist for this bucket"}
+	ErrReplicationConfigurationNotFound = APIError{"ReplicationConfigurationNotFoundError", http.StatusNotFound, "The replication configuration was not found"}
+	ErrSignatureDoesNotMatch            = APIError{"SignatureDoesNotMatch", http.StatusForbidden, "The request signature we calculated does not match the signature you provided."}
+	ErrSSEConfigurationNotFound         = APIError{"ServerSideEncryptionConfigurationNotFoundError", http.StatusNotFound, "The server side encryption configuration was not found"}
+)
@@ -0,0 +1,32 @@
+package responder
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+)
+
+// SendHTMLError answers r with e as an HTML page, the way website endpoints
+// report errors to browsers. An empty message sends the default message of
+// the code.
+func SendHTMLError(w http.ResponseWriter, r *http.Request, e APIError, message string) {
+	if message == "" {
+		message = e.Message
+	}
+	title := fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status))
+
+	var page strings.Builder
+	fmt.Fprintf(&page, "<html>\n<head><title>%s</title></head>\n<body>\n<h1>%s</h1>\n<ul>\n", title, title)
+	fmt.Fprintf(&page, "<li>Code: %s</li>\n", html.EscapeString(e.Code))
+	fmt.Fprintf(&page, "<li>Message: %s</li>\n", html.EscapeString(message))
+	fmt.Fprintf(&page, "<li>RequestId: %s</li>\n", html.EscapeString(w.Header().Get(RequestIDHeader)))
+	fmt.Fprintf(&page, "<li>HostId: %s</li>\n", html.EscapeString(w.Header().Get(HostIDHeader)))
+	page.WriteString("</ul>\n<hr/>\n</body>\n</html>\n")
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(e.Status)
+	if r == nil || r.Method != http.MethodHead {
+		w.Write([]byte(page.String()))
+	}
+}
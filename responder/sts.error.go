@@ -0,0 +1,33 @@
+package responder
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// SendSTSError writes an error in the STS query API format. Client errors
+// are of type Sender, everything else of type Receiver.
+func SendSTSError(w http.ResponseWriter, statusCode int, code, message, requestId string) {
+	errorType := "Sender"
+	if statusCode >= 500 {
+		errorType = "Receiver"
+	}
+
+	xmlData, err := xml.MarshalIndent(types.STSErrorResponse{
+		Xmlns:     types.STSNamespace,
+		Type:      errorType,
+		Code:      code,
+		Message:   message,
+		RequestID: requestId,
+	}, "", "  ")
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(statusCode)
+	w.Write(xmlData)
+}
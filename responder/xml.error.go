@@ -5,42 +5,46 @@ import (
 	"net/http"
 )
 
+// The headers carrying the IDs of a request, set by middleware.RequestState
+// on every response.
+const (
+	RequestIDHeader = "x-amz-request-id"
+	HostIDHeader    = "x-amz-id-2"
+)
+
 type ErrorResponse struct {
 	XMLName   xml.Name `xml:"Error"`
 	Code      string   `xml:"Code"`
 	Message   string   `xml:"Message"`
+	Resource  string   `xml:"Resource,omitempty"`
 	RequestId string   `xml:"RequestId"`
 	HostId    string   `xml:"HostId"`
 }
 
-func SendXML(w http.ResponseWriter, statusCode int, code, message, requestId, hostId string) {
-
-	w.Header().Set("Content-Type", "application/xml")
-	w.WriteHeader(statusCode)
+// SendError answers r with e. An empty message sends the default message of
+// the code. The request and host IDs are taken from the response headers.
+func SendError(w http.ResponseWriter, r *http.Request, e APIError, message string) {
+	if message == "" {
+		message = e.Message
+	}
 
 	errorResp := ErrorResponse{
-		Code:      code,
+		Code:      e.Code,
 		Message:   message,
-		RequestId: requestId,
-		HostId:    hostId,
+		RequestId: w.Header().Get(RequestIDHeader),
+		HostId:    w.Header().Get(HostIDHeader),
+	}
+	if r != nil {
+		errorResp.Resource = r.URL.Path
 	}
 
 	xmlData, err := xml.MarshalIndent(errorResp, "", "  ")
 	if err != nil {
-
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(e.Status)
 	w.Write(xmlData)
 }
-
-func SendAccessDeniedXML(w http.ResponseWriter, requestID *string, hostID *string) {
-	if requestID == nil {
-		requestID = new(string)
-	}
-	if hostID == nil {
-		hostID = new(string)
-	}
-	SendXML(w, http.StatusForbidden, "AccessDenied", "Access Denied", *requestID, *hostID)
-}
@@ -0,0 +1,244 @@
+package routers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/aidenappl/openbucket-go/handler"
+	"github.com/aidenappl/openbucket-go/metadata"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/gorilla/mux"
+)
+
+// accessKeyResponse is returned when a new access key is created for a user.
+type accessKeyResponse struct {
+	User      string `json:"user"`
+	KeyID     string `json:"key_id"`
+	SecretKey string `json:"secret_key"`
+}
+
+// createUserRequest is the optional body of PUT /_admin/users/{name}
+type createUserRequest struct {
+	Email string `json:"email"`
+}
+
+// HandleAdminListUsers handles GET /_admin/users
+func HandleAdminListUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := handler.ListUsers()
+	sendAdminResult(w, r, http.StatusOK, users, err)
+}
+
+// HandleAdminCreateUser handles PUT /_admin/users/{name}
+func HandleAdminCreateUser(w http.ResponseWriter, r *http.Request) {
+	var body createUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		sendAdminError(w, r, handler.ErrInvalidInput)
+		return
+	}
+	user, err := handler.CreateUser(mux.Vars(r)["name"], body.Email)
+	sendAdminResult(w, r, http.StatusCreated, user, err)
+}
+
+// HandleAdminDeleteUser handles DELETE /_admin/users/{name}
+func HandleAdminDeleteUser(w http.ResponseWriter, r *http.Request) {
+	err := handler.DeleteUser(mux.Vars(r)["name"])
+	sendAdminResult(w, r, http.StatusNoContent, nil, err)
+}
+
+// HandleAdminCreateAccessKey handles POST /_admin/users/{name}/keys
+func HandleAdminCreateAccessKey(w http.ResponseWriter, r *http.Request) {
+	creds, err := handler.CreateUserAccessKey(mux.Vars(r)["name"])
+	if err != nil {
+		sendAdminError(w, r, err)
+		return
+	}
+	sendAdminResult(w, r, http.StatusCreated, accessKeyResponse{
+		User:      creds.User,
+		KeyID:     creds.KeyID,
+		SecretKey: creds.SecretKey,
+	}, nil)
+}
+
+// HandleAdminDeleteAccessKey handles DELETE /_admin/users/{name}/keys/{key_id}
+func HandleAdminDeleteAccessKey(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	err := handler.DeleteAccessKey(vars["name"], vars["key_id"])
+	sendAdminResult(w, r, http.StatusNoContent, nil, err)
+}
+
+// HandleAdminAddUserToGroup handles PUT /_admin/users/{name}/groups/{group}
+func HandleAdminAddUserToGroup(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	err := handler.AddUserToGroup(vars["name"], vars["group"])
+	sendAdminResult(w, r, http.StatusNoContent, nil, err)
+}
+
+// HandleAdminRemoveUserFromGroup handles DELETE /_admin/users/{name}/groups/{group}
+func HandleAdminRemoveUserFromGroup(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	err := handler.RemoveUserFromGroup(vars["name"], vars["group"])
+	sendAdminResult(w, r, http.StatusNoContent, nil, err)
+}
+
+// HandleAdminAttachUserPolicy handles PUT /_admin/users/{name}/policies/{policy}
+func HandleAdminAttachUserPolicy(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	err := handler.AttachPolicy(handler.PolicyTargetUser, vars["name"], vars["policy"])
+	sendAdminResult(w, r, http.StatusNoContent, nil, err)
+}
+
+// HandleAdminDetachUserPolicy handles DELETE /_admin/users/{name}/policies/{policy}
+func HandleAdminDetachUserPolicy(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	err := handler.DetachPolicy(handler.PolicyTargetUser, vars["name"], vars["policy"])
+	sendAdminResult(w, r, http.StatusNoContent, nil, err)
+}
+
+// HandleAdminListGroups handles GET /_admin/groups
+func HandleAdminListGroups(w http.ResponseWriter, r *http.Request) {
+	groups, err := handler.ListGroups()
+	sendAdminResult(w, r, http.StatusOK, groups, err)
+}
+
+// HandleAdminCreateGroup handles PUT /_admin/groups/{name}
+func HandleAdminCreateGroup(w http.ResponseWriter, r *http.Request) {
+	group, err := handler.CreateGroup(mux.Vars(r)["name"])
+	sendAdminResult(w, r, http.StatusCreated, group, err)
+}
+
+// HandleAdminDeleteGroup handles DELETE /_admin/groups/{name}
+func HandleAdminDeleteGroup(w http.ResponseWriter, r *http.Request) {
+	err := handler.DeleteGroup(mux.Vars(r)["name"])
+	sendAdminResult(w, r, http.StatusNoContent, nil, err)
+}
+
+// HandleAdminAttachGroupPolicy handles PUT /_admin/groups/{name}/policies/{policy}
+func HandleAdminAttachGroupPolicy(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	err := handler.AttachPolicy(handler.PolicyTargetGroup, vars["name"], vars["policy"])
+	sendAdminResult(w, r, http.StatusNoContent, nil, err)
+}
+
+// HandleAdminDetachGroupPolicy handles DELETE /_admin/groups/{name}/policies/{policy}
+func HandleAdminDetachGroupPolicy(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	err := handler.DetachPolicy(handler.PolicyTargetGroup, vars["name"], vars["policy"])
+	sendAdminResult(w, r, http.StatusNoContent, nil, err)
+}
+
+// HandleAdminListRoles handles GET /_admin/roles
+func HandleAdminListRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := handler.ListRoles()
+	sendAdminResult(w, r, http.StatusOK, roles, err)
+}
+
+// HandleAdminCreateRole handles PUT /_admin/roles/{name} with the trust policy as body.
+// The optional max_session_duration query parameter is in seconds.
+func HandleAdminCreateRole(w http.ResponseWriter, r *http.Request) {
+	trustPolicy, err := io.ReadAll(io.LimitReader(r.Body, 64*1024))
+	if err != nil {
+		sendAdminError(w, r, err)
+		return
+	}
+	var maxDuration int
+	if raw := r.URL.Query().Get("max_session_duration"); raw != "" {
+		if maxDuration, err = strconv.Atoi(raw); err != nil {
+			sendAdminError(w, r, handler.ErrInvalidInput)
+			return
+		}
+	}
+	role, err := handler.CreateRole(mux.Vars(r)["name"], trustPolicy, maxDuration)
+	sendAdminResult(w, r, http.StatusCreated, role, err)
+}
+
+// HandleAdminDeleteRole handles DELETE /_admin/roles/{name}
+func HandleAdminDeleteRole(w http.ResponseWriter, r *http.Request) {
+	err := handler.DeleteRole(mux.Vars(r)["name"])
+	sendAdminResult(w, r, http.StatusNoContent, nil, err)
+}
+
+// HandleAdminAttachRolePolicy handles PUT /_admin/roles/{name}/policies/{policy}
+func HandleAdminAttachRolePolicy(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	err := handler.AttachPolicy(handler.PolicyTargetRole, vars["name"], vars["policy"])
+	sendAdminResult(w, r, http.StatusNoContent, nil, err)
+}
+
+// HandleAdminDetachRolePolicy handles DELETE /_admin/roles/{name}/policies/{policy}
+func HandleAdminDetachRolePolicy(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	err := handler.DetachPolicy(handler.PolicyTargetRole, vars["name"], vars["policy"])
+	sendAdminResult(w, r, http.StatusNoContent, nil, err)
+}
+
+// HandleAdminListPolicies handles GET /_admin/policies
+func HandleAdminListPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := handler.ListIdentityPolicies()
+	sendAdminResult(w, r, http.StatusOK, policies, err)
+}
+
+// HandleAdminGetPolicy handles GET /_admin/policies/{name}
+func HandleAdminGetPolicy(w http.ResponseWriter, r *http.Request) {
+	p, err := handler.GetIdentityPolicy(mux.Vars(r)["name"])
+	sendAdminResult(w, r, http.StatusOK, p, err)
+}
+
+// HandleAdminPutPolicy handles PUT /_admin/policies/{name} with the policy document as body
+func HandleAdminPutPolicy(w http.ResponseWriter, r *http.Request) {
+	document, err := io.ReadAll(io.LimitReader(r.Body, 64*1024))
+	if err != nil {
+		sendAdminError(w, r, err)
+		return
+	}
+	p, err := handler.PutIdentityPolicy(mux.Vars(r)["name"], document)
+	sendAdminResult(w, r, http.StatusOK, p, err)
+}
+
+// HandleAdminDeletePolicy handles DELETE /_admin/policies/{name}
+func HandleAdminDeletePolicy(w http.ResponseWriter, r *http.Request) {
+	err := handler.DeleteIdentityPolicy(mux.Vars(r)["name"])
+	sendAdminResult(w, r, http.StatusNoContent, nil, err)
+}
+
+// sendAdminResult writes v as JSON with the given status, or the error if there is one.
+func sendAdminResult(w http.ResponseWriter, r *http.Request, status int, v any, err error) {
+	if err != nil {
+		sendAdminError(w, r, err)
+		return
+	}
+	if v == nil {
+		w.WriteHeader(status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println("JSON encode error:", err)
+	}
+}
+
+// sendAdminError maps handler errors to IAM-style error codes.
+func sendAdminError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, handler.ErrNoSuchEntity):
+		responder.SendError(w, r, responder.ErrNoSuchEntity, err.Error())
+	case errors.Is(err, handler.ErrEntityAlreadyExists):
+		responder.SendError(w, r, responder.ErrEntityAlreadyExists, err.Error())
+	case errors.Is(err, handler.ErrDeleteConflict):
+		responder.SendError(w, r, responder.ErrDeleteConflict, err.Error())
+	case errors.Is(err, handler.ErrInvalidInput):
+		responder.SendError(w, r, responder.ErrInvalidInput, err.Error())
+	case errors.Is(err, handler.ErrNoSuchBucket):
+		responder.SendError(w, r, responder.ErrNoSuchBucket, "")
+	case errors.Is(err, metadata.ErrSidecarsDisabled):
+		responder.SendError(w, r, responder.ErrInvalidRequest, err.Error())
+	default:
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to process admin request")
+		log.Println("Admin request failed:", err)
+	}
+}
@@ -0,0 +1,58 @@
+package routers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/handler"
+	"github.com/aidenappl/openbucket-go/lifecycle"
+	"github.com/aidenappl/openbucket-go/metadata"
+	"github.com/gorilla/mux"
+)
+
+// The storage admin API serves the CLI commands that need the metadata index
+// while the server holds it.
+
+// reindexResponse is returned when the metadata index is rebuilt.
+type reindexResponse struct {
+	Indexed int `json:"indexed"`
+}
+
+// HandleAdminListObjects handles GET /_admin/buckets/{name}/objects
+func HandleAdminListObjects(w http.ResponseWriter, r *http.Request) {
+	objects, err := handler.ListObjects(mux.Vars(r)["name"])
+	sendAdminResult(w, r, http.StatusOK, objects, err)
+}
+
+// HandleAdminLifecycleDryRun handles GET /_admin/lifecycle?bucket=&at=, where
+// at is an RFC 3339 time and bucket may be repeated.
+func HandleAdminLifecycleDryRun(w http.ResponseWriter, r *http.Request) {
+	now := time.Now()
+	if at := r.URL.Query().Get("at"); at != "" {
+		var err error
+		if now, err = time.Parse(time.RFC3339, at); err != nil {
+			sendAdminError(w, r, handler.ErrInvalidInput)
+			return
+		}
+	}
+	actions, err := lifecycle.Run(now, r.URL.Query()["bucket"], true)
+	sendAdminResult(w, r, http.StatusOK, actions, err)
+}
+
+// HandleAdminStorageStats handles GET /_admin/stats
+func HandleAdminStorageStats(w http.ResponseWriter, r *http.Request) {
+	usage, err := handler.StorageStats()
+	sendAdminResult(w, r, http.StatusOK, usage, err)
+}
+
+// HandleAdminRotateEncryptionKey handles POST /_admin/sse/rotate
+func HandleAdminRotateEncryptionKey(w http.ResponseWriter, r *http.Request) {
+	rotation, err := handler.RotateEncryptionKey()
+	sendAdminResult(w, r, http.StatusOK, rotation, err)
+}
+
+// HandleAdminReindex handles POST /_admin/reindex
+func HandleAdminReindex(w http.ResponseWriter, r *http.Request) {
+	count, err := metadata.Reindex()
+	sendAdminResult(w, r, http.StatusOK, reindexResponse{Indexed: count}, err)
+}
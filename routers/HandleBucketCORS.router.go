@@ -0,0 +1,131 @@
+package routers
+
+import (
+	"encoding/xml"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aidenappl/openbucket-go/bucketconfig"
+	"github.com/aidenappl/openbucket-go/middleware"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/gorilla/mux"
+)
+
+// HandlePutBucketCORS handles PUT /{bucket}?cors
+func HandlePutBucketCORS(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, bucketconfig.MaxCORSConfigurationSize+1))
+	if err != nil || len(body) > bucketconfig.MaxCORSConfigurationSize {
+		responder.SendError(w, r, responder.ErrIncompleteBody, "Unable to read CORS configuration")
+		log.Println("Error reading CORS configuration body:", err)
+		return
+	}
+
+	cfg, err := bucketconfig.ParseCORS(body)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrMalformedXML, err.Error())
+		log.Println("Rejected CORS configuration for", bucket+":", err)
+		return
+	}
+
+	if err := bucketconfig.SaveCORS(bucket, cfg); err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to save CORS configuration")
+		log.Println("Error saving CORS configuration:", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	log.Println("CORS configuration updated for bucket:", bucket)
+}
+
+// HandleGetBucketCORS handles GET /{bucket}?cors
+func HandleGetBucketCORS(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	cfg, err := bucketconfig.LoadCORS(bucket)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to load CORS configuration")
+		log.Println("Error loading CORS configuration:", err)
+		return
+	}
+	if cfg == nil {
+		responder.SendError(w, r, responder.ErrNoSuchCORSConfiguration, "The CORS configuration does not exist")
+		return
+	}
+
+	cfg.Xmlns = "http://s3.amazonaws.com/doc/2006-03-01/"
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	if err := xml.NewEncoder(w).Encode(cfg); err != nil {
+		log.Println("XML encode error:", err)
+	}
+}
+
+// HandleDeleteBucketCORS handles DELETE /{bucket}?cors
+func HandleDeleteBucketCORS(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	if err := bucketconfig.DeleteCORS(bucket); err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to delete CORS configuration")
+		log.Println("Error deleting CORS configuration:", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Println("CORS configuration deleted for bucket:", bucket)
+}
+
+// HandleCORSPreflight answers OPTIONS preflight requests for a bucket or
+// object from the bucket's CORS configuration. Preflights carry no
+// credentials, so they are not authorized.
+func HandleCORSPreflight(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	origin := r.Header.Get("Origin")
+	method := r.Header.Get("Access-Control-Request-Method")
+	if origin == "" || method == "" {
+		responder.SendError(w, r, responder.ErrBadRequest, "Insufficient information. Origin request header needed.")
+		return
+	}
+
+	var headers []string
+	for _, header := range strings.Split(r.Header.Get("Access-Control-Request-Headers"), ",") {
+		if header = strings.TrimSpace(header); header != "" {
+			headers = append(headers, header)
+		}
+	}
+
+	cfg, err := bucketconfig.LoadCORS(bucket)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to load CORS configuration")
+		log.Println("Error loading CORS configuration:", err)
+		return
+	}
+	if cfg == nil {
+		responder.SendError(w, r, responder.ErrCORSResponse, "CORS is not enabled for this bucket.")
+		return
+	}
+
+	h := w.Header()
+	h.Add("Vary", "Origin, Access-Control-Request-Headers, Access-Control-Request-Method")
+	rule := bucketconfig.MatchCORS(cfg, origin, method, headers)
+	if rule == nil {
+		responder.SendError(w, r, responder.ErrCORSResponse, "This CORS request is not allowed. This is usually because the evaluation of Origin, request method / Access-Control-Request-Method or Access-Control-Request-Headers are not whitelisted by the resource's CORS spec.")
+		log.Println("CORS preflight rejected for bucket", bucket, "from", origin, method)
+		return
+	}
+
+	middleware.SetCORSHeaders(h, rule, origin)
+	h.Set("Access-Control-Allow-Methods", strings.Join(rule.AllowedMethods, ", "))
+	if len(headers) > 0 {
+		h.Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+	}
+	if rule.MaxAgeSeconds > 0 {
+		h.Set("Access-Control-Max-Age", strconv.Itoa(rule.MaxAgeSeconds))
+	}
+	w.WriteHeader(http.StatusOK)
+}
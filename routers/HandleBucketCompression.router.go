@@ -0,0 +1,77 @@
+package routers
+
+import (
+	"encoding/xml"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/aidenappl/openbucket-go/bucketconfig"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/gorilla/mux"
+)
+
+// HandlePutBucketCompression handles PUT /{bucket}?compression. Compression
+// at rest is an OpenBucket extension; S3 has no equivalent.
+func HandlePutBucketCompression(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, bucketconfig.MaxCompressionConfigurationSize+1))
+	if err != nil || len(body) > bucketconfig.MaxCompressionConfigurationSize {
+		responder.SendError(w, r, responder.ErrIncompleteBody, "Unable to read compression configuration")
+		log.Println("Error reading compression configuration body:", err)
+		return
+	}
+
+	cfg, err := bucketconfig.ParseCompression(body)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrMalformedXML, err.Error())
+		log.Println("Rejected compression configuration for", bucket+":", err)
+		return
+	}
+
+	if err := bucketconfig.SaveCompression(bucket, cfg); err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to save compression configuration")
+		log.Println("Error saving compression configuration:", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	log.Println("Compression configuration updated for bucket:", bucket)
+}
+
+// HandleGetBucketCompression handles GET /{bucket}?compression
+func HandleGetBucketCompression(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	cfg, err := bucketconfig.LoadCompression(bucket)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to load compression configuration")
+		log.Println("Error loading compression configuration:", err)
+		return
+	}
+	if cfg == nil {
+		responder.SendError(w, r, responder.ErrNoSuchCompressionConfiguration, "The compression configuration does not exist")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	if err := xml.NewEncoder(w).Encode(cfg); err != nil {
+		log.Println("XML encode error:", err)
+	}
+}
+
+// HandleDeleteBucketCompression handles DELETE /{bucket}?compression
+func HandleDeleteBucketCompression(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	if err := bucketconfig.DeleteCompression(bucket); err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to delete compression configuration")
+		log.Println("Error deleting compression configuration:", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Println("Compression configuration deleted for bucket:", bucket)
+}
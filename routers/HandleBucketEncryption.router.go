@@ -0,0 +1,194 @@
+package routers
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/aidenappl/openbucket-go/bucketconfig"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/aidenappl/openbucket-go/sse"
+	"github.com/aidenappl/openbucket-go/tools"
+	"github.com/aidenappl/openbucket-go/types"
+	"github.com/gorilla/mux"
+)
+
+// HandlePutBucketEncryption handles PUT /{bucket}?encryption
+func HandlePutBucketEncryption(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, bucketconfig.MaxEncryptionConfigurationSize+1))
+	if err != nil || len(body) > bucketconfig.MaxEncryptionConfigurationSize {
+		responder.SendError(w, r, responder.ErrIncompleteBody, "Unable to read encryption configuration")
+		log.Println("Error reading encryption configuration body:", err)
+		return
+	}
+
+	cfg, err := bucketconfig.ParseEncryption(body)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrMalformedXML, err.Error())
+		log.Println("Rejected encryption configuration for", bucket+":", err)
+		return
+	}
+
+	if err := bucketconfig.SaveEncryption(bucket, cfg); err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to save encryption configuration")
+		log.Println("Error saving encryption configuration:", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	log.Println("Encryption configuration updated for bucket:", bucket)
+}
+
+// HandleGetBucketEncryption handles GET /{bucket}?encryption
+func HandleGetBucketEncryption(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	cfg, err := bucketconfig.LoadEncryption(bucket)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to load encryption configuration")
+		log.Println("Error loading encryption configuration:", err)
+		return
+	}
+	if cfg == nil {
+		responder.SendError(w, r, responder.ErrSSEConfigurationNotFound, "The server side encryption configuration was not found")
+		return
+	}
+
+	cfg.Xmlns = "http://s3.amazonaws.com/doc/2006-03-01/"
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	if err := xml.NewEncoder(w).Encode(cfg); err != nil {
+		log.Println("XML encode error:", err)
+	}
+}
+
+// HandleDeleteBucketEncryption handles DELETE /{bucket}?encryption
+func HandleDeleteBucketEncryption(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	if err := bucketconfig.DeleteEncryption(bucket); err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to delete encryption configuration")
+		log.Println("Error deleting encryption configuration:", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Println("Encryption configuration deleted for bucket:", bucket)
+}
+
+// errUnsupportedEncryption is returned for encryption other than SSE-S3.
+var errUnsupportedEncryption = errors.New("the encryption method specified is not supported")
+
+// requestsEncryption reports whether the x-amz-server-side-encryption value
+// of an upload asks for SSE-S3. An empty value leaves it to the bucket default.
+func requestsEncryption(algorithm string) (bool, error) {
+	switch algorithm {
+	case "":
+		return false, nil
+	case types.SSEAlgorithmAES256:
+		return true, nil
+	}
+	return false, errUnsupportedEncryption
+}
+
+var (
+	// errInvalidCustomerKey is returned for malformed SSE-C headers.
+	errInvalidCustomerKey = errors.New("the customer-provided encryption key is invalid")
+	// errInsecureCustomerKey is returned for SSE-C headers sent without TLS.
+	errInsecureCustomerKey = errors.New("customer-provided keys must be sent over a secure connection")
+)
+
+// uploadEncryption returns the encryption an upload asks for through the
+// x-amz-server-side-encryption and SSE-C headers, which get reads from. The
+// two cannot be combined.
+func uploadEncryption(r *http.Request, get func(string) string) (bool, []byte, error) {
+	encrypt, err := requestsEncryption(get("x-amz-server-side-encryption"))
+	if err != nil {
+		return false, nil, err
+	}
+	customerKey, err := customerKeyFromHeaders(r, get, "x-amz-server-side-encryption-customer")
+	if err != nil {
+		return false, nil, err
+	}
+	if encrypt && customerKey != nil {
+		return false, nil, fmt.Errorf("%w: server-side encryption cannot be combined with a customer-provided key", errInvalidCustomerKey)
+	}
+	return encrypt, customerKey, nil
+}
+
+// customerKeyFromHeaders returns the key of the <prefix>-algorithm, -key and
+// -key-MD5 headers, or nil when none of them is set. The key must be a
+// base64 256-bit AES key matching its MD5, and r must have come over TLS so
+// the key was not sent in the clear.
+func customerKeyFromHeaders(r *http.Request, get func(string) string, prefix string) ([]byte, error) {
+	algorithm, encoded, digest := get(prefix+"-algorithm"), get(prefix+"-key"), get(prefix+"-key-MD5")
+	if algorithm == "" && encoded == "" && digest == "" {
+		return nil, nil
+	}
+	if !tools.SecureTransport(r) {
+		return nil, errInsecureCustomerKey
+	}
+	if algorithm != types.SSEAlgorithmAES256 {
+		return nil, fmt.Errorf("%w: the algorithm must be %s", errInvalidCustomerKey, types.SSEAlgorithmAES256)
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != 32 {
+		return nil, fmt.Errorf("%w: the key must be a base64 encoded 256-bit key", errInvalidCustomerKey)
+	}
+	sum := md5.Sum(key)
+	if digest != base64.StdEncoding.EncodeToString(sum[:]) {
+		return nil, fmt.Errorf("%w: the key MD5 does not match the key", errInvalidCustomerKey)
+	}
+	return key, nil
+}
+
+// sendEncryptionError reports a rejected encryption request or key.
+func sendEncryptionError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, errUnsupportedEncryption):
+		responder.SendError(w, r, responder.ErrInvalidArgument, "The encryption method specified is not supported")
+	case errors.Is(err, errInvalidCustomerKey):
+		responder.SendError(w, r, responder.ErrInvalidArgument, err.Error())
+	case errors.Is(err, errInsecureCustomerKey):
+		responder.SendError(w, r, responder.ErrInvalidRequest, "Requests specifying Server Side Encryption with Customer provided keys must be made over a secure connection.")
+	case errors.Is(err, sse.ErrCustomerKeyRequired):
+		responder.SendError(w, r, responder.ErrInvalidRequest, "The object was stored using a form of Server Side Encryption. The correct parameters must be provided to retrieve the object.")
+	case errors.Is(err, sse.ErrCustomerKeyNotApplicable):
+		responder.SendError(w, r, responder.ErrInvalidRequest, "The encryption parameters are not applicable to this object.")
+	case errors.Is(err, sse.ErrCustomerKeyMismatch):
+		responder.SendError(w, r, responder.ErrAccessDenied, "The provided customer encryption key does not match the object.")
+	default:
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to process object encryption")
+	}
+	log.Println("Encryption request rejected:", err)
+}
+
+// isEncryptionError reports whether err is a rejected encryption request or key.
+func isEncryptionError(err error) bool {
+	return errors.Is(err, errUnsupportedEncryption) || errors.Is(err, errInvalidCustomerKey) || errors.Is(err, errInsecureCustomerKey) ||
+		errors.Is(err, sse.ErrCustomerKeyRequired) || errors.Is(err, sse.ErrCustomerKeyNotApplicable) ||
+		errors.Is(err, sse.ErrCustomerKeyMismatch)
+}
+
+// setEncryptionHeaders reports the encryption of an object on responses.
+// Objects encrypted with a customer-provided key echo its algorithm and MD5.
+func setEncryptionHeaders(h http.Header, md *types.ObjectMetadata, customerKey []byte) {
+	switch {
+	case md == nil || md.Encryption == nil:
+	case sse.IsCustomerKey(md.Encryption):
+		h.Set("x-amz-server-side-encryption-customer-algorithm", md.Encryption.Algorithm)
+		if customerKey != nil {
+			sum := md5.Sum(customerKey)
+			h.Set("x-amz-server-side-encryption-customer-key-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+		}
+	default:
+		h.Set("x-amz-server-side-encryption", md.Encryption.Algorithm)
+	}
+}
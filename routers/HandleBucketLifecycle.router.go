@@ -0,0 +1,77 @@
+package routers
+
+import (
+	"encoding/xml"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/aidenappl/openbucket-go/bucketconfig"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/gorilla/mux"
+)
+
+// HandlePutBucketLifecycle handles PUT /{bucket}?lifecycle
+func HandlePutBucketLifecycle(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, bucketconfig.MaxLifecycleConfigurationSize+1))
+	if err != nil || len(body) > bucketconfig.MaxLifecycleConfigurationSize {
+		responder.SendError(w, r, responder.ErrIncompleteBody, "Unable to read lifecycle configuration")
+		log.Println("Error reading lifecycle configuration body:", err)
+		return
+	}
+
+	cfg, err := bucketconfig.ParseLifecycle(body)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrMalformedXML, err.Error())
+		log.Println("Rejected lifecycle configuration for", bucket+":", err)
+		return
+	}
+
+	if err := bucketconfig.SaveLifecycle(bucket, cfg); err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to save lifecycle configuration")
+		log.Println("Error saving lifecycle configuration:", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	log.Println("Lifecycle configuration updated for bucket:", bucket)
+}
+
+// HandleGetBucketLifecycle handles GET /{bucket}?lifecycle
+func HandleGetBucketLifecycle(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	cfg, err := bucketconfig.LoadLifecycle(bucket)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to load lifecycle configuration")
+		log.Println("Error loading lifecycle configuration:", err)
+		return
+	}
+	if cfg == nil {
+		responder.SendError(w, r, responder.ErrNoSuchLifecycleConfiguration, "The lifecycle configuration does not exist")
+		return
+	}
+
+	cfg.Xmlns = "http://s3.amazonaws.com/doc/2006-03-01/"
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	if err := xml.NewEncoder(w).Encode(cfg); err != nil {
+		log.Println("XML encode error:", err)
+	}
+}
+
+// HandleDeleteBucketLifecycle handles DELETE /{bucket}?lifecycle
+func HandleDeleteBucketLifecycle(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	if err := bucketconfig.DeleteLifecycle(bucket); err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to delete lifecycle configuration")
+		log.Println("Error deleting lifecycle configuration:", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Println("Lifecycle configuration deleted for bucket:", bucket)
+}
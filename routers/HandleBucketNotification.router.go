@@ -0,0 +1,97 @@
+package routers
+
+import (
+	"encoding/xml"
+	"io"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/aidenappl/openbucket-go/bucketconfig"
+	"github.com/aidenappl/openbucket-go/events"
+	"github.com/aidenappl/openbucket-go/middleware"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/aidenappl/openbucket-go/types"
+	"github.com/gorilla/mux"
+)
+
+// HandlePutBucketNotification handles PUT /{bucket}?notification
+func HandlePutBucketNotification(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, bucketconfig.MaxNotificationConfigurationSize+1))
+	if err != nil || len(body) > bucketconfig.MaxNotificationConfigurationSize {
+		responder.SendError(w, r, responder.ErrIncompleteBody, "Unable to read notification configuration")
+		log.Println("Error reading notification configuration body:", err)
+		return
+	}
+
+	cfg, err := bucketconfig.ParseNotification(body)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrMalformedXML, err.Error())
+		log.Println("Rejected notification configuration for", bucket+":", err)
+		return
+	}
+
+	// Events can only be delivered to targets set up on the server
+	targets, err := events.LoadTargets()
+	if err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to load notification targets")
+		log.Println("Error loading notification targets:", err)
+		return
+	}
+	for _, t := range cfg.Targets() {
+		if events.FindTarget(targets, t.ARN()) == nil {
+			responder.SendError(w, r, responder.ErrInvalidArgument, "Unable to validate the following destination configurations: "+t.ARN())
+			return
+		}
+	}
+
+	if err := bucketconfig.SaveNotification(bucket, cfg); err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to save notification configuration")
+		log.Println("Error saving notification configuration:", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	log.Println("Notification configuration updated for bucket:", bucket)
+}
+
+// HandleGetBucketNotification handles GET /{bucket}?notification. A bucket
+// without notifications has an empty configuration.
+func HandleGetBucketNotification(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	cfg, err := bucketconfig.LoadNotification(bucket)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to load notification configuration")
+		log.Println("Error loading notification configuration:", err)
+		return
+	}
+	if cfg == nil {
+		cfg = &types.NotificationConfiguration{}
+	}
+
+	cfg.Xmlns = "http://s3.amazonaws.com/doc/2006-03-01/"
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	if err := xml.NewEncoder(w).Encode(cfg); err != nil {
+		log.Println("XML encode error:", err)
+	}
+}
+
+// emitEvent queues an event notification about an object changed by r.
+func emitEvent(r *http.Request, name, bucket, key string, md *types.ObjectMetadata) {
+	src := events.Source{
+		RequestID: middleware.GetRequestID(r),
+		HostID:    middleware.GetHostID(r),
+		SourceIP:  r.RemoteAddr,
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		src.SourceIP = host
+	}
+	if session := middleware.RetrieveSession(r); session != nil {
+		src.Principal = session.KeyID
+	}
+	events.Emit(name, bucket, key, md, src)
+}
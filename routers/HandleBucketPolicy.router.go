@@ -0,0 +1,77 @@
+package routers
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/aidenappl/openbucket-go/auth"
+	"github.com/aidenappl/openbucket-go/policy"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/gorilla/mux"
+)
+
+// HandlePutBucketPolicy handles PUT /{bucket}?policy
+func HandlePutBucketPolicy(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, policy.MaxBucketPolicySize+1))
+	if err != nil {
+		responder.SendError(w, r, responder.ErrIncompleteBody, "Unable to read policy document")
+		log.Println("Error reading bucket policy body:", err)
+		return
+	}
+
+	p, err := policy.ParseBucketPolicy(body, bucket)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrMalformedPolicy, err.Error())
+		log.Println("Rejected bucket policy for", bucket+":", err)
+		return
+	}
+
+	if err := auth.SaveBucketPolicy(bucket, p); err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to save bucket policy")
+		log.Println("Error saving bucket policy:", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Println("Bucket policy updated for bucket:", bucket)
+}
+
+// HandleGetBucketPolicy handles GET /{bucket}?policy
+func HandleGetBucketPolicy(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	p, err := auth.LoadBucketPolicy(bucket)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to load bucket policy")
+		log.Println("Error loading bucket policy:", err)
+		return
+	}
+	if p == nil {
+		responder.SendError(w, r, responder.ErrNoSuchBucketPolicy, "The bucket policy does not exist")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		log.Println("JSON encode error:", err)
+	}
+}
+
+// HandleDeleteBucketPolicy handles DELETE /{bucket}?policy
+func HandleDeleteBucketPolicy(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	if err := auth.DeleteBucketPolicy(bucket); err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to delete bucket policy")
+		log.Println("Error deleting bucket policy:", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Println("Bucket policy deleted for bucket:", bucket)
+}
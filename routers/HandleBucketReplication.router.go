@@ -0,0 +1,108 @@
+package routers
+
+import (
+	"encoding/xml"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/aidenappl/openbucket-go/bucketconfig"
+	"github.com/aidenappl/openbucket-go/middleware"
+	"github.com/aidenappl/openbucket-go/replication"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/aidenappl/openbucket-go/types"
+	"github.com/gorilla/mux"
+)
+
+// HandlePutBucketReplication handles PUT /{bucket}?replication
+func HandlePutBucketReplication(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, bucketconfig.MaxReplicationConfigurationSize+1))
+	if err != nil || len(body) > bucketconfig.MaxReplicationConfigurationSize {
+		responder.SendError(w, r, responder.ErrIncompleteBody, "Unable to read replication configuration")
+		log.Println("Error reading replication configuration body:", err)
+		return
+	}
+
+	cfg, err := bucketconfig.ParseReplication(body)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrMalformedXML, err.Error())
+		log.Println("Rejected replication configuration for", bucket+":", err)
+		return
+	}
+
+	if err := bucketconfig.SaveReplication(bucket, cfg); err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to save replication configuration")
+		log.Println("Error saving replication configuration:", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	log.Println("Replication configuration updated for bucket:", bucket)
+}
+
+// HandleGetBucketReplication handles GET /{bucket}?replication. Destination
+// secret keys are never returned.
+func HandleGetBucketReplication(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	cfg, err := bucketconfig.LoadReplication(bucket)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to load replication configuration")
+		log.Println("Error loading replication configuration:", err)
+		return
+	}
+	if cfg == nil {
+		responder.SendError(w, r, responder.ErrReplicationConfigurationNotFound, "")
+		return
+	}
+
+	for i := range cfg.Rules {
+		cfg.Rules[i].Destination.SecretAccessKey = ""
+	}
+	cfg.Xmlns = "http://s3.amazonaws.com/doc/2006-03-01/"
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	if err := xml.NewEncoder(w).Encode(cfg); err != nil {
+		log.Println("XML encode error:", err)
+	}
+}
+
+// HandleDeleteBucketReplication handles DELETE /{bucket}?replication. Jobs
+// queued for the removed rules are given up.
+func HandleDeleteBucketReplication(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	if err := bucketconfig.DeleteReplication(bucket); err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to delete replication configuration")
+		log.Println("Error deleting replication configuration:", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Println("Replication configuration deleted for bucket:", bucket)
+}
+
+// replicaRequest reports whether r writes or deletes a replica for the
+// replication of another bucket, which is not replicated further. Marking a
+// request as such needs the given replication action on top of the request's
+// own. It answers the request itself and returns false when that is denied.
+func replicaRequest(w http.ResponseWriter, r *http.Request, action types.Action) (replica bool, ok bool) {
+	if r.Header.Get(replication.StatusHeader) != types.ReplicationReplica {
+		return false, true
+	}
+	if !middleware.AllowedTo(r, action) {
+		responder.SendError(w, r, responder.ErrAccessDenied, "")
+		log.Println("Forbidden: writing replicas requires", action)
+		return false, false
+	}
+	return true, true
+}
+
+// setReplicationHeader reports the replication status of an object.
+func setReplicationHeader(h http.Header, md *types.ObjectMetadata) {
+	if md != nil && md.ReplicationStatus != "" {
+		h.Set(replication.StatusHeader, md.ReplicationStatus)
+	}
+}
@@ -0,0 +1,110 @@
+package routers
+
+import (
+	"encoding/xml"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/aidenappl/openbucket-go/bucketconfig"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/aidenappl/openbucket-go/types"
+	"github.com/gorilla/mux"
+)
+
+// HandlePutBucketWebsite handles PUT /{bucket}?website
+func HandlePutBucketWebsite(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, bucketconfig.MaxWebsiteConfigurationSize+1))
+	if err != nil || len(body) > bucketconfig.MaxWebsiteConfigurationSize {
+		responder.SendError(w, r, responder.ErrIncompleteBody, "Unable to read website configuration")
+		log.Println("Error reading website configuration body:", err)
+		return
+	}
+
+	cfg, err := bucketconfig.ParseWebsite(body)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrMalformedXML, err.Error())
+		log.Println("Rejected website configuration for", bucket+":", err)
+		return
+	}
+
+	if err := bucketconfig.SaveWebsite(bucket, cfg); err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to save website configuration")
+		log.Println("Error saving website configuration:", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	log.Println("Website configuration updated for bucket:", bucket)
+}
+
+// HandleGetBucketWebsite handles GET /{bucket}?website
+func HandleGetBucketWebsite(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	cfg, err := bucketconfig.LoadWebsite(bucket)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to load website configuration")
+		log.Println("Error loading website configuration:", err)
+		return
+	}
+	if cfg == nil {
+		responder.SendError(w, r, responder.ErrNoSuchWebsiteConfiguration, "")
+		return
+	}
+
+	cfg.Xmlns = "http://s3.amazonaws.com/doc/2006-03-01/"
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	if err := xml.NewEncoder(w).Encode(cfg); err != nil {
+		log.Println("XML encode error:", err)
+	}
+}
+
+// HandleDeleteBucketWebsite handles DELETE /{bucket}?website
+func HandleDeleteBucketWebsite(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	if err := bucketconfig.DeleteWebsite(bucket); err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to delete website configuration")
+		log.Println("Error deleting website configuration:", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Println("Website configuration deleted for bucket:", bucket)
+}
+
+// WebsiteRedirectHeader sets the website redirect of an object on upload and
+// reports it on GET and HEAD.
+const WebsiteRedirectHeader = "x-amz-website-redirect-location"
+
+// maxWebsiteRedirectLength is the longest website redirect S3 accepts.
+const maxWebsiteRedirectLength = 2048
+
+// websiteRedirectFromHeaders returns the website redirect requested for a new
+// object, which is a key of the same bucket starting with "/" or an absolute
+// http or https URL. It answers the request itself and returns false when
+// the redirect is invalid.
+func websiteRedirectFromHeaders(w http.ResponseWriter, r *http.Request) (string, bool) {
+	location := r.Header.Get(WebsiteRedirectHeader)
+	if location == "" {
+		return "", true
+	}
+	if len(location) > maxWebsiteRedirectLength ||
+		!(strings.HasPrefix(location, "/") || strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")) {
+		responder.SendError(w, r, responder.ErrInvalidArgument, "The website redirect location must have a prefix of 'http://' or 'https://' or '/'.")
+		return "", false
+	}
+	return location, true
+}
+
+// setWebsiteRedirectHeader reports the website redirect of an object.
+func setWebsiteRedirectHeader(h http.Header, md *types.ObjectMetadata) {
+	if md != nil && md.WebsiteRedirectLocation != "" {
+		h.Set(WebsiteRedirectHeader, md.WebsiteRedirectLocation)
+	}
+}
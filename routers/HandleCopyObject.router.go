@@ -0,0 +1,114 @@
+package routers
+
+import (
+	"encoding/xml"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aidenappl/openbucket-go/events"
+	"github.com/aidenappl/openbucket-go/handler"
+	"github.com/aidenappl/openbucket-go/middleware"
+	"github.com/aidenappl/openbucket-go/replication"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/aidenappl/openbucket-go/tools"
+	"github.com/aidenappl/openbucket-go/types"
+	"github.com/gorilla/mux"
+)
+
+// handleCopyObject serves a PUT with x-amz-copy-source. The upload has been
+// authorized as s3:PutObject on the destination; reading the source needs
+// s3:GetObject on it as well.
+func handleCopyObject(w http.ResponseWriter, r *http.Request, owner types.UserObject, grants []types.Grant, opts handler.PutOptions) {
+	vars := mux.Vars(r)
+	bucket, key := vars["bucket"], vars["key"]
+
+	srcBucket, srcKey, ok := parseCopySource(r.Header.Get("x-amz-copy-source"))
+	if !ok {
+		responder.SendError(w, r, responder.ErrInvalidArgument, "Copy Source must mention the source bucket and key: sourcebucket/sourcekey")
+		return
+	}
+	if !middleware.AllowedOn(r, types.ActionGetObject, srcBucket, srcKey) {
+		responder.SendError(w, r, responder.ErrAccessDenied, "")
+		log.Printf("Forbidden: copy source %s/%s is not readable", srcBucket, srcKey)
+		return
+	}
+
+	srcCustomerKey, err := customerKeyFromHeaders(r, r.Header.Get, "x-amz-copy-source-server-side-encryption-customer")
+	if err != nil {
+		sendEncryptionError(w, r, err)
+		return
+	}
+
+	// The copy keeps the tags of the source unless asked to take those of
+	// the x-amz-tagging header
+	switch r.Header.Get("x-amz-tagging-directive") {
+	case "", "COPY":
+	case "REPLACE":
+		opts.ReplaceTags = true
+	default:
+		responder.SendError(w, r, responder.ErrInvalidArgument, "Unknown tagging directive.")
+		return
+	}
+
+	// Copying an object onto itself must change something about it
+	if srcBucket == bucket && srcKey == key && r.Header.Get("x-amz-metadata-directive") != "REPLACE" &&
+		!opts.ReplaceTags && !opts.Encrypt && opts.CustomerKey == nil {
+		responder.SendError(w, r, responder.ErrInvalidRequest, "This copy request is illegal because it is trying to copy an object to itself without changing the object's metadata, storage class, website redirect location or encryption attributes.")
+		return
+	}
+
+	md, err := handler.CopyObject(srcBucket, srcKey, srcCustomerKey, bucket, key, owner, grants, opts)
+	switch {
+	case errors.Is(err, handler.ErrNoSuchKey):
+		responder.SendError(w, r, responder.ErrNoSuchKey, "The specified key does not exist")
+		return
+	case errors.Is(err, handler.ErrNoSuchBucket):
+		responder.SendError(w, r, responder.ErrNoSuchBucket, "The specified bucket does not exist")
+		return
+	case isEncryptionError(err):
+		sendEncryptionError(w, r, err)
+		return
+	case isObjectLockError(err):
+		sendObjectLockError(w, r, err)
+		return
+	case err != nil:
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to copy object")
+		log.Println("Error copying object:", err)
+		return
+	}
+
+	replication.Enqueue(md)
+	setEncryptionHeaders(w.Header(), md, opts.CustomerKey)
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	if err := xml.NewEncoder(w).Encode(types.CopyObjectResult{
+		Xmlns:        "http://s3.amazonaws.com/doc/2006-03-01/",
+		ETag:         `"` + md.ETag + `"`,
+		LastModified: md.LastModified,
+	}); err != nil {
+		log.Println("XML encode error:", err)
+	}
+	log.Printf("Object copied from %s/%s to %s/%s", srcBucket, srcKey, bucket, key)
+	emitEvent(r, events.ObjectCreatedCopy, bucket, key, md)
+}
+
+// parseCopySource splits an x-amz-copy-source value, "bucket/key" with an
+// optional leading slash and URL encoding, into its bucket and key. Keys are
+// encoded before they reach the file system, so any key up to
+// tools.MaxKeyLength is accepted. Objects have a single version, so a
+// versionId is ignored.
+func parseCopySource(source string) (string, string, bool) {
+	source, _, _ = strings.Cut(source, "?versionId=")
+	source, err := url.PathUnescape(source)
+	if err != nil {
+		return "", "", false
+	}
+	bucket, key, ok := strings.Cut(strings.TrimPrefix(source, "/"), "/")
+	if !ok || !tools.ValidBucketName(bucket) || key == "" || len(key) > tools.MaxKeyLength {
+		return "", "", false
+	}
+	return bucket, key, true
+}
@@ -1,11 +1,16 @@
 package routers
 
 import (
+	"errors"
 	"log"
 	"net/http"
-	"os"
-	"path/filepath"
 
+	"github.com/aidenappl/openbucket-go/events"
+	"github.com/aidenappl/openbucket-go/handler"
+	"github.com/aidenappl/openbucket-go/middleware"
+	"github.com/aidenappl/openbucket-go/replication"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/aidenappl/openbucket-go/types"
 	"github.com/gorilla/mux"
 )
 
@@ -14,20 +19,29 @@ func HandleDelete(w http.ResponseWriter, r *http.Request) {
 	bucket := vars["bucket"]
 	key := vars["key"]
 
-	filePath := filepath.Join("buckets", bucket, key)
-
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		http.Error(w, "Object not found", http.StatusNotFound)
+	replica, ok := replicaRequest(w, r, types.ActionReplicateDelete)
+	if !ok {
 		return
 	}
 
-	err := os.Remove(filePath)
-	if err != nil {
-		http.Error(w, "Failed to delete object", http.StatusInternalServerError)
+	err := handler.DeleteObject(bucket, key, governanceBypass(r))
+	if errors.Is(err, handler.ErrNoSuchKey) {
+		// Like S3, deleting a key that does not exist succeeds
+		w.WriteHeader(http.StatusNoContent)
+		return
+	} else if isObjectLockError(err) {
+		sendObjectLockError(w, r, err)
+		return
+	} else if err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to delete object")
 		log.Println("Error deleting file:", err)
 		return
 	}
 
+	if !replica {
+		replication.EnqueueDelete(bucket, key, middleware.RetrieveMetadata(r))
+	}
 	w.WriteHeader(http.StatusNoContent)
 	log.Printf("Successfully deleted object %s from bucket %s", key, bucket)
+	emitEvent(r, events.ObjectRemovedDelete, bucket, key, middleware.RetrieveMetadata(r))
 }
@@ -1,15 +1,15 @@
 package routers
 
 import (
-	"io"
 	"log"
 	"net/http"
 	"os"
-	"path/filepath"
 	"strconv"
 
+	"github.com/aidenappl/openbucket-go/handler"
 	"github.com/aidenappl/openbucket-go/middleware"
 	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/aidenappl/openbucket-go/sse"
 	"github.com/aidenappl/openbucket-go/tools"
 	"github.com/aidenappl/openbucket-go/types"
 	"github.com/gorilla/mux"
@@ -25,76 +25,71 @@ func HandleDownload(w http.ResponseWriter, r *http.Request) {
 	host := middleware.GetHostID(r)
 
 	if bucket == "" || key == "" {
-		responder.SendAccessDeniedXML(w, nil, nil)
+		responder.SendError(w, r, responder.ErrAccessDenied, "")
 		log.Println(request, host, "Bucket or key is empty")
 		return
 	}
 
-	filePath := filepath.Join("buckets", bucket, key)
-	file, err := os.Open(filePath)
-	if err != nil {
-		responder.SendAccessDeniedXML(w, &request, &host)
-		log.Println(request, host, "Error getting file info:", err)
-		return
-	}
+	filePath := tools.ObjectPath(bucket, key)
 	fileInfo, err := os.Stat(filePath)
 	if os.IsNotExist(err) {
-		responder.SendAccessDeniedXML(w, &request, &host)
+		responder.SendError(w, r, responder.ErrNoSuchKey, "")
 		return
 	} else if err != nil {
-		responder.SendAccessDeniedXML(w, &request, &host)
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to read object")
 		log.Println(request, host, "Error opening file:", err)
 		return
 	} else if fileInfo.IsDir() {
-		responder.SendAccessDeniedXML(w, &request, &host)
+		responder.SendError(w, r, responder.ErrNoSuchKey, "")
 		log.Println(request, host, "File is a directory, not a valid object:", filePath)
 		return
 	}
-	defer file.Close()
 
-	permissions := middleware.RetrievePermissions(r)
+	// Access has already been decided by middleware.Authorized (ACLs, bucket
+	// policy and public objects), so only the metadata is needed here.
 	metadata := middleware.RetrieveMetadata(r)
-	session := middleware.RetrieveSession(r)
+	if metadata == nil {
+		metadata = &types.ObjectMetadata{}
+	}
 
-	if !metadata.Public && !types.IsBucketACLRead(permissions.ACL) && session == nil {
-		if !isValidPresignURL(r, bucket, key) {
-			responder.SendAccessDeniedXML(w, &request, &host)
-			log.Println(request, host, "Invalid or expired presigned URL:", key)
-			return
-		}
+	// Objects encrypted with a customer-provided key can only be read with it
+	customerKey, err := customerKeyFromHeaders(r, r.Header.Get, "x-amz-server-side-encryption-customer")
+	if err == nil {
+		err = sse.CheckCustomerKey(metadata.Encryption, customerKey)
+	}
+	if err != nil {
+		sendEncryptionError(w, r, err)
+		return
+	}
 
-		responder.SendAccessDeniedXML(w, &request, &host)
-		log.Println(request, host, "Access denied for bucket:", bucket, "key:", key)
+	// Stored content is decrypted and decompressed chunk by chunk as it is
+	// read, so ranged requests only decode the chunks they cover
+	content, err := handler.OpenContent(bucket, key, metadata, customerKey)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to read object")
+		log.Println(request, host, "Error opening file:", err)
 		return
 	}
+	defer content.Close()
 
 	w.Header().Set("ETag", metadata.ETag)
 	w.Header().Set("X-Amz-Meta-owner-id", metadata.Owner.ID)
 	w.Header().Set("X-Amz-Meta-owner-display-name", metadata.Owner.DisplayName)
-	w.Header().Set("Content-Length", strconv.FormatInt(fileInfo.Size(), 10))
-	w.Header().Set("Content-Type", tools.ContentType(filePath))
-	w.Header().Set("Last-Modified", fileInfo.ModTime().UTC().Format(http.TimeFormat))
+	w.Header().Set("Content-Type", tools.ContentType(key))
 	w.Header().Set("x-amz-tagging-count", strconv.Itoa(len(metadata.Tags)))
 	w.Header().Set("x-amz-version-id", metadata.VersionId)
+	setObjectLockHeaders(w.Header(), metadata)
+	setEncryptionHeaders(w.Header(), metadata, customerKey)
+	setReplicationHeader(w.Header(), metadata)
+	setWebsiteRedirectHeader(w.Header(), metadata)
 
-	_, err = io.Copy(w, file)
-	if err != nil {
-		responder.SendAccessDeniedXML(w, &request, &host)
-		log.Println(request, host, "Error transferring file:", err)
+	// ServeContent answers Range and conditional requests and sets
+	// Content-Length and Last-Modified
+	http.ServeContent(w, r, "", fileInfo.ModTime(), content)
+	if content.Err() != nil {
+		log.Println(request, host, "Error reading file:", content.Err())
 		return
 	}
 
 	log.Println("File successfully served:", filePath)
 }
-
-func isValidPresignURL(r *http.Request, bucket, key string) bool {
-	request := middleware.GetRequestID(r)
-	host := middleware.GetHostID(r)
-	amzDate := r.URL.Query().Get("X-Amz-Date")
-	signature := r.URL.Query().Get("X-Amz-Signature")
-	if amzDate == "" || signature == "" {
-		log.Println(request, host, "Missing required parameters for presigned URL validation")
-		return false
-	}
-	return true
-}
@@ -1,15 +1,13 @@
 package routers
 
 import (
-	"encoding/xml"
 	"net/http"
 	"os"
-	"path"
-	"path/filepath"
 	"strconv"
-	"strings"
 
+	"github.com/aidenappl/openbucket-go/metadata"
 	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/aidenappl/openbucket-go/sse"
 	"github.com/aidenappl/openbucket-go/tools"
 	"github.com/aidenappl/openbucket-go/types"
 	"github.com/gorilla/mux"
@@ -18,53 +16,48 @@ import (
 func HandleHeadObject(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	bucket := vars["bucket"]
-	rawKey := vars["key"]
+	key := vars["key"]
 
-	if bucket == "" || rawKey == "" {
-		responder.SendXML(w, http.StatusBadRequest, "InvalidRequest",
-			"Bucket and key must be provided", "", "")
+	if bucket == "" || key == "" {
+		responder.SendError(w, r, responder.ErrInvalidRequest, "Bucket and key must be provided")
 		return
 	}
 
-	cleanKey := path.Clean("/" + rawKey)
-	if strings.Contains(cleanKey, "..") ||
-		strings.HasSuffix(cleanKey, "/") {
-		responder.SendXML(w, http.StatusBadRequest, "InvalidKey",
-			"Invalid object key", "", "")
-		return
-	}
-
-	objPath := filepath.Join("buckets", bucket, cleanKey)
+	objPath := tools.ObjectPath(bucket, key)
 	info, err := os.Stat(objPath)
 	if err != nil {
-		responder.SendXML(w, http.StatusNotFound, "NoSuchKey",
-			"Object not found", "", "")
+		responder.SendError(w, r, responder.ErrNoSuchKey, "Object not found")
 		return
 	}
 
-	if info.IsDir() {
-		w.Header().Set("Content-Type", "application/xml")
-		w.Header().Set("Content-Length", strconv.FormatInt(info.Size(), 10))
-		w.Header().Set("Last-Modified", info.ModTime().UTC().Format(http.TimeFormat))
-		w.WriteHeader(http.StatusOK)
-		return
+	var meta types.ObjectMetadata
+	if md, err := metadata.Load(bucket, key); err == nil {
+		meta = *md
 	}
 
-	var meta types.ObjectMetadata
-	metaPath := objPath + ".obmeta"
-	if f, err := os.Open(metaPath); err == nil {
-		_ = xml.NewDecoder(f).Decode(&meta)
-		f.Close()
+	customerKey, err := customerKeyFromHeaders(r, r.Header.Get, "x-amz-server-side-encryption-customer")
+	if err == nil {
+		err = sse.CheckCustomerKey(meta.Encryption, customerKey)
 	}
+	if err != nil {
+		sendEncryptionError(w, r, err)
+		return
+	}
+
+	cType := tools.ContentType(key)
 
-	cType := tools.ContentType(objPath)
+	size := metadata.ContentSize(&meta, info.Size())
 
 	w.Header().Set("Content-Type", cType)
-	w.Header().Set("Content-Length", strconv.FormatInt(info.Size(), 10))
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
 	w.Header().Set("Last-Modified", info.ModTime().UTC().Format(http.TimeFormat))
 	if meta.ETag != "" {
 		w.Header().Set("ETag", meta.ETag)
 	}
+	setObjectLockHeaders(w.Header(), &meta)
+	setEncryptionHeaders(w.Header(), &meta, customerKey)
+	setReplicationHeader(w.Header(), &meta)
+	setWebsiteRedirectHeader(w.Header(), &meta)
 	// w.Header().Set("X-Amz-Meta-Owner-Id", meta.Owner)
 
 	w.WriteHeader(http.StatusOK)
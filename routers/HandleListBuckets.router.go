@@ -2,17 +2,49 @@ package routers
 
 import (
 	"encoding/xml"
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/aidenappl/openbucket-go/handler"
+	"github.com/aidenappl/openbucket-go/middleware"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/aidenappl/openbucket-go/types"
 )
 
+// HandleListBuckets lists the buckets the caller may list the objects of,
+// filtered by prefix and paged with max-buckets and continuation-token.
 func HandleListBuckets(w http.ResponseWriter, r *http.Request) {
+	session := middleware.RetrieveSession(r)
+	if session == nil {
+		responder.SendError(w, r, responder.ErrAccessDenied, "")
+		return
+	}
+
+	q := r.URL.Query()
+	query := handler.BucketQuery{
+		Prefix:            q.Get("prefix"),
+		ContinuationToken: q.Get("continuation-token"),
+	}
+	if v := q.Get("max-buckets"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 10000 {
+			responder.SendError(w, r, responder.ErrInvalidArgument, "max-buckets must be between 1 and 10000")
+			return
+		}
+		query.MaxBuckets = n
+	}
 
-	bucketsList, err := handler.ListBucketsXML()
-	if err != nil {
-		http.Error(w, "Failed to list buckets", http.StatusInternalServerError)
+	owner := types.UserObject{ID: session.KeyID, DisplayName: session.Name}
+	bucketsList, err := handler.ListBucketsXML(owner, query, func(bucket string) bool {
+		return middleware.AllowedOn(r, types.ActionListBucket, bucket, "")
+	})
+	if errors.Is(err, handler.ErrInvalidContinuationToken) {
+		responder.SendError(w, r, responder.ErrInvalidArgument, "The continuation token provided is incorrect")
+		return
+	} else if err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to list buckets")
 		log.Println("Error listing buckets:", err)
 		return
 	}
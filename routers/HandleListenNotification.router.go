@@ -0,0 +1,96 @@
+package routers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/bucketconfig"
+	"github.com/aidenappl/openbucket-go/events"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/aidenappl/openbucket-go/types"
+	"github.com/gorilla/mux"
+)
+
+// keepaliveInterval is how often an idle event stream sends a comment, so
+// proxies and clients do not time it out.
+const keepaliveInterval = 15 * time.Second
+
+// HandleListenBucketNotification handles GET /{bucket}?events. It streams the
+// bucket's object events as Server-Sent Events until the client goes away.
+// The events, prefix and suffix parameters narrow the stream; by default all
+// created and removed events are sent. A client that falls behind misses
+// events and is told how many with a "dropped" event.
+func HandleListenBucketNotification(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+	q := r.URL.Query()
+
+	var names []string
+	for _, name := range q["events"] {
+		if name == "" {
+			continue
+		}
+		if !slices.Contains(bucketconfig.NotificationEvents, name) {
+			responder.SendError(w, r, responder.ErrInvalidArgument, "The event "+name+" is not supported")
+			return
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		names = []string{"s3:ObjectCreated:*", "s3:ObjectRemoved:*"}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		responder.SendError(w, r, responder.ErrNotImplemented, "Streaming is not supported")
+		return
+	}
+
+	sub := events.Subscribe(bucket, q.Get("prefix"), q.Get("suffix"), names)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": listening\n\n")
+	flusher.Flush()
+	log.Println("Event stream opened for bucket:", bucket)
+
+	keepalive := time.NewTicker(keepaliveInterval)
+	defer keepalive.Stop()
+	for {
+		var err error
+		select {
+		case <-r.Context().Done():
+			log.Println("Event stream closed for bucket:", bucket)
+			return
+		case <-keepalive.C:
+			_, err = fmt.Fprint(w, ": keepalive\n\n")
+		case record := <-sub.Events:
+			if n := sub.TakeDropped(); n > 0 {
+				fmt.Fprintf(w, "event: dropped\ndata: {\"count\":%d}\n\n", n)
+			}
+			err = writeStreamEvent(w, record)
+		}
+		if err != nil {
+			log.Println("Event stream for bucket", bucket, "failed:", err)
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// writeStreamEvent writes one event record as a Server-Sent Event named after
+// the event, with the S3 event message as its data.
+func writeStreamEvent(w http.ResponseWriter, record types.EventRecord) error {
+	data, err := json.Marshal(types.EventMessage{Records: []types.EventRecord{record}})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", record.S3.Object.Sequencer, record.EventName, data)
+	return err
+}
@@ -0,0 +1,227 @@
+package routers
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/aidenappl/openbucket-go/events"
+	"github.com/aidenappl/openbucket-go/handler"
+	"github.com/aidenappl/openbucket-go/replication"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/aidenappl/openbucket-go/types"
+	"github.com/gorilla/mux"
+)
+
+// maxCompleteMultipartUploadSize caps the size of a CompleteMultipartUpload document.
+const maxCompleteMultipartUploadSize = 1024 * 1024
+
+// HandleCreateMultipartUpload handles POST /{bucket}/{key}?uploads. The ACL,
+// Object Lock and encryption headers are those of a PUT upload and apply to
+// the object once the upload completes.
+func HandleCreateMultipartUpload(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket, key := vars["bucket"], vars["key"]
+
+	owner, grants, opts, ok := uploadSettings(w, r)
+	if !ok {
+		return
+	}
+
+	upload, err := handler.CreateMultipartUpload(bucket, key, owner, grants, opts)
+	if err != nil {
+		sendMultipartError(w, r, err)
+		return
+	}
+
+	if upload.CustomerKey != nil {
+		setEncryptionHeaders(w.Header(), &types.ObjectMetadata{Encryption: upload.CustomerKey}, opts.CustomerKey)
+	} else if upload.Encrypt {
+		w.Header().Set("x-amz-server-side-encryption", types.SSEAlgorithmAES256)
+	}
+	sendMultipartXML(w, types.InitiateMultipartUploadResult{
+		Xmlns:    "http://s3.amazonaws.com/doc/2006-03-01/",
+		Bucket:   bucket,
+		Key:      key,
+		UploadID: upload.UploadID,
+	})
+	log.Printf("Multipart upload %s started for %s/%s", upload.UploadID, bucket, key)
+}
+
+// HandleUploadPart handles PUT /{bucket}/{key}?partNumber=&uploadId=
+func HandleUploadPart(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket, key := vars["bucket"], vars["key"]
+
+	partNumber, err := strconv.Atoi(vars["partNumber"])
+	if err != nil || partNumber < 1 || partNumber > handler.MaxPartNumber {
+		responder.SendError(w, r, responder.ErrInvalidArgument, "Part number must be an integer between 1 and 10000, inclusive")
+		return
+	}
+	customerKey, err := customerKeyFromHeaders(r, r.Header.Get, "x-amz-server-side-encryption-customer")
+	if err != nil {
+		sendEncryptionError(w, r, err)
+		return
+	}
+
+	part, err := handler.UploadPart(bucket, key, vars["uploadId"], partNumber, r.Body, customerKey)
+	if err != nil {
+		sendMultipartError(w, r, err)
+		return
+	}
+
+	setEncryptionHeaders(w.Header(), &types.ObjectMetadata{Encryption: part.Encryption}, customerKey)
+	w.Header().Set("ETag", `"`+part.ETag+`"`)
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleCompleteMultipartUpload handles POST /{bucket}/{key}?uploadId=
+func HandleCompleteMultipartUpload(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket, key := vars["bucket"], vars["key"]
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxCompleteMultipartUploadSize+1))
+	if err != nil || len(body) > maxCompleteMultipartUploadSize {
+		responder.SendError(w, r, responder.ErrIncompleteBody, "Unable to read the list of parts")
+		log.Println("Error reading CompleteMultipartUpload body:", err)
+		return
+	}
+	var complete types.CompleteMultipartUpload
+	if err := xml.Unmarshal(body, &complete); err != nil {
+		responder.SendError(w, r, responder.ErrMalformedXML, "The XML you provided was not well-formed or did not validate against our published schema")
+		return
+	}
+	customerKey, err := customerKeyFromHeaders(r, r.Header.Get, "x-amz-server-side-encryption-customer")
+	if err != nil {
+		sendEncryptionError(w, r, err)
+		return
+	}
+
+	md, err := handler.CompleteMultipartUpload(bucket, key, vars["uploadId"], complete.Parts, customerKey, governanceBypass(r))
+	if err != nil {
+		sendMultipartError(w, r, err)
+		return
+	}
+
+	replication.Enqueue(md)
+	setEncryptionHeaders(w.Header(), md, customerKey)
+	sendMultipartXML(w, types.CompleteMultipartUploadResult{
+		Xmlns:    "http://s3.amazonaws.com/doc/2006-03-01/",
+		Location: "/" + bucket + "/" + key,
+		Bucket:   bucket,
+		Key:      key,
+		ETag:     `"` + md.ETag + `"`,
+	})
+	log.Printf("Multipart upload %s completed for %s/%s", vars["uploadId"], bucket, key)
+	emitEvent(r, events.ObjectCreatedComplete, bucket, key, md)
+}
+
+// HandleAbortMultipartUpload handles DELETE /{bucket}/{key}?uploadId=
+func HandleAbortMultipartUpload(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket, key := vars["bucket"], vars["key"]
+
+	if err := handler.AbortMultipartUpload(bucket, key, vars["uploadId"]); err != nil {
+		sendMultipartError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Printf("Multipart upload %s aborted for %s/%s", vars["uploadId"], bucket, key)
+}
+
+// HandleListParts handles GET /{bucket}/{key}?uploadId=
+func HandleListParts(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket, key := vars["bucket"], vars["key"]
+
+	upload, parts, err := handler.ListParts(bucket, key, vars["uploadId"])
+	if err != nil {
+		sendMultipartError(w, r, err)
+		return
+	}
+
+	result := types.ListPartsResult{
+		Xmlns:     "http://s3.amazonaws.com/doc/2006-03-01/",
+		Bucket:    bucket,
+		Key:       key,
+		UploadID:  upload.UploadID,
+		Initiator: upload.Owner,
+		Owner:     upload.Owner,
+		MaxParts:  handler.MaxPartNumber,
+	}
+	for _, part := range parts {
+		result.Parts = append(result.Parts, types.ListedPart{
+			PartNumber:   part.PartNumber,
+			LastModified: part.LastModified,
+			ETag:         `"` + part.ETag + `"`,
+			Size:         part.Size,
+		})
+		result.NextPartNumberMarker = part.PartNumber
+	}
+	sendMultipartXML(w, result)
+}
+
+// HandleListMultipartUploads handles GET /{bucket}?uploads
+func HandleListMultipartUploads(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	uploads, err := handler.ListMultipartUploads(bucket)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to list multipart uploads")
+		log.Println("Error listing multipart uploads:", err)
+		return
+	}
+
+	result := types.ListMultipartUploadsResult{
+		Xmlns:      "http://s3.amazonaws.com/doc/2006-03-01/",
+		Bucket:     bucket,
+		MaxUploads: 1000,
+	}
+	for _, upload := range uploads {
+		result.Uploads = append(result.Uploads, types.ListedUpload{
+			Key:       upload.Key,
+			UploadID:  upload.UploadID,
+			Initiator: upload.Owner,
+			Owner:     upload.Owner,
+			Initiated: upload.Initiated,
+		})
+	}
+	sendMultipartXML(w, result)
+}
+
+// sendMultipartError reports a failed multipart upload request.
+func sendMultipartError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, handler.ErrNoSuchUpload):
+		responder.SendError(w, r, responder.ErrNoSuchUpload, "The specified multipart upload does not exist. The upload ID may be invalid, or the upload may have been aborted or completed.")
+	case errors.Is(err, handler.ErrNoSuchBucket):
+		responder.SendError(w, r, responder.ErrNoSuchBucket, "The specified bucket does not exist")
+	case errors.Is(err, handler.ErrInvalidPart):
+		responder.SendError(w, r, responder.ErrInvalidPart, err.Error())
+	case errors.Is(err, handler.ErrInvalidPartOrder):
+		responder.SendError(w, r, responder.ErrInvalidPartOrder, "The list of parts was not in ascending order. The parts list must be specified in order by part number.")
+	case errors.Is(err, handler.ErrPartTooSmall):
+		responder.SendError(w, r, responder.ErrEntityTooSmall, "Your proposed upload is smaller than the minimum allowed object size.")
+	case isEncryptionError(err):
+		sendEncryptionError(w, r, err)
+		return
+	case isObjectLockError(err):
+		sendObjectLockError(w, r, err)
+		return
+	default:
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to process multipart upload")
+	}
+	log.Println("Multipart upload request failed:", err)
+}
+
+func sendMultipartXML(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	if err := xml.NewEncoder(w).Encode(v); err != nil {
+		log.Println("XML encode error:", err)
+	}
+}
@@ -0,0 +1,141 @@
+package routers
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/aidenappl/openbucket-go/auth"
+	"github.com/aidenappl/openbucket-go/handler"
+	"github.com/aidenappl/openbucket-go/metadata"
+	"github.com/aidenappl/openbucket-go/middleware"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// maxACLBodySize caps the size of an AccessControlPolicy request body.
+const maxACLBodySize = 64 * 1024
+
+// HandleGetObjectACL handles GET /{bucket}/{key}?acl
+func HandleGetObjectACL(w http.ResponseWriter, r *http.Request) {
+	md := middleware.RetrieveMetadata(r)
+	if md == nil {
+		responder.SendError(w, r, responder.ErrNoSuchKey, "The specified key does not exist")
+		return
+	}
+
+	sendAccessControlPolicy(w, md.Owner, metadata.EffectiveGrants(md))
+}
+
+// HandlePutObjectACL handles PUT /{bucket}/{key}?acl. The ACL is taken from the
+// x-amz-acl header, the x-amz-grant-* headers or an AccessControlPolicy body,
+// in that order, and replaces the object's existing grants.
+func HandlePutObjectACL(w http.ResponseWriter, r *http.Request) {
+	md := middleware.RetrieveMetadata(r)
+	if md == nil {
+		responder.SendError(w, r, responder.ErrNoSuchKey, "The specified key does not exist")
+		return
+	}
+
+	var bucketOwner types.UserObject
+	if perms := middleware.RetrievePermissions(r); perms != nil {
+		bucketOwner = perms.Owner
+	}
+
+	grants, err := objectACLFromHeaders(r, md.Owner, bucketOwner)
+	if err == nil && grants == nil {
+		grants, err = aclFromBody(r)
+	}
+	if err != nil {
+		sendACLError(w, r, err)
+		return
+	}
+
+	// Reload the metadata under the object's lock, so a concurrent upload
+	// is not overwritten with the metadata it replaced
+	defer handler.LockObject(md.Bucket, md.Key)()
+	current, err := metadata.Load(md.Bucket, md.Key)
+	if errors.Is(err, os.ErrNotExist) {
+		responder.SendError(w, r, responder.ErrNoSuchKey, "The specified key does not exist")
+		return
+	} else if err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to save object ACL")
+		log.Println("Error loading object metadata:", err)
+		return
+	}
+
+	metadata.SetGrants(current, grants)
+	if err := metadata.Save(current); err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to save object ACL")
+		log.Println("Error saving object ACL:", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	log.Printf("Object ACL updated for %s/%s", md.Bucket, md.Key)
+}
+
+// objectACLFromHeaders returns the grants requested through x-amz-acl or the
+// x-amz-grant-* headers, or nil when neither is set. The two cannot be combined.
+func objectACLFromHeaders(r *http.Request, owner, bucketOwner types.UserObject) ([]types.Grant, error) {
+	canned := r.Header.Get("x-amz-acl")
+	hasGrants := auth.HasGrantHeaders(r.Header)
+
+	switch {
+	case canned != "" && hasGrants:
+		return nil, fmt.Errorf("%w: x-amz-acl cannot be combined with x-amz-grant-* headers", auth.ErrInvalidACL)
+	case canned != "":
+		return auth.CannedObjectGrants(canned, owner, bucketOwner)
+	case hasGrants:
+		return auth.GrantsFromHeaders(r.Header)
+	}
+	return nil, nil
+}
+
+// aclFromBody reads the grants of an AccessControlPolicy request body.
+func aclFromBody(r *http.Request) ([]types.Grant, error) {
+	var acp types.AccessControlPolicy
+	if err := xml.NewDecoder(io.LimitReader(r.Body, maxACLBodySize)).Decode(&acp); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: no ACL headers or AccessControlPolicy body provided", auth.ErrInvalidACL)
+		}
+		return nil, fmt.Errorf("%w: malformed AccessControlPolicy: %v", auth.ErrInvalidACL, err)
+	}
+	return auth.ResolveGrants(acp.AccessControlList)
+}
+
+// sendAccessControlPolicy writes an AccessControlPolicy document for the owner and grants.
+func sendAccessControlPolicy(w http.ResponseWriter, owner types.UserObject, grants []types.Grant) {
+	policy := &types.AccessControlPolicy{
+		XmlnsXsi:          types.XsiNS,
+		Owner:             owner,
+		AccessControlList: make([]types.Grant, len(grants)),
+	}
+	for i, g := range grants {
+		g.XmlnsXsi = types.XsiNS
+		g.Grantee.Type = g.Grantee.ResolvedType()
+		policy.AccessControlList[i] = g
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	if err := xml.NewEncoder(w).Encode(policy); err != nil {
+		log.Println("XML encode error:", err)
+	}
+}
+
+// sendACLError reports a rejected ACL as a client error and anything else as
+// an internal error.
+func sendACLError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, auth.ErrInvalidACL) {
+		responder.SendError(w, r, responder.ErrInvalidArgument, err.Error())
+		log.Println("Rejected ACL:", err)
+		return
+	}
+	responder.SendError(w, r, responder.ErrInternalError, "Unable to apply ACL")
+	log.Println("Error applying ACL:", err)
+}
@@ -0,0 +1,230 @@
+package routers
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/bucketconfig"
+	"github.com/aidenappl/openbucket-go/handler"
+	"github.com/aidenappl/openbucket-go/middleware"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/aidenappl/openbucket-go/types"
+	"github.com/gorilla/mux"
+)
+
+// maxObjectLockBodySize caps the size of Retention and LegalHold request bodies.
+const maxObjectLockBodySize = 16 * 1024
+
+// HandlePutObjectLockConfiguration handles PUT /{bucket}?object-lock. Object
+// Lock must have been enabled when the bucket was created; this only changes
+// the default retention.
+func HandlePutObjectLockConfiguration(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, bucketconfig.MaxObjectLockConfigurationSize+1))
+	if err != nil || len(body) > bucketconfig.MaxObjectLockConfigurationSize {
+		responder.SendError(w, r, responder.ErrIncompleteBody, "Unable to read Object Lock configuration")
+		log.Println("Error reading Object Lock configuration body:", err)
+		return
+	}
+
+	cfg, err := bucketconfig.ParseObjectLock(body)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrMalformedXML, err.Error())
+		log.Println("Rejected Object Lock configuration for", bucket+":", err)
+		return
+	}
+
+	current, err := bucketconfig.LoadObjectLock(bucket)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to load Object Lock configuration")
+		log.Println("Error loading Object Lock configuration:", err)
+		return
+	}
+	if current == nil {
+		responder.SendError(w, r, responder.ErrInvalidBucketState, "Object Lock configuration cannot be enabled on existing buckets")
+		return
+	}
+
+	if err := bucketconfig.SaveObjectLock(bucket, cfg); err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to save Object Lock configuration")
+		log.Println("Error saving Object Lock configuration:", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	log.Println("Object Lock configuration updated for bucket:", bucket)
+}
+
+// HandleGetObjectLockConfiguration handles GET /{bucket}?object-lock
+func HandleGetObjectLockConfiguration(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	cfg, err := bucketconfig.LoadObjectLock(bucket)
+	if err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to load Object Lock configuration")
+		log.Println("Error loading Object Lock configuration:", err)
+		return
+	}
+	if cfg == nil {
+		responder.SendError(w, r, responder.ErrObjectLockConfigurationNotFound, "Object Lock configuration does not exist for this bucket")
+		return
+	}
+
+	cfg.Xmlns = "http://s3.amazonaws.com/doc/2006-03-01/"
+	sendObjectLockXML(w, cfg)
+}
+
+// HandlePutObjectRetention handles PUT /{bucket}/{key}?retention
+func HandlePutObjectRetention(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket, key := vars["bucket"], vars["key"]
+
+	var retention types.ObjectRetention
+	if err := xml.NewDecoder(io.LimitReader(r.Body, maxObjectLockBodySize)).Decode(&retention); err != nil {
+		responder.SendError(w, r, responder.ErrMalformedXML, "The XML you provided was not well-formed or did not validate against our published schema")
+		log.Println("Error decoding retention:", err)
+		return
+	}
+
+	if err := handler.PutObjectRetention(bucket, key, &retention, governanceBypass(r)); err != nil {
+		sendObjectLockError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	log.Printf("Retention updated for %s/%s", bucket, key)
+}
+
+// HandleGetObjectRetention handles GET /{bucket}/{key}?retention
+func HandleGetObjectRetention(w http.ResponseWriter, r *http.Request) {
+	md := middleware.RetrieveMetadata(r)
+	if md == nil {
+		responder.SendError(w, r, responder.ErrNoSuchKey, "The specified key does not exist")
+		return
+	}
+	if md.Retention == nil {
+		responder.SendError(w, r, responder.ErrNoSuchObjectLockConfiguration, "The specified object does not have a ObjectLock configuration")
+		return
+	}
+
+	retention := *md.Retention
+	retention.Xmlns = "http://s3.amazonaws.com/doc/2006-03-01/"
+	sendObjectLockXML(w, &retention)
+}
+
+// HandlePutObjectLegalHold handles PUT /{bucket}/{key}?legal-hold
+func HandlePutObjectLegalHold(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket, key := vars["bucket"], vars["key"]
+
+	var hold types.ObjectLegalHold
+	if err := xml.NewDecoder(io.LimitReader(r.Body, maxObjectLockBodySize)).Decode(&hold); err != nil {
+		responder.SendError(w, r, responder.ErrMalformedXML, "The XML you provided was not well-formed or did not validate against our published schema")
+		log.Println("Error decoding legal hold:", err)
+		return
+	}
+
+	if err := handler.PutObjectLegalHold(bucket, key, hold.Status); err != nil {
+		sendObjectLockError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	log.Printf("Legal hold set to %s for %s/%s", hold.Status, bucket, key)
+}
+
+// HandleGetObjectLegalHold handles GET /{bucket}/{key}?legal-hold
+func HandleGetObjectLegalHold(w http.ResponseWriter, r *http.Request) {
+	md := middleware.RetrieveMetadata(r)
+	if md == nil {
+		responder.SendError(w, r, responder.ErrNoSuchKey, "The specified key does not exist")
+		return
+	}
+	if md.LegalHold == "" {
+		responder.SendError(w, r, responder.ErrNoSuchObjectLockConfiguration, "The specified object does not have a ObjectLock configuration")
+		return
+	}
+
+	sendObjectLockXML(w, &types.ObjectLegalHold{
+		Xmlns:  "http://s3.amazonaws.com/doc/2006-03-01/",
+		Status: md.LegalHold,
+	})
+}
+
+// objectLockFromHeaders returns the retention and legal hold requested by the
+// x-amz-object-lock-* headers of an upload.
+func objectLockFromHeaders(r *http.Request) (*types.ObjectRetention, string, error) {
+	mode := r.Header.Get("x-amz-object-lock-mode")
+	until := r.Header.Get("x-amz-object-lock-retain-until-date")
+	legalHold := r.Header.Get("x-amz-object-lock-legal-hold")
+
+	if mode == "" && until == "" {
+		return nil, legalHold, nil
+	}
+	if mode == "" || until == "" {
+		return nil, "", fmt.Errorf("%w: x-amz-object-lock-mode and x-amz-object-lock-retain-until-date must both be supplied", handler.ErrInvalidRetention)
+	}
+	date, err := time.Parse(time.RFC3339, until)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: the retain until date must be provided in ISO 8601 format", handler.ErrInvalidRetention)
+	}
+	return &types.ObjectRetention{Mode: mode, RetainUntilDate: types.IsoTime(date)}, legalHold, nil
+}
+
+// governanceBypass reports whether the request asks to bypass GOVERNANCE
+// retention and the caller holds s3:BypassGovernanceRetention.
+func governanceBypass(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("x-amz-bypass-governance-retention"), "true") &&
+		middleware.AllowedTo(r, types.ActionBypassGovernance)
+}
+
+// setObjectLockHeaders describes the lock of an object on GET and HEAD responses.
+func setObjectLockHeaders(h http.Header, md *types.ObjectMetadata) {
+	if md.Retention != nil {
+		h.Set("x-amz-object-lock-mode", md.Retention.Mode)
+		h.Set("x-amz-object-lock-retain-until-date", time.Time(md.Retention.RetainUntilDate).UTC().Format(time.RFC3339))
+	}
+	if md.LegalHold != "" {
+		h.Set("x-amz-object-lock-legal-hold", md.LegalHold)
+	}
+}
+
+// isObjectLockError reports whether err is an Object Lock refusal.
+func isObjectLockError(err error) bool {
+	return errors.Is(err, handler.ErrObjectLocked) ||
+		errors.Is(err, handler.ErrObjectLockNotEnabled) ||
+		errors.Is(err, handler.ErrInvalidRetention)
+}
+
+// sendObjectLockError reports a rejected Object Lock operation as a client
+// error and anything else as an internal error.
+func sendObjectLockError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, handler.ErrObjectLocked):
+		responder.SendError(w, r, responder.ErrAccessDenied, "Access Denied because object protected by object lock.")
+	case errors.Is(err, handler.ErrObjectLockNotEnabled):
+		responder.SendError(w, r, responder.ErrInvalidRequest, "Bucket is missing Object Lock Configuration")
+	case errors.Is(err, handler.ErrInvalidRetention):
+		responder.SendError(w, r, responder.ErrInvalidArgument, err.Error())
+	case errors.Is(err, handler.ErrNoSuchKey):
+		responder.SendError(w, r, responder.ErrNoSuchKey, "The specified key does not exist")
+	default:
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to update Object Lock settings")
+	}
+	log.Println("Object Lock operation failed:", err)
+}
+
+func sendObjectLockXML(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	if err := xml.NewEncoder(w).Encode(v); err != nil {
+		log.Println("XML encode error:", err)
+	}
+}
@@ -0,0 +1,121 @@
+package routers
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aidenappl/openbucket-go/handler"
+	"github.com/aidenappl/openbucket-go/middleware"
+	"github.com/aidenappl/openbucket-go/replication"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/aidenappl/openbucket-go/types"
+	"github.com/gorilla/mux"
+)
+
+// maxTaggingBodySize caps the size of Tagging request bodies.
+const maxTaggingBodySize = 16 * 1024
+
+// HandlePutObjectTagging handles PUT /{bucket}/{key}?tagging
+func HandlePutObjectTagging(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket, key := vars["bucket"], vars["key"]
+
+	var tagging types.Tagging
+	if err := xml.NewDecoder(io.LimitReader(r.Body, maxTaggingBodySize)).Decode(&tagging); err != nil {
+		responder.SendError(w, r, responder.ErrMalformedXML, "The XML you provided was not well-formed or did not validate against our published schema")
+		log.Println("Error decoding tagging:", err)
+		return
+	}
+
+	md, err := handler.PutObjectTagging(bucket, key, tagging.TagSet)
+	if err != nil {
+		sendTaggingError(w, r, err)
+		return
+	}
+
+	// Replicas are sent whole, so the new tags go with the content
+	replication.Enqueue(md)
+
+	w.WriteHeader(http.StatusOK)
+	log.Printf("Tags updated for %s/%s", bucket, key)
+}
+
+// HandleGetObjectTagging handles GET /{bucket}/{key}?tagging
+func HandleGetObjectTagging(w http.ResponseWriter, r *http.Request) {
+	md := middleware.RetrieveMetadata(r)
+	if md == nil {
+		responder.SendError(w, r, responder.ErrNoSuchKey, "The specified key does not exist")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	if err := xml.NewEncoder(w).Encode(types.Tagging{
+		Xmlns:  "http://s3.amazonaws.com/doc/2006-03-01/",
+		TagSet: md.Tags,
+	}); err != nil {
+		log.Println("XML encode error:", err)
+	}
+}
+
+// HandleDeleteObjectTagging handles DELETE /{bucket}/{key}?tagging
+func HandleDeleteObjectTagging(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket, key := vars["bucket"], vars["key"]
+
+	md, err := handler.PutObjectTagging(bucket, key, nil)
+	if err != nil {
+		sendTaggingError(w, r, err)
+		return
+	}
+
+	replication.Enqueue(md)
+
+	w.WriteHeader(http.StatusNoContent)
+	log.Printf("Tags deleted for %s/%s", bucket, key)
+}
+
+// tagsFromHeader returns the tags of an upload, given in the x-amz-tagging
+// header as URL query parameters, or nil when the header is absent.
+func tagsFromHeader(r *http.Request) ([]types.Tag, error) {
+	header := r.Header.Get("x-amz-tagging")
+	if header == "" {
+		return nil, nil
+	}
+
+	// The order of the tags is kept, so the pairs are split by hand
+	var tags []types.Tag
+	for _, pair := range strings.Split(header, "&") {
+		k, v, _ := strings.Cut(pair, "=")
+		key, err := url.QueryUnescape(k)
+		if err != nil {
+			return nil, fmt.Errorf("%w: the x-amz-tagging header is not URL encoded", handler.ErrInvalidTag)
+		}
+		value, err := url.QueryUnescape(v)
+		if err != nil {
+			return nil, fmt.Errorf("%w: the x-amz-tagging header is not URL encoded", handler.ErrInvalidTag)
+		}
+		tags = append(tags, types.Tag{Key: key, Value: value})
+	}
+	return tags, handler.ValidateTags(tags)
+}
+
+// sendTaggingError reports a rejected tag set as a client error and anything
+// else as an internal error.
+func sendTaggingError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, handler.ErrInvalidTag):
+		responder.SendError(w, r, responder.ErrInvalidTag, err.Error())
+	case errors.Is(err, handler.ErrNoSuchKey):
+		responder.SendError(w, r, responder.ErrNoSuchKey, "The specified key does not exist")
+	default:
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to update object tags")
+	}
+	log.Println("Tagging operation failed:", err)
+}
@@ -0,0 +1,135 @@
+package routers
+
+import (
+	"encoding/xml"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/aidenappl/openbucket-go/auth"
+	"github.com/aidenappl/openbucket-go/aws"
+	"github.com/aidenappl/openbucket-go/events"
+	"github.com/aidenappl/openbucket-go/handler"
+	"github.com/aidenappl/openbucket-go/middleware"
+	"github.com/aidenappl/openbucket-go/replication"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/aidenappl/openbucket-go/types"
+	"github.com/gorilla/mux"
+)
+
+// HandlePostObject handles browser POST uploads to /{bucket}. The form has
+// already been read up to the file and its policy checked by
+// middleware.Authorized; the object is written like a PUT upload.
+func HandlePostObject(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bucket, key := vars["bucket"], vars["key"]
+
+	upload := middleware.RetrievePostUpload(r)
+	if upload == nil {
+		responder.SendError(w, r, responder.ErrMalformedPOSTRequest, "The body of your POST request is not well-formed multipart/form-data.")
+		return
+	}
+
+	user := middleware.RetrieveSession(r)
+	if user == nil {
+		responder.SendError(w, r, responder.ErrAccessDenied, "")
+		log.Println("Unauthorized access attempt")
+		return
+	}
+
+	// The acl field takes a canned ACL, as x-amz-acl does for PUT
+	owner := types.UserObject{ID: user.KeyID, DisplayName: user.Name}
+	grants := []types.Grant{auth.NewGrant(owner.ID, owner.DisplayName, types.FULL_CONTROL)}
+	if canned := upload.Fields["acl"]; canned != "" {
+		var bucketOwner types.UserObject
+		if perms := middleware.RetrievePermissions(r); perms != nil {
+			bucketOwner = perms.Owner
+		}
+		var err error
+		if grants, err = auth.CannedObjectGrants(canned, owner, bucketOwner); err != nil {
+			sendACLError(w, r, err)
+			return
+		}
+	}
+
+	// Form field names are lowercased
+	encrypt, customerKey, err := uploadEncryption(r, func(name string) string { return upload.Fields[strings.ToLower(name)] })
+	if err != nil {
+		sendEncryptionError(w, r, err)
+		return
+	}
+
+	md, err := handler.PutObject(bucket, key, upload.File, owner, grants, handler.PutOptions{Encrypt: encrypt, CustomerKey: customerKey})
+	switch {
+	case errors.Is(err, aws.ErrEntityTooLarge):
+		responder.SendError(w, r, responder.ErrEntityTooLarge, "Your proposed upload exceeds the maximum allowed size")
+		log.Println("Rejected POST upload:", err)
+		return
+	case errors.Is(err, aws.ErrEntityTooSmall):
+		responder.SendError(w, r, responder.ErrEntityTooSmall, "Your proposed upload is smaller than the minimum allowed size")
+		log.Println("Rejected POST upload:", err)
+		return
+	case errors.Is(err, handler.ErrNoSuchBucket):
+		responder.SendError(w, r, responder.ErrNoSuchBucket, "The specified bucket does not exist")
+		return
+	case isObjectLockError(err):
+		sendObjectLockError(w, r, err)
+		return
+	case err != nil:
+		responder.SendError(w, r, responder.ErrInternalError, "Error saving file")
+		log.Println("Error uploading file:", err)
+		return
+	}
+
+	replication.Enqueue(md)
+	setEncryptionHeaders(w.Header(), md, customerKey)
+	etag := `"` + md.ETag + `"`
+	w.Header().Set("ETag", etag)
+	location := "/" + bucket + "/" + key
+	w.Header().Set("Location", location)
+	log.Println("File uploaded by POST:", bucket+"/"+key)
+	emitEvent(r, events.ObjectCreatedPost, bucket, key, md)
+
+	sendPostResult(w, r, upload, &types.PostResponse{
+		Location: location,
+		Bucket:   bucket,
+		Key:      key,
+		ETag:     etag,
+	})
+}
+
+// sendPostResult answers a successful POST upload: a 303 redirect when
+// success_action_redirect is a valid URL, otherwise the status requested by
+// success_action_status, defaulting to 204.
+func sendPostResult(w http.ResponseWriter, r *http.Request, upload *middleware.PostUpload, result *types.PostResponse) {
+	redirect := upload.Fields["success_action_redirect"]
+	if redirect == "" {
+		redirect = upload.Fields["redirect"]
+	}
+	if target, err := url.Parse(redirect); redirect != "" && err == nil && target.IsAbs() {
+		query := target.Query()
+		query.Set("bucket", result.Bucket)
+		query.Set("key", result.Key)
+		query.Set("etag", result.ETag)
+		target.RawQuery = query.Encode()
+		http.Redirect(w, r, target.String(), http.StatusSeeOther)
+		return
+	}
+
+	status, _ := strconv.Atoi(upload.Fields["success_action_status"])
+	switch status {
+	case http.StatusOK:
+		w.WriteHeader(http.StatusOK)
+	case http.StatusCreated:
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusCreated)
+		if err := xml.NewEncoder(w).Encode(result); err != nil {
+			log.Println("XML encode error:", err)
+		}
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
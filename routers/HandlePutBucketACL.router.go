@@ -0,0 +1,80 @@
+package routers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/aidenappl/openbucket-go/auth"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/aidenappl/openbucket-go/types"
+	"github.com/gorilla/mux"
+)
+
+// bucketACL is a replacement ACL for a bucket: a canned ACL plus explicit grants.
+type bucketACL struct {
+	ACL    types.Permission
+	Grants []types.Grant
+}
+
+// HandlePutBucketACL handles PUT /{bucket}?acl. The ACL is taken from the
+// x-amz-acl header, the x-amz-grant-* headers or an AccessControlPolicy body,
+// in that order, and replaces the bucket's existing ACL. The caller's
+// WRITE_ACP permission has already been checked by middleware.Authorized.
+func HandlePutBucketACL(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	acl, err := bucketACLFromHeaders(r)
+	if err == nil && acl == nil {
+		var grants []types.Grant
+		grants, err = aclFromBody(r)
+		acl = &bucketACL{ACL: types.BUCKET_ACLPrivate, Grants: grants}
+	}
+	if err != nil {
+		sendACLError(w, r, err)
+		return
+	}
+
+	if err := setBucketACL(bucket, acl); err != nil {
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to save bucket ACL")
+		log.Println("Error saving bucket ACL:", err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	log.Printf("Bucket ACL updated for bucket %s: %s with %d grants", bucket, acl.ACL, len(acl.Grants))
+}
+
+// bucketACLFromHeaders returns the ACL requested through x-amz-acl or the
+// x-amz-grant-* headers, or nil when neither is set. The two cannot be combined.
+func bucketACLFromHeaders(r *http.Request) (*bucketACL, error) {
+	canned := r.Header.Get("x-amz-acl")
+	hasGrants := auth.HasGrantHeaders(r.Header)
+
+	switch {
+	case canned != "" && hasGrants:
+		return nil, fmt.Errorf("%w: x-amz-acl cannot be combined with x-amz-grant-* headers", auth.ErrInvalidACL)
+	case canned != "":
+		acl, err := auth.CannedBucketACL(canned)
+		if err != nil {
+			return nil, err
+		}
+		return &bucketACL{ACL: acl}, nil
+	case hasGrants:
+		grants, err := auth.GrantsFromHeaders(r.Header)
+		if err != nil {
+			return nil, err
+		}
+		return &bucketACL{ACL: types.BUCKET_ACLPrivate, Grants: grants}, nil
+	}
+	return nil, nil
+}
+
+// setBucketACL replaces the canned ACL and grants of a bucket.
+func setBucketACL(bucket string, acl *bucketACL) error {
+	return auth.ModifyBucketPermissions(bucket, func(permissions *types.Bucket) error {
+		permissions.ACL = acl.ACL
+		permissions.Grants = acl.Grants
+		return nil
+	})
+}
@@ -0,0 +1,157 @@
+package routers
+
+import (
+	"encoding/xml"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/aidenappl/openbucket-go/handler"
+	"github.com/aidenappl/openbucket-go/middleware"
+	"github.com/aidenappl/openbucket-go/oidc"
+	"github.com/aidenappl/openbucket-go/policy"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// HandleSTS handles POST / requests of the STS query API, dispatching on the
+// Action parameter.
+func HandleSTS(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r)
+
+	if err := r.ParseForm(); err != nil {
+		responder.SendSTSError(w, http.StatusBadRequest, "InvalidParameterValue", "Unable to parse request parameters", requestID)
+		return
+	}
+
+	switch action := r.Form.Get("Action"); action {
+	case "GetSessionToken":
+		handleGetSessionToken(w, r)
+	case "AssumeRole":
+		handleAssumeRole(w, r)
+	case "AssumeRoleWithWebIdentity":
+		handleAssumeRoleWithWebIdentity(w, r)
+	default:
+		responder.SendSTSError(w, http.StatusBadRequest, "InvalidAction", "Unsupported STS action: "+action, requestID)
+	}
+}
+
+func handleGetSessionToken(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r)
+	session := middleware.RetrieveSession(r)
+
+	duration, ok := durationSeconds(w, r)
+	if !ok {
+		return
+	}
+
+	creds, err := handler.GetSessionToken(session, duration, r.Form.Get("Policy"))
+	if err != nil {
+		sendSTSError(w, r, err)
+		return
+	}
+
+	sendSTSResult(w, &types.GetSessionTokenResponse{
+		Xmlns:            types.STSNamespace,
+		Credentials:      *creds,
+		ResponseMetadata: types.ResponseMetadata{RequestID: requestID},
+	})
+	log.Println("Issued session token for", session.KeyID, "as", creds.AccessKeyID)
+}
+
+func handleAssumeRole(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r)
+	session := middleware.RetrieveSession(r)
+
+	duration, ok := durationSeconds(w, r)
+	if !ok {
+		return
+	}
+
+	trustReq := policy.NewRequest(r, session.KeyID, "sts:AssumeRole", "", "")
+	creds, user, err := handler.AssumeRole(trustReq, session, r.Form.Get("RoleArn"), r.Form.Get("RoleSessionName"), duration, r.Form.Get("Policy"))
+	if err != nil {
+		sendSTSError(w, r, err)
+		return
+	}
+
+	sendSTSResult(w, &types.AssumeRoleResponse{
+		Xmlns:            types.STSNamespace,
+		Credentials:      *creds,
+		AssumedRoleUser:  *user,
+		ResponseMetadata: types.ResponseMetadata{RequestID: requestID},
+	})
+	log.Println("Issued role session", user.Arn, "to", session.KeyID, "as", creds.AccessKeyID)
+}
+
+func handleAssumeRoleWithWebIdentity(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r)
+
+	duration, ok := durationSeconds(w, r)
+	if !ok {
+		return
+	}
+
+	creds, user, identity, err := handler.AssumeRoleWithWebIdentity(r.Form.Get("WebIdentityToken"), r.Form.Get("RoleArn"), r.Form.Get("RoleSessionName"), duration, r.Form.Get("Policy"))
+	if err != nil {
+		sendSTSError(w, r, err)
+		return
+	}
+
+	sendSTSResult(w, &types.AssumeRoleWithWebIdentityResponse{
+		Xmlns:                       types.STSNamespace,
+		Credentials:                 *creds,
+		AssumedRoleUser:             *user,
+		SubjectFromWebIdentityToken: identity.Subject,
+		Provider:                    identity.Issuer,
+		Audience:                    identity.Audience,
+		ResponseMetadata:            types.ResponseMetadata{RequestID: requestID},
+	})
+	log.Println("Issued role session", user.Arn, "to", identity.Subject, "of", identity.Issuer, "as", creds.AccessKeyID)
+}
+
+// durationSeconds reads the optional DurationSeconds parameter; zero means
+// the action's default.
+func durationSeconds(w http.ResponseWriter, r *http.Request) (int, bool) {
+	raw := r.Form.Get("DurationSeconds")
+	if raw == "" {
+		return 0, true
+	}
+	d, err := strconv.Atoi(raw)
+	if err != nil || d <= 0 {
+		responder.SendSTSError(w, http.StatusBadRequest, "ValidationError", "DurationSeconds must be a positive integer", middleware.GetRequestID(r))
+		return 0, false
+	}
+	return d, true
+}
+
+func sendSTSResult(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "text/xml")
+	w.WriteHeader(http.StatusOK)
+	if err := xml.NewEncoder(w).Encode(v); err != nil {
+		log.Println("XML encode error:", err)
+	}
+}
+
+// sendSTSError maps handler errors to STS error codes.
+func sendSTSError(w http.ResponseWriter, r *http.Request, err error) {
+	requestID := middleware.GetRequestID(r)
+
+	switch {
+	case errors.Is(err, handler.ErrInvalidInput):
+		responder.SendSTSError(w, http.StatusBadRequest, "ValidationError", err.Error(), requestID)
+	case errors.Is(err, oidc.ErrExpiredToken):
+		responder.SendSTSError(w, http.StatusBadRequest, "ExpiredTokenException", "Token is expired", requestID)
+	case errors.Is(err, oidc.ErrInvalidToken):
+		responder.SendSTSError(w, http.StatusBadRequest, "InvalidIdentityToken", "Invalid web identity token", requestID)
+		log.Println("STS request rejected:", err)
+	case errors.Is(err, handler.ErrNoSuchEntity), errors.Is(err, handler.ErrAccessDenied):
+		// Unknown roles are reported like forbidden ones so role names cannot be probed
+		responder.SendSTSError(w, http.StatusForbidden, "AccessDenied", "Not authorized to perform sts:"+r.Form.Get("Action"), requestID)
+		log.Println("STS request denied:", err)
+	default:
+		responder.SendSTSError(w, http.StatusInternalServerError, "InternalFailure", "Unable to issue credentials", requestID)
+		log.Println("STS request failed:", err)
+	}
+}
@@ -0,0 +1,22 @@
+package routers
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/aidenappl/openbucket-go/responder"
+)
+
+// HandleMethodNotAllowed answers requests whose path is routed but not for
+// their method.
+func HandleMethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	responder.SendError(w, r, responder.ErrMethodNotAllowed, "")
+	log.Println("Method not allowed:", r.Method, r.URL.Path)
+}
+
+// HandleNotImplemented answers requests that match no route, such as
+// subresources this server does not support.
+func HandleNotImplemented(w http.ResponseWriter, r *http.Request) {
+	responder.SendError(w, r, responder.ErrNotImplemented, "The requested operation is not implemented")
+	log.Println("No route for", r.Method, r.URL.Path)
+}
@@ -1,17 +1,16 @@
 package routers
 
 import (
-	"encoding/xml"
-	"io"
+	"errors"
 	"log"
 	"net/http"
-	"os"
-	"path/filepath"
-	"strings"
-	"time"
 
+	"github.com/aidenappl/openbucket-go/auth"
+	"github.com/aidenappl/openbucket-go/events"
+	"github.com/aidenappl/openbucket-go/handler"
 	"github.com/aidenappl/openbucket-go/middleware"
-	"github.com/aidenappl/openbucket-go/tools"
+	"github.com/aidenappl/openbucket-go/replication"
+	"github.com/aidenappl/openbucket-go/responder"
 	"github.com/aidenappl/openbucket-go/types"
 	"github.com/gorilla/mux"
 )
@@ -23,116 +22,129 @@ func HandleUpload(w http.ResponseWriter, r *http.Request) {
 	key := vars["key"]
 
 	if bucket == "" || key == "" {
-		http.Error(w, "Bucket and key must be provided", http.StatusBadRequest)
+		responder.SendError(w, r, responder.ErrInvalidRequest, "Bucket and key must be provided")
 		log.Println("Bucket or key is empty")
 		return
 	}
-
-	user := middleware.RetrieveSession(r)
-	if user == nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		log.Println("Unauthorized access attempt")
+	if r.ContentLength > handler.MaxObjectSize {
+		responder.SendError(w, r, responder.ErrEntityTooLarge, "")
+		log.Println("Rejected upload of", r.ContentLength, "bytes to", bucket+"/"+key)
 		return
 	}
 
-	filePath := filepath.Join("buckets", bucket, key)
+	owner, grants, opts, ok := uploadSettings(w, r)
+	if !ok {
+		return
+	}
+	if r.Header.Get("x-amz-copy-source") != "" {
+		handleCopyObject(w, r, owner, grants, opts)
+		return
+	}
 
-	bucketDir := filepath.Join("buckets", bucket)
-	if _, err := os.Stat(bucketDir); os.IsNotExist(err) {
-		http.Error(w, "Bucket not found", http.StatusNotFound)
-		log.Println("Bucket not found:", bucketDir)
+	md, err := handler.PutObject(bucket, key, r.Body, owner, grants, opts)
+	if errors.Is(err, handler.ErrNoSuchBucket) {
+		responder.SendError(w, r, responder.ErrNoSuchBucket, "")
+		log.Println("Bucket not found:", bucket)
+		return
+	} else if isObjectLockError(err) {
+		sendObjectLockError(w, r, err)
 		return
 	} else if err != nil {
-		http.Error(w, "Unable to access bucket", http.StatusInternalServerError)
-		log.Println("Error accessing bucket:", err)
+		responder.SendError(w, r, responder.ErrInternalError, "Unable to save object")
+		log.Println("Error uploading file:", err)
 		return
 	}
 
-	isDirectory := strings.HasSuffix(key, "/")
-	if isDirectory {
+	replication.Enqueue(md)
+	setEncryptionHeaders(w.Header(), md, opts.CustomerKey)
+	w.WriteHeader(http.StatusOK)
+	log.Println("File uploaded successfully. ETag:", md.ETag)
+	emitEvent(r, events.ObjectCreatedPut, bucket, key, md)
+}
 
-		err := os.MkdirAll(filePath, os.ModePerm)
-		if err != nil {
-			http.Error(w, "Failed to create directory", http.StatusInternalServerError)
-			log.Println("Error creating directory:", err)
-			return
-		}
-		w.WriteHeader(http.StatusOK)
-		log.Println("Directory created:", filePath)
-		return
+// uploadSettings resolves the owner, ACL, Object Lock, encryption, replica
+// status, website redirect and tags of an object written by PUT, copy or
+// multipart upload from the request headers. It answers the request itself
+// and returns false when they are rejected.
+func uploadSettings(w http.ResponseWriter, r *http.Request) (types.UserObject, []types.Grant, handler.PutOptions, bool) {
+	user := middleware.RetrieveSession(r)
+	if user == nil {
+		responder.SendError(w, r, responder.ErrAccessDenied, "")
+		log.Println("Unauthorized access attempt")
+		return types.UserObject{}, nil, handler.PutOptions{}, false
 	}
 
-	err := os.MkdirAll(filepath.Dir(filePath), os.ModePerm)
-	if err != nil {
-		http.Error(w, "Failed to create directory", http.StatusInternalServerError)
-		log.Println("Error creating directory:", err)
-		return
+	// Resolve the object ACL before writing anything, so an invalid ACL leaves
+	// no object behind. Without ACL headers the object is private.
+	owner := types.UserObject{ID: user.KeyID, DisplayName: user.Name}
+	var bucketOwner types.UserObject
+	if perms := middleware.RetrievePermissions(r); perms != nil {
+		bucketOwner = perms.Owner
 	}
-
-	file, err := os.Create(filePath)
+	grants, err := objectACLFromHeaders(r, owner, bucketOwner)
 	if err != nil {
-		http.Error(w, "Unable to create file", http.StatusInternalServerError)
-		log.Println("Error creating file:", err)
-		return
+		sendACLError(w, r, err)
+		return types.UserObject{}, nil, handler.PutOptions{}, false
 	}
-	defer file.Close()
-
-	stat, err := file.Stat()
-	if err != nil {
-		http.Error(w, "Unable to get file info", http.StatusInternalServerError)
-		log.Println("Error getting file info:", err)
-		return
+	if grants == nil {
+		grants = []types.Grant{auth.NewGrant(owner.ID, owner.DisplayName, types.FULL_CONTROL)}
 	}
 
-	_, err = io.Copy(file, r.Body)
+	// Locking the new object needs the permissions of the ?retention and
+	// ?legal-hold subresources as well
+	retention, legalHold, err := objectLockFromHeaders(r)
 	if err != nil {
-		http.Error(w, "Error saving file", http.StatusInternalServerError)
-		log.Println("Error saving file:", err)
-		return
+		sendObjectLockError(w, r, err)
+		return types.UserObject{}, nil, handler.PutOptions{}, false
+	}
+	if (retention != nil && !middleware.AllowedTo(r, types.ActionPutRetention)) ||
+		(legalHold != "" && !middleware.AllowedTo(r, types.ActionPutLegalHold)) {
+		responder.SendError(w, r, responder.ErrAccessDenied, "")
+		log.Println("Forbidden: object lock headers require s3:PutObjectRetention and s3:PutObjectLegalHold")
+		return types.UserObject{}, nil, handler.PutOptions{}, false
 	}
 
-	etag, err := tools.GenerateETag(filePath)
+	// Tagging the new object needs the permission of the ?tagging subresource
+	tags, err := tagsFromHeader(r)
 	if err != nil {
-		http.Error(w, "Error generating ETag", http.StatusInternalServerError)
-		log.Println("Error generating ETag:", err)
-		return
+		sendTaggingError(w, r, err)
+		return types.UserObject{}, nil, handler.PutOptions{}, false
 	}
-
-	metadata := &types.ObjectMetadata{
-		ETag:         etag,
-		Key:          key,
-		Bucket:       bucket,
-		Owner:        types.UserObject{ID: user.KeyID, DisplayName: user.Name},
-		Public:       false,
-		LastModified: types.IsoTime(time.Now()),
-		UploadedAt:   types.IsoTime(time.Now()),
-		VersionId:    "1",
-		Size:         stat.Size(),
+	if tags != nil && !middleware.AllowedTo(r, types.ActionPutTagging) {
+		responder.SendError(w, r, responder.ErrAccessDenied, "")
+		log.Println("Forbidden: the x-amz-tagging header requires s3:PutObjectTagging")
+		return types.UserObject{}, nil, handler.PutOptions{}, false
 	}
 
-	metadataFilePath := filePath + ".obmeta"
-	metadataFile, err := os.Create(metadataFilePath)
+	encrypt, customerKey, err := uploadEncryption(r, r.Header.Get)
 	if err != nil {
-		http.Error(w, "Error saving metadata", http.StatusInternalServerError)
-		log.Println("Error saving metadata:", err)
-		return
+		sendEncryptionError(w, r, err)
+		return types.UserObject{}, nil, handler.PutOptions{}, false
 	}
-	defer metadataFile.Close()
 
-	metadataXML, err := xml.MarshalIndent(metadata, "", "  ")
-	if err != nil {
-		log.Println("Error marshalling metadata to XML:", err)
-		http.Error(w, "Error marshalling metadata", http.StatusInternalServerError)
-		return
+	replica, ok := replicaRequest(w, r, types.ActionReplicateObject)
+	if !ok {
+		return types.UserObject{}, nil, handler.PutOptions{}, false
+	}
+	var replicationStatus string
+	if replica {
+		replicationStatus = types.ReplicationReplica
 	}
 
-	_, err = metadataFile.WriteString(string(metadataXML))
-	if err != nil {
-		log.Println("Error writing to metadata file:", err)
-		http.Error(w, "Error writing to metadata file", http.StatusInternalServerError)
-		return
+	redirect, ok := websiteRedirectFromHeaders(w, r)
+	if !ok {
+		return types.UserObject{}, nil, handler.PutOptions{}, false
 	}
 
-	w.WriteHeader(http.StatusOK)
-	log.Println("File uploaded successfully. ETag:", etag)
+	return owner, grants, handler.PutOptions{
+		Retention:        retention,
+		LegalHold:        legalHold,
+		BypassGovernance: governanceBypass(r),
+		Encrypt:          encrypt,
+		CustomerKey:      customerKey,
+
+		ReplicationStatus:       replicationStatus,
+		WebsiteRedirectLocation: redirect,
+		Tags:                    tags,
+	}, true
 }
@@ -0,0 +1,239 @@
+package routers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/auth"
+	"github.com/aidenappl/openbucket-go/bucketconfig"
+	"github.com/aidenappl/openbucket-go/env"
+	"github.com/aidenappl/openbucket-go/handler"
+	"github.com/aidenappl/openbucket-go/metadata"
+	"github.com/aidenappl/openbucket-go/middleware"
+	"github.com/aidenappl/openbucket-go/responder"
+	"github.com/aidenappl/openbucket-go/sse"
+	"github.com/aidenappl/openbucket-go/tools"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// HandleWebsite serves the website endpoint. The bucket is named by the Host
+// header, either as "<bucket>.<WEBSITE_DOMAIN>" or as the whole host name for
+// buckets named after their site. Requests are anonymous: objects are only
+// served when the bucket policy or a public ACL lets everyone read them.
+// Errors are HTML pages, or the bucket's error document.
+func HandleWebsite(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		responder.SendHTMLError(w, r, responder.ErrMethodNotAllowed, "")
+		return
+	}
+
+	bucket := websiteBucket(r.Host)
+	if !tools.ValidBucketName(bucket) {
+		responder.SendHTMLError(w, r, responder.ErrNoSuchBucket, "")
+		return
+	}
+	if _, err := auth.LoadBucketPermissions(bucket); errors.Is(err, os.ErrNotExist) {
+		responder.SendHTMLError(w, r, responder.ErrNoSuchBucket, "")
+		return
+	} else if err != nil {
+		responder.SendHTMLError(w, r, responder.ErrInternalError, "")
+		log.Println("Error loading permissions for bucket "+bucket+":", err)
+		return
+	}
+
+	cfg, err := bucketconfig.LoadWebsite(bucket)
+	if err != nil {
+		responder.SendHTMLError(w, r, responder.ErrInternalError, "")
+		log.Println("Error loading website configuration:", err)
+		return
+	} else if cfg == nil {
+		responder.SendHTMLError(w, r, responder.ErrNoSuchWebsiteConfiguration, "")
+		return
+	}
+
+	if all := cfg.RedirectAllRequestsTo; all != nil {
+		location := websiteProtocol(r, all.Protocol) + "://" + all.HostName + r.URL.EscapedPath()
+		if r.URL.RawQuery != "" {
+			location += "?" + r.URL.RawQuery
+		}
+		redirect(w, location, http.StatusMovedPermanently)
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/")
+	if rule := bucketconfig.WebsiteRoutingRule(cfg, key, 0); rule != nil {
+		routingRedirect(w, r, rule, key)
+		return
+	}
+	serveWebsiteObject(w, r, bucket, cfg, key)
+}
+
+// serveWebsiteObject answers a website request for key, serving the index
+// document for the root and keys ending in "/".
+func serveWebsiteObject(w http.ResponseWriter, r *http.Request, bucket string, cfg *types.WebsiteConfiguration, key string) {
+	objectKey := key
+	if key == "" || strings.HasSuffix(key, "/") {
+		objectKey += cfg.IndexDocument.Suffix
+	}
+	if len(objectKey) > tools.MaxKeyLength {
+		websiteError(w, r, bucket, cfg, key, responder.ErrKeyTooLong)
+		return
+	}
+
+	md, err := metadata.Load(bucket, objectKey)
+	if errors.Is(err, os.ErrNotExist) {
+		// A "directory" without its slash is redirected to the directory,
+		// so relative links on its index page resolve
+		if objectKey == key && key != "" {
+			if _, err := metadata.Load(bucket, key+"/"+cfg.IndexDocument.Suffix); err == nil {
+				redirect(w, (&url.URL{Path: "/" + key + "/"}).EscapedPath(), http.StatusFound)
+				return
+			}
+		}
+		// Like S3, only callers who may list the bucket learn that a key is missing
+		if middleware.AllowedAnonymously(r, types.ActionListBucket, bucket, "") {
+			websiteError(w, r, bucket, cfg, key, responder.ErrNoSuchKey)
+		} else {
+			websiteError(w, r, bucket, cfg, key, responder.ErrAccessDenied)
+		}
+		return
+	} else if err != nil {
+		websiteError(w, r, bucket, cfg, key, responder.ErrInternalError)
+		log.Println("Error loading object metadata:", err)
+		return
+	}
+
+	if !middleware.AllowedAnonymously(r, types.ActionGetObject, bucket, objectKey) {
+		websiteError(w, r, bucket, cfg, key, responder.ErrAccessDenied)
+		return
+	}
+	if md.WebsiteRedirectLocation != "" {
+		redirect(w, md.WebsiteRedirectLocation, http.StatusMovedPermanently)
+		return
+	}
+
+	if !serveWebsiteContent(w, r, md, http.StatusOK) {
+		websiteError(w, r, bucket, cfg, key, responder.ErrAccessDenied)
+	}
+}
+
+// serveWebsiteContent sends the content of md with status. Objects encrypted
+// with a customer-provided key cannot be served without the key; for those,
+// and objects whose content is missing, it returns false without answering.
+func serveWebsiteContent(w http.ResponseWriter, r *http.Request, md *types.ObjectMetadata, status int) bool {
+	if sse.IsCustomerKey(md.Encryption) {
+		return false
+	}
+	content, err := handler.OpenContent(md.Bucket, md.Key, md, nil)
+	if err != nil {
+		log.Println("Error opening website object", md.Bucket+"/"+md.Key+":", err)
+		return false
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", tools.ContentType(md.Key))
+	if md.ETag != "" {
+		w.Header().Set("ETag", md.ETag)
+	}
+
+	// ServeContent answers Range and conditional requests, which only apply
+	// to the object itself and not to error documents
+	if status == http.StatusOK {
+		http.ServeContent(w, r, "", time.Time(md.LastModified), content)
+	} else {
+		w.Header().Set("Content-Length", strconv.FormatInt(md.Size, 10))
+		w.WriteHeader(status)
+		if r.Method != http.MethodHead {
+			_, err = io.Copy(w, content)
+		}
+	}
+	if err == nil {
+		err = content.Err()
+	}
+	if err != nil {
+		log.Println("Error reading website object", md.Bucket+"/"+md.Key+":", err)
+	}
+	return true
+}
+
+// websiteError answers a website request for key that failed with e. A
+// routing rule for the error code redirects the request; otherwise 4xx
+// errors are answered with the bucket's error document when it is readable.
+func websiteError(w http.ResponseWriter, r *http.Request, bucket string, cfg *types.WebsiteConfiguration, key string, e responder.APIError) {
+	if rule := bucketconfig.WebsiteRoutingRule(cfg, key, e.Status); rule != nil {
+		routingRedirect(w, r, rule, key)
+		return
+	}
+
+	if doc := cfg.ErrorDocument; doc != nil && e.Status >= 400 && e.Status < 500 {
+		md, err := metadata.Load(bucket, doc.Key)
+		if err == nil && middleware.AllowedAnonymously(r, types.ActionGetObject, bucket, doc.Key) &&
+			serveWebsiteContent(w, r, md, e.Status) {
+			return
+		}
+	}
+	responder.SendHTMLError(w, r, e, "")
+}
+
+// routingRedirect redirects a website request for key as the routing rule
+// describes, keeping whatever the rule does not replace.
+func routingRedirect(w http.ResponseWriter, r *http.Request, rule *types.RoutingRule, key string) {
+	red := rule.Redirect
+	host := red.HostName
+	if host == "" {
+		host = r.Host
+	}
+	switch {
+	case red.ReplaceKeyWith != "":
+		key = red.ReplaceKeyWith
+	case red.ReplaceKeyPrefixWith != "":
+		var prefix string
+		if rule.Condition != nil {
+			prefix = rule.Condition.KeyPrefixEquals
+		}
+		key = red.ReplaceKeyPrefixWith + strings.TrimPrefix(key, prefix)
+	}
+	code := http.StatusMovedPermanently
+	if red.HttpRedirectCode != "" {
+		code, _ = strconv.Atoi(red.HttpRedirectCode)
+	}
+
+	redirect(w, websiteProtocol(r, red.Protocol)+"://"+host+(&url.URL{Path: "/" + key}).EscapedPath(), code)
+}
+
+// redirect answers with an empty redirect to location.
+func redirect(w http.ResponseWriter, location string, code int) {
+	w.Header().Set("Location", location)
+	w.WriteHeader(code)
+}
+
+// websiteProtocol returns protocol, or the protocol of r when it is empty.
+func websiteProtocol(r *http.Request, protocol string) string {
+	if protocol != "" {
+		return protocol
+	}
+	if tools.SecureTransport(r) {
+		return "https"
+	}
+	return "http"
+}
+
+// websiteBucket returns the bucket a website request is for from its host.
+func websiteBucket(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	if env.WebsiteDomain != "" {
+		host = strings.TrimSuffix(host, "."+strings.ToLower(env.WebsiteDomain))
+	}
+	return host
+}
@@ -0,0 +1,96 @@
+package routers
+
+import (
+	"net/http"
+
+	"github.com/aidenappl/openbucket-go/middleware"
+	"github.com/aidenappl/openbucket-go/types"
+	"github.com/gorilla/mux"
+)
+
+// Route maps a method, path and optional subresource query to the S3 action
+// it performs and the handler that serves it.
+type Route struct {
+	Method  string
+	Path    string
+	Queries []string // subresource pairs for mux, e.g. {"acl", ""}
+	Action  types.Action
+	Handler http.HandlerFunc
+}
+
+// Routes is the central S3 routing table. Routes with subresource queries
+// must come before the plain route for the same method and path.
+var Routes = []Route{
+	{http.MethodGet, "/", nil, types.ActionListAllMyBuckets, HandleListBuckets},
+
+	{http.MethodGet, "/{bucket}", []string{"policy", ""}, types.ActionGetBucketPolicy, HandleGetBucketPolicy},
+	{http.MethodPut, "/{bucket}", []string{"policy", ""}, types.ActionPutBucketPolicy, HandlePutBucketPolicy},
+	{http.MethodDelete, "/{bucket}", []string{"policy", ""}, types.ActionDeleteBucketPolicy, HandleDeleteBucketPolicy},
+	{http.MethodGet, "/{bucket}", []string{"cors", ""}, types.ActionGetBucketCORS, HandleGetBucketCORS},
+	{http.MethodPut, "/{bucket}", []string{"cors", ""}, types.ActionPutBucketCORS, HandlePutBucketCORS},
+	{http.MethodDelete, "/{bucket}", []string{"cors", ""}, types.ActionPutBucketCORS, HandleDeleteBucketCORS},
+	{http.MethodGet, "/{bucket}", []string{"lifecycle", ""}, types.ActionGetLifecycle, HandleGetBucketLifecycle},
+	{http.MethodPut, "/{bucket}", []string{"lifecycle", ""}, types.ActionPutLifecycle, HandlePutBucketLifecycle},
+	{http.MethodDelete, "/{bucket}", []string{"lifecycle", ""}, types.ActionPutLifecycle, HandleDeleteBucketLifecycle},
+	{http.MethodGet, "/{bucket}", []string{"object-lock", ""}, types.ActionGetObjectLock, HandleGetObjectLockConfiguration},
+	{http.MethodPut, "/{bucket}", []string{"object-lock", ""}, types.ActionPutObjectLock, HandlePutObjectLockConfiguration},
+	{http.MethodGet, "/{bucket}", []string{"encryption", ""}, types.ActionGetEncryption, HandleGetBucketEncryption},
+	{http.MethodPut, "/{bucket}", []string{"encryption", ""}, types.ActionPutEncryption, HandlePutBucketEncryption},
+	{http.MethodDelete, "/{bucket}", []string{"encryption", ""}, types.ActionPutEncryption, HandleDeleteBucketEncryption},
+	{http.MethodGet, "/{bucket}", []string{"compression", ""}, types.ActionGetCompression, HandleGetBucketCompression},
+	{http.MethodPut, "/{bucket}", []string{"compression", ""}, types.ActionPutCompression, HandlePutBucketCompression},
+	{http.MethodDelete, "/{bucket}", []string{"compression", ""}, types.ActionPutCompression, HandleDeleteBucketCompression},
+	{http.MethodGet, "/{bucket}", []string{"notification", ""}, types.ActionGetNotification, HandleGetBucketNotification},
+	{http.MethodPut, "/{bucket}", []string{"notification", ""}, types.ActionPutNotification, HandlePutBucketNotification},
+	{http.MethodGet, "/{bucket}", []string{"replication", ""}, types.ActionGetReplication, HandleGetBucketReplication},
+	{http.MethodPut, "/{bucket}", []string{"replication", ""}, types.ActionPutReplication, HandlePutBucketReplication},
+	{http.MethodDelete, "/{bucket}", []string{"replication", ""}, types.ActionPutReplication, HandleDeleteBucketReplication},
+	{http.MethodGet, "/{bucket}", []string{"website", ""}, types.ActionGetWebsite, HandleGetBucketWebsite},
+	{http.MethodPut, "/{bucket}", []string{"website", ""}, types.ActionPutWebsite, HandlePutBucketWebsite},
+	{http.MethodDelete, "/{bucket}", []string{"website", ""}, types.ActionDeleteWebsite, HandleDeleteBucketWebsite},
+	{http.MethodGet, "/{bucket}", []string{"events", "{events}"}, types.ActionListenNotification, HandleListenBucketNotification},
+	{http.MethodGet, "/{bucket}", []string{"acl", ""}, types.ActionGetBucketAcl, HandleGetBucketACL},
+	{http.MethodPut, "/{bucket}", []string{"acl", ""}, types.ActionPutBucketAcl, HandlePutBucketACL},
+	{http.MethodGet, "/{bucket}", []string{"uploads", ""}, types.ActionListUploads, HandleListMultipartUploads},
+	{http.MethodGet, "/{bucket}", nil, types.ActionListBucket, HandleListObjects},
+	{http.MethodPut, "/{bucket}", nil, types.ActionCreateBucket, HandleCreateBucket},
+	{http.MethodDelete, "/{bucket}", nil, types.ActionDeleteBucket, HandleDeleteBucket},
+	{http.MethodPost, "/{bucket}", nil, types.ActionPutObject, HandlePostObject},
+
+	{http.MethodGet, "/{bucket}/{key:.*}", []string{"acl", ""}, types.ActionGetObjectAcl, HandleGetObjectACL},
+	{http.MethodPut, "/{bucket}/{key:.*}", []string{"acl", ""}, types.ActionPutObjectAcl, HandlePutObjectACL},
+	{http.MethodGet, "/{bucket}/{key:.*}", []string{"tagging", ""}, types.ActionGetTagging, HandleGetObjectTagging},
+	{http.MethodPut, "/{bucket}/{key:.*}", []string{"tagging", ""}, types.ActionPutTagging, HandlePutObjectTagging},
+	{http.MethodDelete, "/{bucket}/{key:.*}", []string{"tagging", ""}, types.ActionDeleteTagging, HandleDeleteObjectTagging},
+	{http.MethodGet, "/{bucket}/{key:.*}", []string{"retention", ""}, types.ActionGetRetention, HandleGetObjectRetention},
+	{http.MethodPut, "/{bucket}/{key:.*}", []string{"retention", ""}, types.ActionPutRetention, HandlePutObjectRetention},
+	{http.MethodGet, "/{bucket}/{key:.*}", []string{"legal-hold", ""}, types.ActionGetLegalHold, HandleGetObjectLegalHold},
+	{http.MethodPut, "/{bucket}/{key:.*}", []string{"legal-hold", ""}, types.ActionPutLegalHold, HandlePutObjectLegalHold},
+	{http.MethodPost, "/{bucket}/{key:.*}", []string{"uploads", ""}, types.ActionPutObject, HandleCreateMultipartUpload},
+	{http.MethodPost, "/{bucket}/{key:.*}", []string{"uploadId", "{uploadId}"}, types.ActionPutObject, HandleCompleteMultipartUpload},
+	{http.MethodPut, "/{bucket}/{key:.*}", []string{"partNumber", "{partNumber}", "uploadId", "{uploadId}"}, types.ActionPutObject, HandleUploadPart},
+	{http.MethodGet, "/{bucket}/{key:.*}", []string{"uploadId", "{uploadId}"}, types.ActionListParts, HandleListParts},
+	{http.MethodDelete, "/{bucket}/{key:.*}", []string{"uploadId", "{uploadId}"}, types.ActionAbortUpload, HandleAbortMultipartUpload},
+	{http.MethodHead, "/{bucket}/{key:.*}", nil, types.ActionGetObject, HandleHeadObject},
+	{http.MethodGet, "/{bucket}/{key:.*}", nil, types.ActionGetObject, HandleDownload},
+	{http.MethodDelete, "/{bucket}/{key:.*}", nil, types.ActionDeleteObject, HandleDelete},
+	{http.MethodPut, "/{bucket}/{key:.*}", nil, types.ActionPutObject, HandleUpload},
+}
+
+// RegisterRoutes adds every route in Routes to the router, wrapped in
+// middleware.Authorized. The action is stored as the route name, which is
+// how the middleware knows what is being authorized. CORS preflights are
+// unauthenticated and registered for every bucket and object path.
+func RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/{bucket}", HandleCORSPreflight).Methods(http.MethodOptions)
+	r.HandleFunc("/{bucket}/{key:.*}", HandleCORSPreflight).Methods(http.MethodOptions)
+
+	for _, route := range Routes {
+		mr := r.HandleFunc(route.Path, middleware.Authorized(route.Handler)).
+			Methods(route.Method).
+			Name(string(route.Action))
+		if len(route.Queries) > 0 {
+			mr.Queries(route.Queries...)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package sse
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+var (
+	// ErrCustomerKeyRequired is returned when an object encrypted with a
+	// customer-provided key is accessed without one.
+	ErrCustomerKeyRequired = errors.New("object is encrypted with a customer-provided key")
+	// ErrCustomerKeyMismatch is returned when the key presented is not the one
+	// the object was encrypted with.
+	ErrCustomerKeyMismatch = errors.New("customer-provided key does not match the object")
+	// ErrCustomerKeyNotApplicable is returned when a customer-provided key is
+	// presented for an object that was not encrypted with one.
+	ErrCustomerKeyNotApplicable = errors.New("object is not encrypted with a customer-provided key")
+)
+
+// NewCustomerKey returns the encryption metadata of a new object encrypted
+// with a customer-provided key. The key itself is never stored: only a
+// random salt and a salted fingerprint are kept, and the object's data key is
+// derived from the key and the salt.
+func NewCustomerKey(customerKey []byte) (*types.ObjectEncryption, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %v", err)
+	}
+	return &types.ObjectEncryption{
+		Algorithm:              types.SSEAlgorithmAES256,
+		ChunkSize:              ChunkSize,
+		CustomerKeySalt:        base64.StdEncoding.EncodeToString(salt),
+		CustomerKeyFingerprint: base64.StdEncoding.EncodeToString(deriveCustomer(customerKey, salt, "fingerprint")),
+	}, nil
+}
+
+// IsCustomerKey reports whether an object is encrypted with a customer-provided key.
+func IsCustomerKey(enc *types.ObjectEncryption) bool {
+	return enc != nil && enc.CustomerKeyFingerprint != ""
+}
+
+// CheckCustomerKey verifies the customer-provided key presented for an
+// object, which must be given exactly when the object was encrypted with one.
+func CheckCustomerKey(enc *types.ObjectEncryption, customerKey []byte) error {
+	_, err := customerDataKey(enc, customerKey)
+	return err
+}
+
+// customerDataKey checks customerKey against the object's fingerprint and
+// derives the object's data key from it.
+func customerDataKey(enc *types.ObjectEncryption, customerKey []byte) ([]byte, error) {
+	switch {
+	case !IsCustomerKey(enc) && customerKey != nil:
+		return nil, ErrCustomerKeyNotApplicable
+	case !IsCustomerKey(enc):
+		return nil, nil
+	case customerKey == nil:
+		return nil, ErrCustomerKeyRequired
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(enc.CustomerKeySalt)
+	if err != nil {
+		return nil, fmt.Errorf("malformed customer key salt: %v", err)
+	}
+	fingerprint, err := base64.StdEncoding.DecodeString(enc.CustomerKeyFingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("malformed customer key fingerprint: %v", err)
+	}
+	if !hmac.Equal(fingerprint, deriveCustomer(customerKey, salt, "fingerprint")) {
+		return nil, ErrCustomerKeyMismatch
+	}
+	return deriveCustomer(customerKey, salt, "data key"), nil
+}
+
+// deriveCustomer derives a value for purpose from a customer-provided key and
+// an object's salt, so the fingerprint reveals nothing about the data key.
+func deriveCustomer(customerKey, salt []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, customerKey)
+	mac.Write([]byte("openbucket sse-c " + purpose + "\x00"))
+	mac.Write(salt)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,221 @@
+package sse
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/env"
+	"github.com/aidenappl/openbucket-go/tools"
+)
+
+// kek is a key-encryption key. Data keys are wrapped by the active kek, the
+// last one in the keyring.
+type kek struct {
+	ID  string
+	Key []byte
+}
+
+var (
+	keyringMu      sync.Mutex
+	keyring        []kek
+	keyringModTime time.Time
+)
+
+// ErrUnknownKey is returned when a data key is wrapped by a key-encryption
+// key that is not in the keyring.
+var ErrUnknownKey = errors.New("unknown key-encryption key")
+
+// loadKeyring returns the key-encryption keys from env.SSEKeyFile, one
+// "<id> <hex key>" pair per line. The file is generated with a single key on
+// first use and re-read whenever it changes, so a rotation done by the CLI is
+// picked up by a running server.
+func loadKeyring() ([]kek, error) {
+	keyringMu.Lock()
+	defer keyringMu.Unlock()
+
+	info, err := os.Stat(env.SSEKeyFile)
+	if errors.Is(err, os.ErrNotExist) {
+		if _, err := addKey(nil); err != nil {
+			return nil, err
+		}
+		info, err = os.Stat(env.SSEKeyFile)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSE keyring: %v", err)
+	}
+	if keyring != nil && info.ModTime().Equal(keyringModTime) {
+		return keyring, nil
+	}
+
+	data, err := os.ReadFile(env.SSEKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSE keyring: %v", err)
+	}
+	var ring []kek
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("malformed line in SSE keyring %s", env.SSEKeyFile)
+		}
+		key, err := hex.DecodeString(fields[1])
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("key %s in SSE keyring %s is not a 256-bit hex key", fields[0], env.SSEKeyFile)
+		}
+		ring = append(ring, kek{ID: fields[0], Key: key})
+	}
+	if len(ring) == 0 {
+		return nil, fmt.Errorf("SSE keyring %s is empty", env.SSEKeyFile)
+	}
+
+	keyring, keyringModTime = ring, info.ModTime()
+	return keyring, nil
+}
+
+// addKey generates a new key-encryption key, appends it to ring as the
+// active key and saves the keyring.
+func addKey(ring []kek) ([]kek, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate key-encryption key: %v", err)
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate key-encryption key: %v", err)
+	}
+
+	ring = append(ring, kek{ID: hex.EncodeToString(id), Key: key})
+	if err := saveKeyring(ring); err != nil {
+		return nil, err
+	}
+	return ring, nil
+}
+
+func saveKeyring(ring []kek) error {
+	var b strings.Builder
+	for _, k := range ring {
+		fmt.Fprintf(&b, "%s %s\n", k.ID, hex.EncodeToString(k.Key))
+	}
+	if err := tools.WriteFileAtomic(env.SSEKeyFile, []byte(b.String()), 0600); err != nil {
+		return fmt.Errorf("failed to save SSE keyring: %v", err)
+	}
+	return nil
+}
+
+// activeKey returns the key new data keys are wrapped with.
+func activeKey() (kek, error) {
+	ring, err := loadKeyring()
+	if err != nil {
+		return kek{}, err
+	}
+	return ring[len(ring)-1], nil
+}
+
+// keyByID returns the key-encryption key with the given ID.
+func keyByID(id string) (kek, error) {
+	ring, err := loadKeyring()
+	if err != nil {
+		return kek{}, err
+	}
+	for _, k := range ring {
+		if k.ID == id {
+			return k, nil
+		}
+	}
+	return kek{}, fmt.Errorf("%w: %s", ErrUnknownKey, id)
+}
+
+// Rotate adds a new key-encryption key and makes it the active one. Existing
+// data keys stay wrapped by their old key until they are passed to Rewrap.
+func Rotate() (string, error) {
+	ring, err := loadKeyring()
+	if err != nil {
+		return "", err
+	}
+
+	keyringMu.Lock()
+	defer keyringMu.Unlock()
+	ring, err = addKey(append([]kek(nil), ring...))
+	if err != nil {
+		return "", err
+	}
+	keyring = nil // reload, and record the new modification time, on next use
+	return ring[len(ring)-1].ID, nil
+}
+
+// Retire removes every key-encryption key except the active one and those
+// in inUse from the keyring, and returns the IDs of the removed keys.
+func Retire(inUse map[string]bool) ([]string, error) {
+	ring, err := loadKeyring()
+	if err != nil {
+		return nil, err
+	}
+
+	keyringMu.Lock()
+	defer keyringMu.Unlock()
+	var kept []kek
+	var retired []string
+	for i, k := range ring {
+		if i == len(ring)-1 || inUse[k.ID] {
+			kept = append(kept, k)
+		} else {
+			retired = append(retired, k.ID)
+		}
+	}
+	if len(retired) == 0 {
+		return nil, nil
+	}
+	if err := saveKeyring(kept); err != nil {
+		return nil, err
+	}
+	keyring = nil
+	return retired, nil
+}
+
+// wrap seals a data key with a key-encryption key.
+func wrap(k kek, dataKey []byte) ([]byte, error) {
+	aead, err := newGCM(k.Key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	return aead.Seal(nonce, nonce, dataKey, []byte(k.ID)), nil
+}
+
+// unwrap opens a data key sealed by wrap.
+func unwrap(k kek, wrapped []byte) ([]byte, error) {
+	aead, err := newGCM(k.Key)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, fmt.Errorf("wrapped data key is too short")
+	}
+	nonce, sealed := wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():]
+	dataKey, err := aead.Open(nil, nonce, sealed, []byte(k.ID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key with key %s: %v", k.ID, err)
+	}
+	return dataKey, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,247 @@
+package sse
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// ChunkSize is the amount of plaintext sealed per chunk. Each chunk is
+// followed by its authentication tag, so any byte range can be decrypted by
+// reading only the chunks that hold it.
+const ChunkSize = 64 * 1024
+
+// ErrCorrupt is returned when an encrypted object fails authentication.
+var ErrCorrupt = errors.New("encrypted object is corrupt")
+
+// NewObjectKey generates a data key for a new object and returns its
+// encryption metadata, with the data key wrapped by the active key.
+func NewObjectKey() (*types.ObjectEncryption, error) {
+	k, err := activeKey()
+	if err != nil {
+		return nil, err
+	}
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %v", err)
+	}
+	wrapped, err := wrap(k, dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return &types.ObjectEncryption{
+		Algorithm: types.SSEAlgorithmAES256,
+		KeyID:     k.ID,
+		DataKey:   base64.StdEncoding.EncodeToString(wrapped),
+		ChunkSize: ChunkSize,
+	}, nil
+}
+
+// Rewrap re-wraps the data key of an object with the active key. It reports
+// whether enc changed. Objects encrypted with customer-provided keys have no
+// wrapped key and are left alone.
+func Rewrap(enc *types.ObjectEncryption) (bool, error) {
+	if IsCustomerKey(enc) {
+		return false, nil
+	}
+	k, err := activeKey()
+	if err != nil || enc.KeyID == k.ID {
+		return false, err
+	}
+	dataKey, err := objectKey(enc)
+	if err != nil {
+		return false, err
+	}
+	wrapped, err := wrap(k, dataKey)
+	if err != nil {
+		return false, err
+	}
+	enc.KeyID = k.ID
+	enc.DataKey = base64.StdEncoding.EncodeToString(wrapped)
+	return true, nil
+}
+
+// objectKey unwraps the data key of an object.
+func objectKey(enc *types.ObjectEncryption) ([]byte, error) {
+	k, err := keyByID(enc.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(enc.DataKey)
+	if err != nil {
+		return nil, fmt.Errorf("malformed data key: %v", err)
+	}
+	return unwrap(k, wrapped)
+}
+
+// objectAEAD returns the cipher an object's chunks are sealed with.
+// customerKey must be given for objects encrypted with a customer-provided
+// key, and only for those.
+func objectAEAD(enc *types.ObjectEncryption, customerKey []byte) (cipher.AEAD, error) {
+	if enc.Algorithm != types.SSEAlgorithmAES256 || enc.ChunkSize <= 0 {
+		return nil, fmt.Errorf("unsupported object encryption %s with chunk size %d", enc.Algorithm, enc.ChunkSize)
+	}
+	dataKey, err := customerDataKey(enc, customerKey)
+	if err == nil && dataKey == nil {
+		dataKey, err = objectKey(enc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newGCM(dataKey)
+}
+
+// chunkNonce is the nonce of chunk n. Data keys are never reused, so the
+// chunk index is unique; the final chunk is marked so that truncating an
+// object at a chunk boundary is detected.
+func chunkNonce(n int64, final bool) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce, uint64(n))
+	if final {
+		nonce[11] = 1
+	}
+	return nonce
+}
+
+// Encrypt seals src into dst in chunks with the data key of enc and
+// returns the number of plaintext bytes written.
+func Encrypt(enc *types.ObjectEncryption, customerKey []byte, dst io.Writer, src io.Reader) (int64, error) {
+	aead, err := objectAEAD(enc, customerKey)
+	if err != nil {
+		return 0, err
+	}
+
+	in := bufio.NewReaderSize(src, enc.ChunkSize)
+	buf := make([]byte, enc.ChunkSize)
+	out := make([]byte, 0, enc.ChunkSize+aead.Overhead())
+	var size int64
+	for n := int64(0); ; n++ {
+		read, err := io.ReadFull(in, buf)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return size, err
+		}
+		if read == 0 {
+			return size, nil
+		}
+
+		// A full chunk is the last one when nothing follows it
+		final := read < len(buf)
+		if !final {
+			if _, err := in.Peek(1); errors.Is(err, io.EOF) {
+				final = true
+			} else if err != nil {
+				return size, err
+			}
+		}
+
+		out = aead.Seal(out[:0], chunkNonce(n, final), buf[:read], nil)
+		if _, err := dst.Write(out); err != nil {
+			return size, err
+		}
+		size += int64(read)
+		if final {
+			return size, nil
+		}
+	}
+}
+
+// Reader decrypts an object stored by Encrypt. It supports seeking, so
+// ranged reads only decrypt the chunks they touch.
+type Reader struct {
+	aead      cipher.AEAD
+	src       io.ReaderAt
+	size      int64
+	chunkSize int64
+	offset    int64
+
+	chunk  int64 // index of the chunk held in plain, or -1
+	plain  []byte
+	sealed []byte
+	err    error
+}
+
+// Decrypt returns a Reader for an encrypted object of size plaintext bytes
+// stored in src.
+func Decrypt(enc *types.ObjectEncryption, customerKey []byte, src io.ReaderAt, size int64) (*Reader, error) {
+	aead, err := objectAEAD(enc, customerKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{
+		aead:      aead,
+		src:       src,
+		size:      size,
+		chunkSize: int64(enc.ChunkSize),
+		chunk:     -1,
+		sealed:    make([]byte, enc.ChunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (r *Reader) Read(p []byte) (int, error) {
+	if r.offset >= r.size {
+		return 0, io.EOF
+	}
+
+	n := r.offset / r.chunkSize
+	if n != r.chunk {
+		if err := r.load(n); err != nil {
+			r.err = err
+			return 0, err
+		}
+	}
+
+	copied := copy(p, r.plain[r.offset-n*r.chunkSize:])
+	r.offset += int64(copied)
+	return copied, nil
+}
+
+// Err returns the error that ended the last failed read, if any.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// load reads and opens chunk n.
+func (r *Reader) load(n int64) error {
+	start := n * r.chunkSize
+	length := min(r.chunkSize, r.size-start)
+	sealed := r.sealed[:length+int64(r.aead.Overhead())]
+
+	if _, err := r.src.ReadAt(sealed, n*(r.chunkSize+int64(r.aead.Overhead()))); err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: chunk %d is truncated", ErrCorrupt, n)
+		}
+		return err
+	}
+
+	final := start+length == r.size
+	plain, err := r.aead.Open(r.plain[:0], chunkNonce(n, final), sealed, nil)
+	if err != nil {
+		return fmt.Errorf("%w: chunk %d failed authentication", ErrCorrupt, n)
+	}
+	r.plain, r.chunk = plain, n
+	return nil
+}
+
+func (r *Reader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.offset
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, fmt.Errorf("invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative position %d", offset)
+	}
+	r.offset = offset
+	return offset, nil
+}
@@ -0,0 +1,52 @@
+package sts
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/aidenappl/openbucket-go/env"
+	"github.com/aidenappl/openbucket-go/tools"
+)
+
+var (
+	signingKey     []byte
+	signingKeyErr  error
+	signingKeyOnce sync.Once
+)
+
+// loadSigningKey returns the key session tokens are signed with. It is read
+// from env.STSKeyFile and generated on first use, so tokens stay valid across
+// restarts. Deleting the file revokes every outstanding session.
+func loadSigningKey() ([]byte, error) {
+	signingKeyOnce.Do(func() {
+		data, err := os.ReadFile(env.STSKeyFile)
+		if err == nil {
+			signingKey, signingKeyErr = hex.DecodeString(strings.TrimSpace(string(data)))
+			if signingKeyErr == nil && len(signingKey) < 32 {
+				signingKeyErr = fmt.Errorf("STS signing key in %s is too short", env.STSKeyFile)
+			}
+			return
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			signingKeyErr = fmt.Errorf("failed to read STS signing key: %v", err)
+			return
+		}
+
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			signingKeyErr = fmt.Errorf("failed to generate STS signing key: %v", err)
+			return
+		}
+		if err := tools.WriteFileAtomic(env.STSKeyFile, []byte(hex.EncodeToString(key)+"\n"), 0600); err != nil {
+			signingKeyErr = fmt.Errorf("failed to save STS signing key: %v", err)
+			return
+		}
+		signingKey = key
+	})
+	return signingKey, signingKeyErr
+}
@@ -0,0 +1,136 @@
+package sts
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aidenappl/openbucket-go/tools"
+	"github.com/aidenappl/openbucket-go/types"
+)
+
+// tokenPrefix versions the session token format.
+const tokenPrefix = "OB1."
+
+// ErrInvalidToken is returned for session tokens that are malformed, forged,
+// expired or presented with the wrong access key.
+var ErrInvalidToken = errors.New("invalid session token")
+
+// Claims is the content of a session token. Everything needed to authorize
+// a request made with the session is carried in the token itself, so issued
+// credentials never have to be stored.
+type Claims struct {
+	AccessKeyID string `json:"akid"`
+	Parent      string `json:"parent,omitempty"`  // long-term access key that requested the session
+	Role        string `json:"role,omitempty"`    // assumed role, empty for GetSessionToken
+	SessionName string `json:"session,omitempty"` // RoleSessionName of an assumed role
+	Issuer      string `json:"iss,omitempty"`     // OIDC issuer for AssumeRoleWithWebIdentity
+	Subject     string `json:"sub,omitempty"`     // OIDC subject for AssumeRoleWithWebIdentity
+	Policy      string `json:"policy,omitempty"`  // optional session policy further limiting access
+	IssuedAt    int64  `json:"iat"`
+	Expiration  int64  `json:"exp"`
+}
+
+// Expires returns the time the session ends.
+func (c *Claims) Expires() time.Time {
+	return time.Unix(c.Expiration, 0).UTC()
+}
+
+// Issue creates temporary credentials for the claims, valid for duration.
+// The access key ID, issue time and expiration of the claims are filled in.
+func Issue(claims Claims, duration time.Duration) (*types.Credentials, error) {
+	key, err := loadSigningKey()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	claims.AccessKeyID = "ASIA" + strings.ToUpper(tools.GenerateRandomKey(16))
+	claims.IssuedAt = now.Unix()
+	claims.Expiration = now.Add(duration).Unix()
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode session claims: %v", err)
+	}
+	body := base64.RawURLEncoding.EncodeToString(payload)
+	token := tokenPrefix + body + "." + base64.RawURLEncoding.EncodeToString(mac(key, "token", body))
+
+	return &types.Credentials{
+		AccessKeyID:     claims.AccessKeyID,
+		SecretAccessKey: secretFor(key, claims.AccessKeyID),
+		SessionToken:    token,
+		Expiration:      claims.Expires(),
+	}, nil
+}
+
+// Verify checks that the token was issued by this server for accessKeyID and
+// has not expired, and returns its claims.
+func Verify(token, accessKeyID string) (*Claims, error) {
+	key, err := loadSigningKey()
+	if err != nil {
+		return nil, err
+	}
+
+	rest, ok := strings.CutPrefix(token, tokenPrefix)
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown format", ErrInvalidToken)
+	}
+	body, sig, ok := strings.Cut(rest, ".")
+	if !ok {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+	gotMAC, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(gotMAC, mac(key, "token", body)) {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidToken)
+	}
+
+	if claims.AccessKeyID != accessKeyID {
+		return nil, fmt.Errorf("%w: issued for a different access key", ErrInvalidToken)
+	}
+	if time.Now().After(claims.Expires()) {
+		return nil, fmt.Errorf("%w: expired at %s", ErrInvalidToken, claims.Expires().Format(time.RFC3339))
+	}
+	return &claims, nil
+}
+
+// SessionSecret verifies the token and returns the secret access key of the
+// session, which is derived from the access key ID rather than stored.
+func SessionSecret(token, accessKeyID string) (string, error) {
+	if _, err := Verify(token, accessKeyID); err != nil {
+		return "", err
+	}
+	key, err := loadSigningKey()
+	if err != nil {
+		return "", err
+	}
+	return secretFor(key, accessKeyID), nil
+}
+
+func secretFor(key []byte, accessKeyID string) string {
+	return base64.RawURLEncoding.EncodeToString(mac(key, "secret", accessKeyID))
+}
+
+// mac computes a domain-separated HMAC-SHA256 so the token signature and the
+// derived secrets can never be confused.
+func mac(key []byte, purpose, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(purpose))
+	h.Write([]byte{0})
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"net"
+	"strings"
+)
+
+// ValidBucketName reports whether name follows the S3 rules for DNS-compatible
+// bucket names: 3 to 63 lowercase letters, digits, dots and hyphens, starting
+// and ending with a letter or digit, without adjacent dots, not shaped like
+// an IP address and free of the prefixes and suffixes S3 reserves. Names
+// whose last label starts with "ob" are reserved as well, as bucket
+// configuration files are stored as "<bucket>.ob<name>" next to the buckets.
+func ValidBucketName(name string) bool {
+	if len(name) < 3 || len(name) > 63 {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' || c == '-') {
+			return false
+		}
+	}
+	if !isAlphanumeric(name[0]) || !isAlphanumeric(name[len(name)-1]) {
+		return false
+	}
+	if strings.Contains(name, "..") || net.ParseIP(name) != nil {
+		return false
+	}
+	for _, prefix := range []string{"xn--", "sthree-", "amzn-s3-demo-"} {
+		if strings.HasPrefix(name, prefix) {
+			return false
+		}
+	}
+	for _, suffix := range []string{"-s3alias", "--ol-s3", ".mrap", "--x-s3"} {
+		if strings.HasSuffix(name, suffix) {
+			return false
+		}
+	}
+	if i := strings.LastIndexByte(name, '.'); i != -1 && strings.HasPrefix(name[i+1:], "ob") {
+		return false
+	}
+	return true
+}
+
+func isAlphanumeric(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= '0' && c <= '9'
+}
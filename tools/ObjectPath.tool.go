@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+)
+
+// MaxKeyLength is the longest object key S3 allows, in bytes of UTF-8.
+const MaxKeyLength = 1024
+
+// maxNameLength keeps encoded names, with the ".obmeta" suffix of their
+// sidecar and the suffix of the temporary files they are written through,
+// below the 255-byte name limit of common file systems.
+const maxNameLength = 200
+
+// ObjectPath returns the file an object is stored in. Every key maps to a
+// single file directly in the bucket's directory, so keys such as "a/../b",
+// "foo" next to "foo/bar", or names ending in ".obmeta" cannot escape the
+// bucket or collide. See EncodeKey.
+func ObjectPath(bucket, key string) string {
+	return filepath.Join("buckets", bucket, EncodeKey(key))
+}
+
+// EncodeKey maps an object key to a file name. Slashes, "%", "~" and bytes
+// that are unsafe in file names are percent-encoded, as are a leading dot,
+// a trailing dot or space and the dot of a trailing ".obmeta", so names
+// never clash with hidden temporary files or metadata sidecars. Keys made
+// only of letters, digits and common punctuation are kept as they are.
+// Names longer than maxNameLength are cut and suffixed with "~" and the
+// SHA-256 of the key; "~" appears in no other name.
+func EncodeKey(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		last := i == len(key)-1
+		switch {
+		case c == '.' && i == 0,
+			(c == '.' || c == ' ') && last,
+			c == '.' && key[i:] == ".obmeta",
+			c < 0x20, c == 0x7f,
+			strings.IndexByte(`/\%~<>:"|?*`, c) != -1:
+			b.WriteString("%")
+			b.WriteString(strings.ToUpper(hex.EncodeToString([]byte{c})))
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	name := b.String()
+	if len(name) <= maxNameLength {
+		return name
+	}
+	sum := sha256.Sum256([]byte(key))
+	cut := maxNameLength - 1 - 2*len(sum)
+	// Do not cut through an escape sequence or a UTF-8 character
+	for cut > 0 && (name[cut-1] == '%' || (cut > 1 && name[cut-2] == '%') || name[cut]&0xC0 == 0x80) {
+		cut--
+	}
+	return name[:cut] + "~" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/aidenappl/openbucket-go/env"
+)
+
+var (
+	trustedProxiesOnce sync.Once
+	trustedProxies     []*net.IPNet
+)
+
+// SecureTransport reports whether r reached the server over TLS, either
+// directly or through a trusted proxy (see env.TrustedProxies) that says so
+// with X-Forwarded-Proto: https. The header is ignored from other peers.
+func SecureTransport(r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	proto, _, _ := strings.Cut(r.Header.Get("X-Forwarded-Proto"), ",")
+	if !strings.EqualFold(strings.TrimSpace(proto), "https") {
+		return false
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	trustedProxiesOnce.Do(loadTrustedProxies)
+	for _, proxy := range trustedProxies {
+		if proxy.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// loadTrustedProxies parses the comma-separated IP addresses and CIDR blocks
+// of env.TrustedProxies. Invalid entries are logged and skipped.
+func loadTrustedProxies() {
+	for _, entry := range strings.Split(env.TrustedProxies, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, block, err := net.ParseCIDR(entry)
+		if err != nil {
+			log.Printf("Ignoring invalid trusted proxy %q: %v", entry, err)
+			continue
+		}
+		trustedProxies = append(trustedProxies, block)
+	}
+}
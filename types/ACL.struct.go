@@ -156,3 +156,38 @@ func IsBucketACLRead(acl Permission) bool {
 func IsBucketACLWrite(acl Permission) bool {
 	return acl == BUCKET_ACLPublicWrite || acl == BUCKET_ACLPublicReadWrite
 }
+
+// Predefined grantee groups.
+const (
+	AllUsersGroup           = "http://acs.amazonaws.com/groups/global/AllUsers"
+	AuthenticatedUsersGroup = "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"
+)
+
+// Grantee types as used in the xsi:type attribute.
+const (
+	GranteeCanonicalUser = "CanonicalUser"
+	GranteeGroup         = "Group"
+	GranteeEmail         = "AmazonCustomerByEmail"
+)
+
+// GrantApplies reports whether the grant covers the caller. keyID is empty
+// for anonymous callers.
+func GrantApplies(g Grant, keyID string) bool {
+	switch g.Grantee.URI {
+	case AllUsersGroup:
+		return true
+	case AuthenticatedUsersGroup:
+		return keyID != ""
+	}
+	return keyID != "" && g.Grantee.ID == keyID
+}
+
+// HasGrant reports whether any of the grants gives the caller the permission.
+func HasGrant(grants []Grant, keyID string, want Permission) bool {
+	for _, g := range grants {
+		if GrantApplies(g, keyID) && PermissionImplies(g.Permission, want) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package types
+
+// Action is an S3 action name as used in policies, e.g. s3:GetObject.
+type Action string
+
+// S3 actions served by OpenBucket.
+const (
+	ActionListAllMyBuckets   Action = "s3:ListAllMyBuckets"
+	ActionCreateBucket       Action = "s3:CreateBucket"
+	ActionDeleteBucket       Action = "s3:DeleteBucket"
+	ActionListBucket         Action = "s3:ListBucket"
+	ActionGetBucketAcl       Action = "s3:GetBucketAcl"
+	ActionPutBucketAcl       Action = "s3:PutBucketAcl"
+	ActionGetBucketPolicy    Action = "s3:GetBucketPolicy"
+	ActionPutBucketPolicy    Action = "s3:PutBucketPolicy"
+	ActionDeleteBucketPolicy Action = "s3:DeleteBucketPolicy"
+	ActionGetBucketCORS      Action = "s3:GetBucketCORS"
+	ActionPutBucketCORS      Action = "s3:PutBucketCORS"
+	ActionGetLifecycle       Action = "s3:GetLifecycleConfiguration"
+	ActionPutLifecycle       Action = "s3:PutLifecycleConfiguration"
+	ActionGetObjectLock      Action = "s3:GetBucketObjectLockConfiguration"
+	ActionPutObjectLock      Action = "s3:PutBucketObjectLockConfiguration"
+	ActionGetEncryption      Action = "s3:GetEncryptionConfiguration"
+	ActionPutEncryption      Action = "s3:PutEncryptionConfiguration"
+	ActionGetCompression     Action = "s3:GetBucketCompression"
+	ActionPutCompression     Action = "s3:PutBucketCompression"
+	ActionGetNotification    Action = "s3:GetBucketNotification"
+	ActionPutNotification    Action = "s3:PutBucketNotification"
+	ActionListenNotification Action = "s3:ListenBucketNotification"
+	ActionGetReplication     Action = "s3:GetReplicationConfiguration"
+	ActionPutReplication     Action = "s3:PutReplicationConfiguration"
+	ActionReplicateObject    Action = "s3:ReplicateObject"
+	ActionReplicateDelete    Action = "s3:ReplicateDelete"
+	ActionGetWebsite         Action = "s3:GetBucketWebsite"
+	ActionPutWebsite         Action = "s3:PutBucketWebsite"
+	ActionDeleteWebsite      Action = "s3:DeleteBucketWebsite"
+	ActionGetObject          Action = "s3:GetObject"
+	ActionPutObject          Action = "s3:PutObject"
+	ActionDeleteObject       Action = "s3:DeleteObject"
+	ActionGetObjectAcl       Action = "s3:GetObjectAcl"
+	ActionPutObjectAcl       Action = "s3:PutObjectAcl"
+	ActionGetTagging         Action = "s3:GetObjectTagging"
+	ActionPutTagging         Action = "s3:PutObjectTagging"
+	ActionDeleteTagging      Action = "s3:DeleteObjectTagging"
+	ActionGetRetention       Action = "s3:GetObjectRetention"
+	ActionPutRetention       Action = "s3:PutObjectRetention"
+	ActionGetLegalHold       Action = "s3:GetObjectLegalHold"
+	ActionPutLegalHold       Action = "s3:PutObjectLegalHold"
+	ActionBypassGovernance   Action = "s3:BypassGovernanceRetention"
+	ActionListUploads        Action = "s3:ListBucketMultipartUploads"
+	ActionListParts          Action = "s3:ListMultipartUploadParts"
+	ActionAbortUpload        Action = "s3:AbortMultipartUpload"
+)
+
+// ActionInfo captures the ACL permission an action requires. A zero Perm
+// means the action is not governed by a bucket ACL.
+type ActionInfo struct {
+	Action   Action
+	Perm     Permission
+	Resource Resource
+}
+
+// actionTable maps each action to the ACL permission that allows it,
+// following the semantics in permissionTable. Bucket policies and bucket
+// configurations can only be managed by the owner or holders of FULL_CONTROL.
+// Object actions are checked against the bucket ACL first and then the
+// object's own grants.
+var actionTable = []ActionInfo{
+	{ActionListAllMyBuckets, "", ""},
+	{ActionCreateBucket, "", ""},
+
+	{ActionListBucket, READ, ResourceBucket},
+	{ActionListenNotification, READ, ResourceBucket},
+	{ActionPutObject, WRITE, ResourceBucket},
+	{ActionDeleteObject, WRITE, ResourceBucket},
+	{ActionPutTagging, WRITE, ResourceBucket},
+	{ActionDeleteTagging, WRITE, ResourceBucket},
+	{ActionListUploads, READ, ResourceBucket},
+	{ActionListParts, WRITE, ResourceBucket},
+	{ActionAbortUpload, WRITE, ResourceBucket},
+	{ActionReplicateObject, WRITE, ResourceBucket},
+	{ActionReplicateDelete, WRITE, ResourceBucket},
+	{ActionGetBucketAcl, READ_ACP, ResourceBucket},
+	{ActionPutBucketAcl, WRITE_ACP, ResourceBucket},
+	{ActionDeleteBucket, FULL_CONTROL, ResourceBucket},
+	{ActionGetBucketPolicy, FULL_CONTROL, ResourceBucket},
+	{ActionPutBucketPolicy, FULL_CONTROL, ResourceBucket},
+	{ActionDeleteBucketPolicy, FULL_CONTROL, ResourceBucket},
+	{ActionGetBucketCORS, FULL_CONTROL, ResourceBucket},
+	{ActionPutBucketCORS, FULL_CONTROL, ResourceBucket},
+	{ActionGetLifecycle, FULL_CONTROL, ResourceBucket},
+	{ActionPutLifecycle, FULL_CONTROL, ResourceBucket},
+	{ActionGetObjectLock, FULL_CONTROL, ResourceBucket},
+	{ActionPutObjectLock, FULL_CONTROL, ResourceBucket},
+	{ActionGetEncryption, FULL_CONTROL, ResourceBucket},
+	{ActionPutEncryption, FULL_CONTROL, ResourceBucket},
+	{ActionGetCompression, FULL_CONTROL, ResourceBucket},
+	{ActionPutCompression, FULL_CONTROL, ResourceBucket},
+	{ActionGetNotification, FULL_CONTROL, ResourceBucket},
+	{ActionPutNotification, FULL_CONTROL, ResourceBucket},
+	{ActionGetReplication, FULL_CONTROL, ResourceBucket},
+	{ActionPutReplication, FULL_CONTROL, ResourceBucket},
+	{ActionGetWebsite, FULL_CONTROL, ResourceBucket},
+	{ActionPutWebsite, FULL_CONTROL, ResourceBucket},
+	{ActionDeleteWebsite, FULL_CONTROL, ResourceBucket},
+
+	{ActionGetObject, READ, ResourceObject},
+	{ActionGetObjectAcl, READ_ACP, ResourceObject},
+	{ActionPutObjectAcl, WRITE_ACP, ResourceObject},
+	{ActionGetTagging, READ, ResourceObject},
+	{ActionGetRetention, READ, ResourceObject},
+	{ActionGetLegalHold, READ, ResourceObject},
+
+	// Locking objects, or lifting a lock early, is reserved for the bucket owner
+	{ActionPutRetention, FULL_CONTROL, ResourceBucket},
+	{ActionPutLegalHold, FULL_CONTROL, ResourceBucket},
+	{ActionBypassGovernance, FULL_CONTROL, ResourceBucket},
+}
+
+// LookupAction returns the ACL requirements of an action.
+func LookupAction(a Action) (ActionInfo, bool) {
+	for _, row := range actionTable {
+		if row.Action == a {
+			return row, true
+		}
+	}
+	return ActionInfo{}, false
+}
+
+// PermissionImplies reports whether holding permission have satisfies want.
+// FULL_CONTROL implies every other permission.
+func PermissionImplies(have, want Permission) bool {
+	return have == want || have == FULL_CONTROL
+}
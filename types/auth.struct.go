@@ -9,6 +9,7 @@ type Authorization struct {
 	Name        string    `xml:"Name"`
 	KeyID       string    `xml:"KEY_ID"`
 	SecretKey   string    `xml:"SECRET_KEY"`
+	User        string    `xml:"User,omitempty"`
 	DateCreated time.Time `xml:"Date_Created"`
 }
 
@@ -19,9 +19,29 @@ type Grant struct {
 }
 
 type Grantee struct {
-	XMLName     xml.Name `xml:"Grantee"`
-	Type        string   `xml:"xsi:type,attr"`
-	ID          string   `xml:"ID,omitempty"`
-	DisplayName string   `xml:"DisplayName,omitempty"`
-	URI         string   `xml:"URI,omitempty"`
+	XMLName      xml.Name `xml:"Grantee"`
+	Type         string   `xml:"xsi:type,attr"`
+	ID           string   `xml:"ID,omitempty"`
+	DisplayName  string   `xml:"DisplayName,omitempty"`
+	EmailAddress string   `xml:"EmailAddress,omitempty"`
+	URI          string   `xml:"URI,omitempty"`
+}
+
+// ResolvedType returns the grantee type, inferring it from the populated
+// fields when the xsi:type attribute was not decoded.
+func (g Grantee) ResolvedType() string {
+	switch {
+	case g.URI != "":
+		return GranteeGroup
+	case g.ID == "" && g.EmailAddress != "":
+		return GranteeEmail
+	default:
+		return GranteeCanonicalUser
+	}
+}
+
+// CreateBucketConfiguration is the optional request body of CreateBucket.
+type CreateBucketConfiguration struct {
+	XMLName            xml.Name `xml:"CreateBucketConfiguration"`
+	LocationConstraint string   `xml:"LocationConstraint,omitempty"`
 }
@@ -0,0 +1,15 @@
+package types
+
+import "encoding/xml"
+
+// ContentChunk records a blob of object content held once in the chunk store
+// under the SHA-256 of its content. Refs counts the objects pointing at it;
+// the blob is removed when the last of them goes away.
+type ContentChunk struct {
+	XMLName     xml.Name           `xml:"Chunk"`
+	Hash        string             `xml:"Hash"`
+	Size        int64              `xml:"Size"`
+	StoredSize  int64              `xml:"StoredSize"`
+	Compression *ObjectCompression `xml:"Compression,omitempty"`
+	Refs        int                `xml:"Refs"`
+}
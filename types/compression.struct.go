@@ -0,0 +1,31 @@
+package types
+
+import "encoding/xml"
+
+// Compression algorithms objects can be stored with.
+const (
+	CompressionZstd = "zstd"
+	CompressionGzip = "gzip"
+)
+
+// CompressionConfiguration turns on compression at rest for a bucket. New
+// objects whose content type, as derived from their key, matches one of
+// ContentTypes are compressed with Algorithm. Entries may end in /* to match
+// a whole type; without entries a default set of text formats is used.
+type CompressionConfiguration struct {
+	XMLName      xml.Name `xml:"CompressionConfiguration"`
+	Xmlns        string   `xml:"xmlns,attr,omitempty"`
+	Algorithm    string   `xml:"Algorithm"`
+	ContentTypes []string `xml:"ContentType"`
+}
+
+// ObjectCompression describes how an object is compressed at rest. The
+// content is stored in frames of ChunkSize bytes that are compressed on their
+// own, so ranges can be read without decompressing the whole object. Size is
+// the length of the compressed content and its frame offset table, before
+// any encryption.
+type ObjectCompression struct {
+	Algorithm string `xml:"Algorithm"`
+	ChunkSize int    `xml:"ChunkSize"`
+	Size      int64  `xml:"Size"`
+}
@@ -0,0 +1,41 @@
+package types
+
+import "encoding/xml"
+
+// SSEAlgorithmAES256 is the only server-side encryption algorithm OpenBucket
+// supports: SSE-S3, with data keys wrapped by a locally held key.
+const SSEAlgorithmAES256 = "AES256"
+
+// ServerSideEncryptionConfiguration is the default encryption of a bucket.
+type ServerSideEncryptionConfiguration struct {
+	XMLName xml.Name                   `xml:"ServerSideEncryptionConfiguration"`
+	Xmlns   string                     `xml:"xmlns,attr,omitempty"`
+	Rules   []ServerSideEncryptionRule `xml:"Rule"`
+}
+
+// ServerSideEncryptionRule encrypts new objects that do not ask for
+// encryption themselves.
+type ServerSideEncryptionRule struct {
+	ApplyServerSideEncryptionByDefault *ServerSideEncryptionByDefault `xml:"ApplyServerSideEncryptionByDefault"`
+	BucketKeyEnabled                   bool                           `xml:"BucketKeyEnabled,omitempty"`
+}
+
+// ServerSideEncryptionByDefault names the algorithm new objects are encrypted with.
+type ServerSideEncryptionByDefault struct {
+	SSEAlgorithm   string `xml:"SSEAlgorithm"`
+	KMSMasterKeyID string `xml:"KMSMasterKeyID,omitempty"`
+}
+
+// ObjectEncryption describes how an object is encrypted at rest. The object
+// is sealed in ChunkSize chunks with its own data key. With SSE-S3 the data
+// key is stored wrapped by the key-encryption key KeyID; with SSE-C it is
+// derived from the customer's key, of which only a salted fingerprint is kept.
+type ObjectEncryption struct {
+	Algorithm string `xml:"Algorithm"`
+	KeyID     string `xml:"KeyID,omitempty"`
+	DataKey   string `xml:"DataKey,omitempty"`
+	ChunkSize int    `xml:"ChunkSize"`
+
+	CustomerKeySalt        string `xml:"CustomerKeySalt,omitempty"`
+	CustomerKeyFingerprint string `xml:"CustomerKeyFingerprint,omitempty"`
+}
@@ -0,0 +1,67 @@
+package types
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Identities is the root of identities.xml, which holds users, groups,
+// roles and the identity policies that can be attached to them.
+type Identities struct {
+	XMLName  xml.Name        `xml:"Identities"`
+	Users    []User          `xml:"Users>User"`
+	Groups   []Group         `xml:"Groups>Group"`
+	Roles    []Role          `xml:"Roles>Role"`
+	Policies []ManagedPolicy `xml:"Policies>Policy"`
+}
+
+// User is a named identity that owns one or more access keys in authorizations.xml.
+type User struct {
+	Name        string    `xml:"Name" json:"name"`
+	Email       string    `xml:"Email,omitempty" json:"email,omitempty"`
+	Groups      []string  `xml:"Groups>Group" json:"groups,omitempty"`
+	Policies    []string  `xml:"Policies>Policy" json:"policies,omitempty"`
+	DateCreated time.Time `xml:"Date_Created" json:"date_created"`
+}
+
+// Group is a collection of users sharing the same attached policies.
+type Group struct {
+	Name        string    `xml:"Name" json:"name"`
+	Policies    []string  `xml:"Policies>Policy" json:"policies,omitempty"`
+	DateCreated time.Time `xml:"Date_Created" json:"date_created"`
+}
+
+// Role is an identity that callers named in its trust policy can assume
+// through STS AssumeRole to receive temporary credentials.
+type Role struct {
+	Name               string    `xml:"Name" json:"name"`
+	TrustPolicy        string    `xml:"TrustPolicy" json:"trust_policy"`
+	Policies           []string  `xml:"Policies>Policy" json:"policies,omitempty"`
+	MaxSessionDuration int       `xml:"MaxSessionDuration,omitempty" json:"max_session_duration,omitempty"` // seconds
+	DateCreated        time.Time `xml:"Date_Created" json:"date_created"`
+}
+
+// ManagedPolicy is a named identity policy document that can be attached to
+// users, groups and roles.
+type ManagedPolicy struct {
+	Name        string    `xml:"Name" json:"name"`
+	Document    string    `xml:"Document" json:"document"`
+	DateCreated time.Time `xml:"Date_Created" json:"date_created"`
+}
+
+// IdentityPolicy is the decoded form of a ManagedPolicy document. Unlike a
+// bucket policy its statements carry no Principal; they apply to whoever
+// the policy is attached to.
+type IdentityPolicy struct {
+	Version   string            `json:"Version,omitempty"`
+	ID        string            `json:"Id,omitempty"`
+	Statement []PolicyStatement `json:"Statement"`
+}
+
+// TrustPolicy is the decoded form of a role's trust policy. Its statements
+// name the principals allowed to assume the role and carry no Resource.
+type TrustPolicy struct {
+	Version   string            `json:"Version,omitempty"`
+	ID        string            `json:"Id,omitempty"`
+	Statement []PolicyStatement `json:"Statement"`
+}
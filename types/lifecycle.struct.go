@@ -0,0 +1,59 @@
+package types
+
+import "encoding/xml"
+
+// LifecycleConfiguration is the S3 lifecycle configuration of a bucket.
+type LifecycleConfiguration struct {
+	XMLName xml.Name        `xml:"LifecycleConfiguration"`
+	Xmlns   string          `xml:"xmlns,attr,omitempty"`
+	Rules   []LifecycleRule `xml:"Rule"`
+}
+
+// LifecycleRule selects objects with Filter, or the legacy top-level Prefix,
+// and applies its actions to them while Status is Enabled.
+type LifecycleRule struct {
+	ID                             string                          `xml:"ID,omitempty"`
+	Status                         string                          `xml:"Status"`
+	Prefix                         *string                         `xml:"Prefix"`
+	Filter                         *LifecycleFilter                `xml:"Filter"`
+	Expiration                     *LifecycleExpiration            `xml:"Expiration"`
+	NoncurrentVersionExpiration    *NoncurrentVersionExpiration    `xml:"NoncurrentVersionExpiration"`
+	AbortIncompleteMultipartUpload *AbortIncompleteMultipartUpload `xml:"AbortIncompleteMultipartUpload"`
+}
+
+// LifecycleFilter holds exactly one of its conditions; And combines several.
+type LifecycleFilter struct {
+	Prefix                *string       `xml:"Prefix"`
+	Tag                   *Tag          `xml:"Tag"`
+	ObjectSizeGreaterThan *int64        `xml:"ObjectSizeGreaterThan"`
+	ObjectSizeLessThan    *int64        `xml:"ObjectSizeLessThan"`
+	And                   *LifecycleAnd `xml:"And"`
+}
+
+// LifecycleAnd matches objects that satisfy all of its conditions.
+type LifecycleAnd struct {
+	Prefix                string `xml:"Prefix,omitempty"`
+	Tags                  []Tag  `xml:"Tag"`
+	ObjectSizeGreaterThan int64  `xml:"ObjectSizeGreaterThan,omitempty"`
+	ObjectSizeLessThan    int64  `xml:"ObjectSizeLessThan,omitempty"`
+}
+
+// LifecycleExpiration expires current objects after Days or on Date, or
+// removes delete markers that no longer have noncurrent versions behind them.
+type LifecycleExpiration struct {
+	Days                      int    `xml:"Days,omitempty"`
+	Date                      string `xml:"Date,omitempty"`
+	ExpiredObjectDeleteMarker bool   `xml:"ExpiredObjectDeleteMarker,omitempty"`
+}
+
+// NoncurrentVersionExpiration removes versions NoncurrentDays after they
+// became noncurrent, keeping the NewerNoncurrentVersions most recent ones.
+type NoncurrentVersionExpiration struct {
+	NoncurrentDays          int `xml:"NoncurrentDays"`
+	NewerNoncurrentVersions int `xml:"NewerNoncurrentVersions,omitempty"`
+}
+
+// AbortIncompleteMultipartUpload aborts uploads DaysAfterInitiation days after they started.
+type AbortIncompleteMultipartUpload struct {
+	DaysAfterInitiation int `xml:"DaysAfterInitiation"`
+}
@@ -0,0 +1,119 @@
+package types
+
+import "encoding/xml"
+
+// MultipartUpload is a multipart upload in progress. Everything the final
+// object needs is recorded when the upload is created; the customer key of an
+// SSE-C upload is only kept as a fingerprint in CustomerKey.
+type MultipartUpload struct {
+	XMLName     xml.Name          `xml:"MultipartUpload"`
+	UploadID    string            `xml:"UploadId"`
+	Bucket      string            `xml:"Bucket"`
+	Key         string            `xml:"Key"`
+	Owner       UserObject        `xml:"Owner"`
+	Initiated   IsoTime           `xml:"Initiated"`
+	Grants      []Grant           `xml:"Grants>Grant"`
+	Retention   *ObjectRetention  `xml:"Retention,omitempty"`
+	LegalHold   string            `xml:"LegalHold,omitempty"`
+	Encrypt     bool              `xml:"Encrypt,omitempty"`
+	CustomerKey *ObjectEncryption `xml:"CustomerKey,omitempty"`
+
+	WebsiteRedirectLocation string `xml:"WebsiteRedirectLocation,omitempty"`
+}
+
+// UploadPart is a part uploaded to a multipart upload.
+type UploadPart struct {
+	XMLName      xml.Name          `xml:"Part"`
+	PartNumber   int               `xml:"PartNumber"`
+	ETag         string            `xml:"ETag"`
+	Size         int64             `xml:"Size"`
+	LastModified IsoTime           `xml:"LastModified"`
+	Encryption   *ObjectEncryption `xml:"Encryption,omitempty"`
+}
+
+// InitiateMultipartUploadResult answers CreateMultipartUpload.
+type InitiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+// CompleteMultipartUpload is the request body of CompleteMultipartUpload.
+type CompleteMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []CompletedPart `xml:"Part"`
+}
+
+// CompletedPart names a part to assemble into the final object.
+type CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// CompleteMultipartUploadResult answers CompleteMultipartUpload.
+type CompleteMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"CompleteMultipartUploadResult"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Location string   `xml:"Location"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	ETag     string   `xml:"ETag"`
+}
+
+// ListPartsResult answers ListParts.
+type ListPartsResult struct {
+	XMLName              xml.Name     `xml:"ListPartsResult"`
+	Xmlns                string       `xml:"xmlns,attr"`
+	Bucket               string       `xml:"Bucket"`
+	Key                  string       `xml:"Key"`
+	UploadID             string       `xml:"UploadId"`
+	Initiator            UserObject   `xml:"Initiator"`
+	Owner                UserObject   `xml:"Owner"`
+	PartNumberMarker     int          `xml:"PartNumberMarker"`
+	NextPartNumberMarker int          `xml:"NextPartNumberMarker"`
+	MaxParts             int          `xml:"MaxParts"`
+	IsTruncated          bool         `xml:"IsTruncated"`
+	Parts                []ListedPart `xml:"Part"`
+}
+
+// ListedPart is a part in a ListPartsResult.
+type ListedPart struct {
+	PartNumber   int     `xml:"PartNumber"`
+	LastModified IsoTime `xml:"LastModified"`
+	ETag         string  `xml:"ETag"`
+	Size         int64   `xml:"Size"`
+}
+
+// ListMultipartUploadsResult answers ListMultipartUploads.
+type ListMultipartUploadsResult struct {
+	XMLName            xml.Name       `xml:"ListMultipartUploadsResult"`
+	Xmlns              string         `xml:"xmlns,attr"`
+	Bucket             string         `xml:"Bucket"`
+	KeyMarker          string         `xml:"KeyMarker"`
+	UploadIDMarker     string         `xml:"UploadIdMarker"`
+	NextKeyMarker      string         `xml:"NextKeyMarker,omitempty"`
+	NextUploadIDMarker string         `xml:"NextUploadIdMarker,omitempty"`
+	Prefix             string         `xml:"Prefix"`
+	MaxUploads         int            `xml:"MaxUploads"`
+	IsTruncated        bool           `xml:"IsTruncated"`
+	Uploads            []ListedUpload `xml:"Upload"`
+}
+
+// ListedUpload is an upload in a ListMultipartUploadsResult.
+type ListedUpload struct {
+	Key       string     `xml:"Key"`
+	UploadID  string     `xml:"UploadId"`
+	Initiator UserObject `xml:"Initiator"`
+	Owner     UserObject `xml:"Owner"`
+	Initiated IsoTime    `xml:"Initiated"`
+}
+
+// CopyObjectResult answers CopyObject.
+type CopyObjectResult struct {
+	XMLName      xml.Name `xml:"CopyObjectResult"`
+	Xmlns        string   `xml:"xmlns,attr"`
+	ETag         string   `xml:"ETag"`
+	LastModified IsoTime  `xml:"LastModified"`
+}
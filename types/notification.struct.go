@@ -0,0 +1,119 @@
+package types
+
+import "encoding/xml"
+
+// NotificationConfiguration is the S3 event notification configuration of a
+// bucket. Queue, topic and function configurations are all delivered to the
+// webhook target whose ARN they name.
+type NotificationConfiguration struct {
+	XMLName                xml.Name             `xml:"NotificationConfiguration"`
+	Xmlns                  string               `xml:"xmlns,attr,omitempty"`
+	TopicConfigurations    []NotificationTarget `xml:"TopicConfiguration"`
+	QueueConfigurations    []NotificationTarget `xml:"QueueConfiguration"`
+	FunctionConfigurations []NotificationTarget `xml:"CloudFunctionConfiguration"`
+}
+
+// Targets returns the topic, queue and function configurations together.
+func (c *NotificationConfiguration) Targets() []NotificationTarget {
+	targets := append([]NotificationTarget{}, c.TopicConfigurations...)
+	targets = append(targets, c.QueueConfigurations...)
+	return append(targets, c.FunctionConfigurations...)
+}
+
+// NotificationTarget sends the Events matching Filter to the target named by
+// exactly one of Topic, Queue or CloudFunction.
+type NotificationTarget struct {
+	Id            string              `xml:"Id,omitempty"`
+	Topic         string              `xml:"Topic,omitempty"`
+	Queue         string              `xml:"Queue,omitempty"`
+	CloudFunction string              `xml:"CloudFunction,omitempty"`
+	Events        []string            `xml:"Event"`
+	Filter        *NotificationFilter `xml:"Filter,omitempty"`
+}
+
+// ARN returns the target the configuration delivers to.
+func (t NotificationTarget) ARN() string {
+	switch {
+	case t.Topic != "":
+		return t.Topic
+	case t.Queue != "":
+		return t.Queue
+	}
+	return t.CloudFunction
+}
+
+// NotificationFilter limits a configuration to keys with a prefix and/or suffix.
+type NotificationFilter struct {
+	Key struct {
+		Rules []FilterRule `xml:"FilterRule"`
+	} `xml:"S3Key"`
+}
+
+// FilterRule is a prefix or suffix rule of a NotificationFilter.
+type FilterRule struct {
+	Name  string `xml:"Name"`
+	Value string `xml:"Value"`
+}
+
+// NotificationTargets is the root of the notification targets file, which
+// lists the webhooks bucket configurations may deliver to.
+type NotificationTargets struct {
+	XMLName  xml.Name        `xml:"NotificationTargets"`
+	Webhooks []WebhookTarget `xml:"Webhook"`
+}
+
+// WebhookTarget receives event notifications as JSON POSTs to Endpoint. When
+// AuthToken is set it is sent as a bearer token.
+type WebhookTarget struct {
+	ARN       string `xml:"ARN"`
+	Endpoint  string `xml:"Endpoint"`
+	AuthToken string `xml:"AuthToken,omitempty"`
+}
+
+// EventMessage is the JSON body of an event notification.
+type EventMessage struct {
+	Records []EventRecord `json:"Records"`
+}
+
+// EventRecord describes one event in the S3 event message format.
+type EventRecord struct {
+	EventVersion      string            `json:"eventVersion"`
+	EventSource       string            `json:"eventSource"`
+	AwsRegion         string            `json:"awsRegion"`
+	EventTime         string            `json:"eventTime"`
+	EventName         string            `json:"eventName"`
+	UserIdentity      EventIdentity     `json:"userIdentity"`
+	RequestParameters map[string]string `json:"requestParameters"`
+	ResponseElements  map[string]string `json:"responseElements"`
+	S3                EventS3           `json:"s3"`
+}
+
+// EventIdentity names the caller or owner in an event record.
+type EventIdentity struct {
+	PrincipalID string `json:"principalId"`
+}
+
+// EventS3 holds the bucket and object an event record is about.
+type EventS3 struct {
+	SchemaVersion   string      `json:"s3SchemaVersion"`
+	ConfigurationID string      `json:"configurationId"`
+	Bucket          EventBucket `json:"bucket"`
+	Object          EventObject `json:"object"`
+}
+
+// EventBucket is the bucket of an event record.
+type EventBucket struct {
+	Name          string        `json:"name"`
+	OwnerIdentity EventIdentity `json:"ownerIdentity"`
+	ARN           string        `json:"arn"`
+}
+
+// EventObject is the object of an event record. Key is URL-encoded; Size and
+// ETag are left out for removals.
+type EventObject struct {
+	Key       string `json:"key"`
+	Size      int64  `json:"size,omitempty"`
+	ETag      string `json:"eTag,omitempty"`
+	VersionID string `json:"versionId,omitempty"`
+	Sequencer string `json:"sequencer"`
+}
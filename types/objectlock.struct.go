@@ -0,0 +1,51 @@
+package types
+
+import "encoding/xml"
+
+// Object Lock retention modes and legal hold statuses.
+const (
+	RetentionGovernance = "GOVERNANCE"
+	RetentionCompliance = "COMPLIANCE"
+	LegalHoldOn         = "ON"
+	LegalHoldOff        = "OFF"
+)
+
+// ObjectLockConfiguration is the Object Lock configuration of a bucket.
+// Object Lock can only be enabled when the bucket is created; Rule sets the
+// retention applied to new objects that do not ask for their own.
+type ObjectLockConfiguration struct {
+	XMLName           xml.Name        `xml:"ObjectLockConfiguration"`
+	Xmlns             string          `xml:"xmlns,attr,omitempty"`
+	ObjectLockEnabled string          `xml:"ObjectLockEnabled"`
+	Rule              *ObjectLockRule `xml:"Rule,omitempty"`
+}
+
+// ObjectLockRule holds the default retention of a bucket.
+type ObjectLockRule struct {
+	DefaultRetention DefaultRetention `xml:"DefaultRetention"`
+}
+
+// DefaultRetention locks new objects for Days or Years in the given Mode.
+type DefaultRetention struct {
+	Mode  string `xml:"Mode"`
+	Days  int    `xml:"Days,omitempty"`
+	Years int    `xml:"Years,omitempty"`
+}
+
+// ObjectRetention keeps an object from being deleted or overwritten until
+// RetainUntilDate. GOVERNANCE retention can be bypassed with the
+// s3:BypassGovernanceRetention permission, COMPLIANCE retention cannot.
+type ObjectRetention struct {
+	XMLName         xml.Name `xml:"Retention"`
+	Xmlns           string   `xml:"xmlns,attr,omitempty"`
+	Mode            string   `xml:"Mode,omitempty"`
+	RetainUntilDate IsoTime  `xml:"RetainUntilDate"`
+}
+
+// ObjectLegalHold keeps an object from being deleted or overwritten while
+// Status is ON, regardless of its retention.
+type ObjectLegalHold struct {
+	XMLName xml.Name `xml:"LegalHold"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	Status  string   `xml:"Status"`
+}
@@ -15,9 +15,29 @@ type ObjectMetadata struct {
 	PreviousVersionId string     `xml:"PreviousVersionId,omitempty" json:"previousVersionId,omitempty"`
 	Owner             UserObject `xml:"Owner" json:"owner"`
 	Public            bool       `xml:"Public" json:"public"`
+	Grants            []Grant    `xml:"Grants>Grant" json:"grants,omitempty"`
 	Size              int64      `xml:"Size" json:"size"`
+	StoredSize        int64      `xml:"StoredSize,omitempty" json:"storedSize,omitempty"`
 	LastModified      IsoTime    `xml:"LastModified" json:"lastModified"`
 	UploadedAt        IsoTime    `xml:"UploadedAt" json:"uploadedAt"`
+
+	Retention *ObjectRetention `xml:"Retention,omitempty" json:"retention,omitempty"`
+	LegalHold string           `xml:"LegalHold,omitempty" json:"legalHold,omitempty"`
+
+	Encryption  *ObjectEncryption  `xml:"Encryption,omitempty" json:"-"`
+	Compression *ObjectCompression `xml:"Compression,omitempty" json:"-"`
+	// ContentHash points at the object's content in the chunk store when it
+	// was written in dedup storage mode; the file under the bucket is empty.
+	ContentHash string `xml:"ContentHash,omitempty" json:"-"`
+	// ReplicationStatus is set on objects covered by a replication rule, and
+	// to REPLICA on the copies replication writes.
+	ReplicationStatus string `xml:"ReplicationStatus,omitempty" json:"replicationStatus,omitempty"`
+	// WebsiteRedirectLocation redirects website requests for the object to
+	// another key of the bucket or to a URL.
+	WebsiteRedirectLocation string `xml:"WebsiteRedirectLocation,omitempty" json:"websiteRedirectLocation,omitempty"`
+	// KeyBase64 holds the key, base64-encoded, when XML cannot represent it,
+	// such as keys with control characters. It is set by metadata.Save.
+	KeyBase64 string `xml:"KeyBase64,omitempty" json:"-"`
 }
 
 type Tag struct {
@@ -55,3 +75,20 @@ func (t IsoTime) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	v := time.Time(t).UTC().Format("2006-01-02T15:04:05.000Z")
 	return e.EncodeElement(v, start)
 }
+
+func (t *IsoTime) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var v string
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	if v == "" {
+		*t = IsoTime{}
+		return nil
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return err
+	}
+	*t = IsoTime(parsed)
+	return nil
+}
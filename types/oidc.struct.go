@@ -0,0 +1,29 @@
+package types
+
+import "encoding/xml"
+
+// OIDCProviders is the root of the OIDC configuration file, which lists the
+// identity providers whose tokens can be exchanged through
+// AssumeRoleWithWebIdentity.
+type OIDCProviders struct {
+	XMLName   xml.Name       `xml:"OIDCProviders"`
+	Providers []OIDCProvider `xml:"Provider"`
+}
+
+// OIDCProvider is a trusted token issuer. Signing keys come from JWKSURL or,
+// for tests and offline setups, from a local JWKSFile.
+type OIDCProvider struct {
+	Issuer       string        `xml:"Issuer"`
+	JWKSURL      string        `xml:"JWKSURL,omitempty"`
+	JWKSFile     string        `xml:"JWKSFile,omitempty"`
+	Audiences    []string      `xml:"Audience"`
+	RoleMappings []RoleMapping `xml:"RoleMapping"`
+}
+
+// RoleMapping lets tokens whose Claim matches Value assume Role. Value may
+// contain * and ? wildcards; array claims match if any element matches.
+type RoleMapping struct {
+	Claim string `xml:"Claim"`
+	Value string `xml:"Value"`
+	Role  string `xml:"Role"`
+}
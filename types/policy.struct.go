@@ -0,0 +1,138 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Policy effects.
+const (
+	EffectAllow = "Allow"
+	EffectDeny  = "Deny"
+)
+
+// Supported policy language versions.
+const (
+	PolicyVersion2012 = "2012-10-17"
+	PolicyVersion2008 = "2008-10-17"
+)
+
+// BucketPolicy represents an IAM-style JSON bucket policy document.
+type BucketPolicy struct {
+	Version   string            `json:"Version,omitempty"`
+	ID        string            `json:"Id,omitempty"`
+	Statement []PolicyStatement `json:"Statement"`
+}
+
+// PolicyStatement is a single statement of a policy document.
+type PolicyStatement struct {
+	Sid         string           `json:"Sid,omitempty"`
+	Effect      string           `json:"Effect"`
+	Principal   *PolicyPrincipal `json:"Principal,omitempty"`
+	Action      StringOrSlice    `json:"Action,omitempty"`
+	NotAction   StringOrSlice    `json:"NotAction,omitempty"`
+	Resource    StringOrSlice    `json:"Resource,omitempty"`
+	NotResource StringOrSlice    `json:"NotResource,omitempty"`
+	Condition   PolicyCondition  `json:"Condition,omitempty"`
+}
+
+// PolicyCondition maps a condition operator to its key/value pairs,
+// e.g. {"IpAddress": {"aws:SourceIp": ["10.0.0.0/8"]}}.
+type PolicyCondition map[string]map[string]StringOrSlice
+
+// PolicyPrincipal is either the wildcard "*" or a set of AWS principals.
+type PolicyPrincipal struct {
+	Wildcard bool
+	AWS      StringOrSlice
+}
+
+// UnmarshalJSON accepts "*", {"AWS": "id"} and {"AWS": ["id", ...]}.
+func (p *PolicyPrincipal) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		if s != "*" {
+			return fmt.Errorf("invalid principal %q", s)
+		}
+		p.Wildcard = true
+		return nil
+	}
+
+	var m map[string]StringOrSlice
+	if err := json.Unmarshal(data, &m); err != nil {
+		return fmt.Errorf("invalid principal: %v", err)
+	}
+	for k, v := range m {
+		if k != "AWS" {
+			return fmt.Errorf("unsupported principal type %q", k)
+		}
+		p.AWS = v
+	}
+	for _, v := range p.AWS {
+		if v == "*" {
+			p.Wildcard = true
+		}
+	}
+	return nil
+}
+
+// MarshalJSON writes the principal back in its canonical form.
+func (p PolicyPrincipal) MarshalJSON() ([]byte, error) {
+	if p.Wildcard && len(p.AWS) == 0 {
+		return json.Marshal("*")
+	}
+	return json.Marshal(map[string]StringOrSlice{"AWS": p.AWS})
+}
+
+// StringOrSlice decodes a JSON string, bool, number or array of those
+// into a list of strings.
+type StringOrSlice []string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (s *StringOrSlice) UnmarshalJSON(data []byte) error {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	toString := func(v any) (string, error) {
+		switch t := v.(type) {
+		case string:
+			return t, nil
+		case bool:
+			return strconv.FormatBool(t), nil
+		case float64:
+			return strconv.FormatFloat(t, 'f', -1, 64), nil
+		default:
+			return "", fmt.Errorf("unsupported value %v", v)
+		}
+	}
+
+	switch t := raw.(type) {
+	case []any:
+		out := make([]string, 0, len(t))
+		for _, v := range t {
+			str, err := toString(v)
+			if err != nil {
+				return err
+			}
+			out = append(out, str)
+		}
+		*s = out
+	default:
+		str, err := toString(t)
+		if err != nil {
+			return err
+		}
+		*s = StringOrSlice{str}
+	}
+	return nil
+}
+
+// MarshalJSON writes single values as a plain string.
+func (s StringOrSlice) MarshalJSON() ([]byte, error) {
+	if len(s) == 1 {
+		return json.Marshal(s[0])
+	}
+	return json.Marshal([]string(s))
+}
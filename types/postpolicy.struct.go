@@ -0,0 +1,32 @@
+package types
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// PostPolicy is the decoded policy document of a browser POST upload.
+type PostPolicy struct {
+	Expiration time.Time
+	Conditions []PostPolicyCondition
+}
+
+// PostPolicyCondition is a single condition of a POST policy. Field is the
+// lowercased form field name without its leading "$". Min and Max are only
+// set for content-length-range.
+type PostPolicyCondition struct {
+	Operator string // "eq", "starts-with" or "content-length-range"
+	Field    string
+	Value    string
+	Min      int64
+	Max      int64
+}
+
+// PostResponse is returned by a browser POST upload when success_action_status is 201.
+type PostResponse struct {
+	XMLName  xml.Name `xml:"PostResponse"`
+	Location string   `xml:"Location"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	ETag     string   `xml:"ETag"`
+}
@@ -0,0 +1,63 @@
+package types
+
+import "encoding/xml"
+
+// Replication statuses of an object, as sent in x-amz-replication-status.
+const (
+	ReplicationPending   = "PENDING"
+	ReplicationCompleted = "COMPLETED"
+	ReplicationFailed    = "FAILED"
+	ReplicationReplica   = "REPLICA"
+)
+
+// ReplicationConfiguration is the S3 replication configuration of a bucket.
+// Role is accepted for compatibility; destinations carry their own endpoint
+// and credentials instead.
+type ReplicationConfiguration struct {
+	XMLName xml.Name          `xml:"ReplicationConfiguration"`
+	Xmlns   string            `xml:"xmlns,attr,omitempty"`
+	Role    string            `xml:"Role,omitempty"`
+	Rules   []ReplicationRule `xml:"Rule"`
+}
+
+// ReplicationRule copies the objects selected by Filter, or the legacy
+// top-level Prefix, to Destination while Status is Enabled. When several
+// rules match an object, the one with the highest Priority is used.
+type ReplicationRule struct {
+	ID                      string                   `xml:"ID,omitempty"`
+	Priority                int                      `xml:"Priority,omitempty"`
+	Status                  string                   `xml:"Status"`
+	Prefix                  *string                  `xml:"Prefix"`
+	Filter                  *ReplicationFilter       `xml:"Filter"`
+	Destination             ReplicationDestination   `xml:"Destination"`
+	DeleteMarkerReplication *DeleteMarkerReplication `xml:"DeleteMarkerReplication"`
+}
+
+// ReplicationFilter holds exactly one of its conditions; And combines several.
+type ReplicationFilter struct {
+	Prefix *string         `xml:"Prefix"`
+	Tag    *Tag            `xml:"Tag"`
+	And    *ReplicationAnd `xml:"And"`
+}
+
+// ReplicationAnd matches objects that satisfy all of its conditions.
+type ReplicationAnd struct {
+	Prefix string `xml:"Prefix,omitempty"`
+	Tags   []Tag  `xml:"Tag"`
+}
+
+// ReplicationDestination is the bucket objects are copied to, on the S3
+// endpoint at Endpoint. Requests are signed with the access key, for Region
+// when it is set. The secret key is never returned by GetBucketReplication.
+type ReplicationDestination struct {
+	Bucket          string `xml:"Bucket"`
+	Endpoint        string `xml:"Endpoint"`
+	Region          string `xml:"Region,omitempty"`
+	AccessKeyID     string `xml:"AccessKeyId"`
+	SecretAccessKey string `xml:"SecretAccessKey,omitempty"`
+}
+
+// DeleteMarkerReplication turns the replication of deletes on or off.
+type DeleteMarkerReplication struct {
+	Status string `xml:"Status"`
+}
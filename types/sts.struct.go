@@ -0,0 +1,67 @@
+package types
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// STSNamespace is the XML namespace of STS responses.
+const STSNamespace = "https://sts.amazonaws.com/doc/2011-06-15/"
+
+// Credentials is a set of temporary security credentials issued by STS.
+type Credentials struct {
+	AccessKeyID     string    `xml:"AccessKeyId"`
+	SecretAccessKey string    `xml:"SecretAccessKey"`
+	SessionToken    string    `xml:"SessionToken"`
+	Expiration      time.Time `xml:"Expiration"`
+}
+
+// AssumedRoleUser identifies the session created by AssumeRole.
+type AssumedRoleUser struct {
+	AssumedRoleID string `xml:"AssumedRoleId"`
+	Arn           string `xml:"Arn"`
+}
+
+// ResponseMetadata carries the request ID of an STS response.
+type ResponseMetadata struct {
+	RequestID string `xml:"RequestId"`
+}
+
+// GetSessionTokenResponse is returned by STS GetSessionToken.
+type GetSessionTokenResponse struct {
+	XMLName          xml.Name         `xml:"GetSessionTokenResponse"`
+	Xmlns            string           `xml:"xmlns,attr"`
+	Credentials      Credentials      `xml:"GetSessionTokenResult>Credentials"`
+	ResponseMetadata ResponseMetadata `xml:"ResponseMetadata"`
+}
+
+// AssumeRoleResponse is returned by STS AssumeRole.
+type AssumeRoleResponse struct {
+	XMLName          xml.Name         `xml:"AssumeRoleResponse"`
+	Xmlns            string           `xml:"xmlns,attr"`
+	Credentials      Credentials      `xml:"AssumeRoleResult>Credentials"`
+	AssumedRoleUser  AssumedRoleUser  `xml:"AssumeRoleResult>AssumedRoleUser"`
+	ResponseMetadata ResponseMetadata `xml:"ResponseMetadata"`
+}
+
+// STSErrorResponse is the error format of the STS query API.
+type STSErrorResponse struct {
+	XMLName   xml.Name `xml:"ErrorResponse"`
+	Xmlns     string   `xml:"xmlns,attr"`
+	Type      string   `xml:"Error>Type"`
+	Code      string   `xml:"Error>Code"`
+	Message   string   `xml:"Error>Message"`
+	RequestID string   `xml:"RequestId"`
+}
+
+// AssumeRoleWithWebIdentityResponse is returned by STS AssumeRoleWithWebIdentity.
+type AssumeRoleWithWebIdentityResponse struct {
+	XMLName                     xml.Name         `xml:"AssumeRoleWithWebIdentityResponse"`
+	Xmlns                       string           `xml:"xmlns,attr"`
+	Credentials                 Credentials      `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	AssumedRoleUser             AssumedRoleUser  `xml:"AssumeRoleWithWebIdentityResult>AssumedRoleUser"`
+	SubjectFromWebIdentityToken string           `xml:"AssumeRoleWithWebIdentityResult>SubjectFromWebIdentityToken"`
+	Provider                    string           `xml:"AssumeRoleWithWebIdentityResult>Provider"`
+	Audience                    string           `xml:"AssumeRoleWithWebIdentityResult>Audience"`
+	ResponseMetadata            ResponseMetadata `xml:"ResponseMetadata"`
+}
@@ -0,0 +1,10 @@
+package types
+
+import "encoding/xml"
+
+// Tagging is the tag set of an object, as sent to and returned by ?tagging.
+type Tagging struct {
+	XMLName xml.Name `xml:"Tagging"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	TagSet  []Tag    `xml:"TagSet>Tag"`
+}
@@ -0,0 +1,58 @@
+package types
+
+import "encoding/xml"
+
+// WebsiteConfiguration is the static website configuration of a bucket. A
+// bucket either redirects every request elsewhere with RedirectAllRequestsTo
+// or serves its objects with IndexDocument, ErrorDocument and RoutingRules.
+type WebsiteConfiguration struct {
+	XMLName               xml.Name               `xml:"WebsiteConfiguration"`
+	Xmlns                 string                 `xml:"xmlns,attr,omitempty"`
+	RedirectAllRequestsTo *RedirectAllRequestsTo `xml:"RedirectAllRequestsTo,omitempty"`
+	IndexDocument         *IndexDocument         `xml:"IndexDocument,omitempty"`
+	ErrorDocument         *ErrorDocument         `xml:"ErrorDocument,omitempty"`
+	RoutingRules          []RoutingRule          `xml:"RoutingRules>RoutingRule,omitempty"`
+}
+
+// RedirectAllRequestsTo sends every request to the same path on HostName.
+type RedirectAllRequestsTo struct {
+	HostName string `xml:"HostName"`
+	Protocol string `xml:"Protocol,omitempty"`
+}
+
+// IndexDocument is appended to requests for the root or a "directory", so
+// "docs/" is served from "docs/index.html" with a Suffix of "index.html".
+type IndexDocument struct {
+	Suffix string `xml:"Suffix"`
+}
+
+// ErrorDocument is the object served with 4xx errors.
+type ErrorDocument struct {
+	Key string `xml:"Key"`
+}
+
+// RoutingRule redirects requests that meet its Condition, or every request
+// when it has none.
+type RoutingRule struct {
+	Condition *RoutingCondition `xml:"Condition,omitempty"`
+	Redirect  RoutingRedirect   `xml:"Redirect"`
+}
+
+// RoutingCondition matches keys with a prefix and/or requests that would
+// fail with an HTTP error code.
+type RoutingCondition struct {
+	KeyPrefixEquals             string `xml:"KeyPrefixEquals,omitempty"`
+	HttpErrorCodeReturnedEquals string `xml:"HttpErrorCodeReturnedEquals,omitempty"`
+}
+
+// RoutingRedirect describes where a routing rule redirects to. Fields left
+// empty keep the host, protocol and key of the request; the key is replaced
+// as a whole with ReplaceKeyWith or only in its matched prefix with
+// ReplaceKeyPrefixWith.
+type RoutingRedirect struct {
+	HostName             string `xml:"HostName,omitempty"`
+	HttpRedirectCode     string `xml:"HttpRedirectCode,omitempty"`
+	Protocol             string `xml:"Protocol,omitempty"`
+	ReplaceKeyPrefixWith string `xml:"ReplaceKeyPrefixWith,omitempty"`
+	ReplaceKeyWith       string `xml:"ReplaceKeyWith,omitempty"`
+}
@@ -2,9 +2,21 @@ package types
 
 import "encoding/xml"
 
+// BucketList is the response of ListBuckets. ContinuationToken is set when
+// more buckets follow.
 type BucketList struct {
-	XMLName xml.Name `xml:"ListAllMyBucketsResult"`
+	XMLName xml.Name    `xml:"ListAllMyBucketsResult"`
+	Xmlns   string      `xml:"xmlns,attr,omitempty"`
+	Owner   *UserObject `xml:"Owner,omitempty"`
 	Buckets struct {
-		Bucket []Bucket `xml:"Bucket"`
+		Bucket []BucketListEntry `xml:"Bucket"`
 	} `xml:"Buckets"`
+	ContinuationToken string `xml:"ContinuationToken,omitempty"`
+	Prefix            string `xml:"Prefix,omitempty"`
+}
+
+// BucketListEntry is a bucket in a ListBuckets response.
+type BucketListEntry struct {
+	Name         string  `xml:"Name"`
+	CreationDate IsoTime `xml:"CreationDate"`
 }